@@ -0,0 +1,432 @@
+// Code generated by go run ./cmd/openapi -ts. DO NOT EDIT.
+
+export interface Availability {
+    busy_until?: string | null;
+    free: boolean;
+    free_until?: string | null;
+}
+
+export interface AvailabilityResponse {
+    at: string;
+    busy_until: string | null;
+    free: boolean;
+    free_until: string | null;
+}
+
+export interface BuildingInfo {
+    lat: number;
+    lng: number;
+    name: string;
+}
+
+export interface BuildingResponse {
+    code: string;
+    lat: number;
+    lng: number;
+    name: string;
+}
+
+export interface ClassResponse {
+    course_id: string;
+    crn: string;
+    instructors: string[];
+    section: string;
+}
+
+export interface Course {
+    course_id: string;
+    course_number: string;
+    sections: Section[];
+    subject: string;
+    title: string;
+}
+
+export interface CursorPageResponseRoomResponse {
+    data: RoomResponse[];
+    next_cursor: string | null;
+}
+
+export interface DeviceResponse {
+    device_id: string;
+    expires_at: string;
+    token: string;
+}
+
+export interface Favorite {
+    created_at: string;
+    room_id: string;
+}
+
+export interface Hold {
+    end: string;
+    group: string;
+    id: string;
+    room_id: string;
+    start: string;
+}
+
+export interface HoldResponse {
+    end: string;
+    group: string;
+    start: string;
+}
+
+export interface Instructor {
+    email?: string;
+    id: string;
+    name: string;
+}
+
+export interface InstructorScheduleResponse {
+    instructor: Instructor;
+    schedule: TeachingSlot[];
+}
+
+export interface Location {
+    column: number;
+    line: number;
+}
+
+export interface Meeting {
+    day: number;
+    end_time: number;
+    label?: MeetingInfo[];
+    location: string;
+    start_time: number;
+}
+
+export interface MeetingInfo {
+    course_id: string;
+    id: string;
+    instructors?: Instructor[];
+    professor: string;
+    section: string;
+}
+
+export interface MeetingResponse {
+    classes: ClassResponse[];
+    day: number;
+    end_time: number;
+    start_time: number;
+}
+
+export interface Override {
+    building: string;
+    created_at: string;
+    created_by: string;
+    end?: string | null;
+    id: string;
+    kind: string;
+    room_id?: string;
+    start: string;
+    title?: string;
+}
+
+export interface OverrideResponse {
+    end: string | null;
+    kind: string;
+    start: string;
+    title: string;
+}
+
+export interface PageResponseBuildingResponse {
+    data: BuildingResponse[];
+    pagination: Pagination;
+}
+
+export interface Pagination {
+    limit: number;
+    next_offset: number | null;
+    offset: number;
+    total: number;
+}
+
+export interface QueryError {
+    extensions?: Record<string, unknown>;
+    locations?: Location[];
+    message: string;
+    path?: unknown[];
+}
+
+export interface Report {
+    expires_at: string;
+    id: string;
+    reported_at: string;
+    room_id: string;
+    status: string;
+}
+
+export interface ReportSummary {
+    confidence: number;
+    count: number;
+    last_reported_at: string;
+    status: string;
+}
+
+export interface Response {
+    data?: unknown;
+    errors?: QueryError[];
+}
+
+export interface Result {
+    id: string;
+    score: number;
+    subtitle?: string;
+    title: string;
+    type: string;
+}
+
+export interface Room {
+    Availability?: Availability;
+    Building: string;
+    Capacity: number;
+    Holds?: Hold[];
+    ID: string;
+    Number: string;
+    Overrides?: Override[];
+    Reports?: ReportSummary;
+    Schedule: Meeting[];
+}
+
+export interface RoomResponse {
+    availability: AvailabilityResponse;
+    building: string;
+    building_name: string;
+    holds: HoldResponse[];
+    id: string;
+    number: string;
+    overrides: OverrideResponse[];
+    reports: ReportSummary;
+    schedule: MeetingResponse[];
+}
+
+export interface RoomStatus {
+    building: string;
+    busy_until?: string | null;
+    free: boolean;
+    free_until?: string | null;
+    number: string;
+    reports?: ReportSummary;
+    room_id: string;
+}
+
+export interface SavedSearch {
+    building: string;
+    created_at: string;
+    id: string;
+    min_duration: number;
+    name: string;
+}
+
+export interface SearchResponse {
+    query: string;
+    results: Result[];
+}
+
+export interface Section {
+    course_id: string;
+    course_number: string;
+    crn: string;
+    instructors: Instructor[];
+    meetings: SectionMeeting[];
+    section: string;
+    subject: string;
+    term: string;
+    title: string;
+}
+
+export interface SectionMeeting {
+    days: number[];
+    end_time: number;
+    instructors: Instructor[];
+    location: string;
+    room_id?: string;
+    start_time: number;
+}
+
+export interface TeachingSlot {
+    course_id: string;
+    crn: string;
+    days: number[];
+    end_time: number;
+    location: string;
+    room_id?: string;
+    section: string;
+    start_time: number;
+    title: string;
+}
+
+export interface User {
+    avatar_url: string;
+    created_at: string;
+    email: string;
+    id: string;
+    last_login_at: string;
+    name: string;
+}
+
+export interface Watch {
+    channel: string;
+    created_at: string;
+    id: string;
+    lead_minutes: number;
+    notified_for?: string;
+    room_id: string;
+}
+
+export class ApiError extends Error {
+    constructor(public status: number, public body: unknown) {
+        super(`request failed with ${status}`);
+    }
+}
+
+async function request<T>(baseUrl: string, method: string, path: string, query?: Record<string, unknown>, body?: unknown, init?: RequestInit): Promise<T> {
+    const url = new URL(path, baseUrl);
+    for (const [k, v] of Object.entries(query ?? {})) {
+        if (v !== undefined && v !== null) url.searchParams.set(k, String(v));
+    }
+    const res = await fetch(url, {
+        ...init,
+        method,
+        credentials: "include",
+        headers: { ...(body !== undefined ? { "Content-Type": "application/json" } : {}), ...init?.headers },
+        body: body !== undefined ? JSON.stringify(body) : undefined,
+    });
+    if (!res.ok) throw new ApiError(res.status, await res.json().catch(() => null));
+    return (res.status === 204 ? undefined : await res.json()) as T;
+}
+
+/** Building coordinates keyed by code */
+export const getApiBuildings = (baseUrl: string, init?: RequestInit) =>
+    request<Record<string, BuildingInfo>>(baseUrl, "GET", `/api/buildings`, undefined, undefined, init);
+
+/** A room and its schedule */
+export const getApiRoom = (baseUrl: string, query: { room: string }, init?: RequestInit) =>
+    request<Room>(baseUrl, "GET", `/api/room`, query, undefined, init);
+
+/** Rooms and their schedules */
+export const getApiRooms = (baseUrl: string, query: { building?: string; sort?: string; fields?: string; limit?: number; cursor?: string; day?: number; time?: number } = {}, init?: RequestInit) =>
+    request<Room[]>(baseUrl, "GET", `/api/rooms`, query, undefined, init);
+
+/** Report a room's status */
+export const postApiRoomsByIdReports = (baseUrl: string, id: string, body: {
+    status: string;
+    timestamp?: string | null;
+}, init?: RequestInit) =>
+    request<Report>(baseUrl, "POST", `/api/rooms/${encodeURIComponent(id)}/reports`, undefined, body, init);
+
+/** Place a study group hold on a room */
+export const postApiRoomsByIdHolds = (baseUrl: string, id: string, body: {
+    end: string;
+    group?: string;
+    start?: string | null;
+}, init?: RequestInit) =>
+    request<Hold>(baseUrl, "POST", `/api/rooms/${encodeURIComponent(id)}/holds`, undefined, body, init);
+
+/** Issue an anonymous device token */
+export const postApiDevice = (baseUrl: string, init?: RequestInit) =>
+    request<DeviceResponse>(baseUrl, "POST", `/api/device`, undefined, undefined, init);
+
+/** Search courses by code, subject or title */
+export const getApiCourses = (baseUrl: string, query: { q: string }, init?: RequestInit) =>
+    request<Course[]>(baseUrl, "GET", `/api/courses`, query, undefined, init);
+
+/** A section with instructors and meetings */
+export const getApiSectionsByCrn = (baseUrl: string, crn: string, init?: RequestInit) =>
+    request<Section>(baseUrl, "GET", `/api/sections/${encodeURIComponent(crn)}`, undefined, undefined, init);
+
+/** Where and when an instructor teaches */
+export const getApiInstructorsByIdSchedule = (baseUrl: string, id: string, init?: RequestInit) =>
+    request<InstructorScheduleResponse>(baseUrl, "GET", `/api/instructors/${encodeURIComponent(id)}/schedule`, undefined, undefined, init);
+
+/** Search rooms, buildings, courses and instructors */
+export const getApiSearch = (baseUrl: string, query: { q: string; type?: string; limit?: number }, init?: RequestInit) =>
+    request<SearchResponse>(baseUrl, "GET", `/api/search`, query, undefined, init);
+
+/** GraphQL query, schema at internal/graph/schema.graphql */
+export const postApiGraphql = (baseUrl: string, body: {
+    operationName?: string;
+    query: string;
+    variables?: Record<string, unknown>;
+}, init?: RequestInit) =>
+    request<Response>(baseUrl, "POST", `/api/graphql`, undefined, body, init);
+
+/** The signed in user */
+export const getApiMe = (baseUrl: string, init?: RequestInit) =>
+    request<User>(baseUrl, "GET", `/api/me`, undefined, undefined, init);
+
+/** Favorite rooms */
+export const getApiMeFavorites = (baseUrl: string, init?: RequestInit) =>
+    request<Favorite[]>(baseUrl, "GET", `/api/me/favorites`, undefined, undefined, init);
+
+/** Add a favorite room */
+export const putApiMeFavoritesByRoom = (baseUrl: string, room: string, init?: RequestInit) =>
+    request<Favorite>(baseUrl, "PUT", `/api/me/favorites/${encodeURIComponent(room)}`, undefined, undefined, init);
+
+/** Remove a favorite room */
+export const deleteApiMeFavoritesByRoom = (baseUrl: string, room: string, init?: RequestInit) =>
+    request<void>(baseUrl, "DELETE", `/api/me/favorites/${encodeURIComponent(room)}`, undefined, undefined, init);
+
+/** Free/busy state of each favorite room */
+export const getApiMeFavoritesStatus = (baseUrl: string, init?: RequestInit) =>
+    request<RoomStatus[]>(baseUrl, "GET", `/api/me/favorites/status`, undefined, undefined, init);
+
+/** Saved searches */
+export const getApiMeSearches = (baseUrl: string, init?: RequestInit) =>
+    request<SavedSearch[]>(baseUrl, "GET", `/api/me/searches`, undefined, undefined, init);
+
+/** Save a search */
+export const postApiMeSearches = (baseUrl: string, body: {
+    building?: string;
+    created_at?: string;
+    id?: string;
+    min_duration?: number;
+    name: string;
+}, init?: RequestInit) =>
+    request<SavedSearch>(baseUrl, "POST", `/api/me/searches`, undefined, body, init);
+
+/** Delete a saved search */
+export const deleteApiMeSearchesById = (baseUrl: string, id: string, init?: RequestInit) =>
+    request<void>(baseUrl, "DELETE", `/api/me/searches/${encodeURIComponent(id)}`, undefined, undefined, init);
+
+/** Room watches */
+export const getApiMeWatches = (baseUrl: string, init?: RequestInit) =>
+    request<Watch[]>(baseUrl, "GET", `/api/me/watches`, undefined, undefined, init);
+
+/** Get notified before a room frees up */
+export const postApiMeWatches = (baseUrl: string, body: {
+    channel: string;
+    lead_minutes: number;
+    room_id: string;
+    target?: string;
+}, init?: RequestInit) =>
+    request<Watch>(baseUrl, "POST", `/api/me/watches`, undefined, body, init);
+
+/** Delete a watch */
+export const deleteApiMeWatchesById = (baseUrl: string, id: string, init?: RequestInit) =>
+    request<void>(baseUrl, "DELETE", `/api/me/watches/${encodeURIComponent(id)}`, undefined, undefined, init);
+
+/** Your active holds */
+export const getApiMeHolds = (baseUrl: string, init?: RequestInit) =>
+    request<Hold[]>(baseUrl, "GET", `/api/me/holds`, undefined, undefined, init);
+
+/** Release a hold */
+export const deleteApiMeHoldsById = (baseUrl: string, id: string, init?: RequestInit) =>
+    request<void>(baseUrl, "DELETE", `/api/me/holds/${encodeURIComponent(id)}`, undefined, undefined, init);
+
+/** Buildings */
+export const getApiV2Buildings = (baseUrl: string, query: { limit?: number; offset?: number } = {}, init?: RequestInit) =>
+    request<PageResponseBuildingResponse>(baseUrl, "GET", `/api/v2/buildings`, query, undefined, init);
+
+/** A building */
+export const getApiV2BuildingsByCode = (baseUrl: string, code: string, init?: RequestInit) =>
+    request<BuildingResponse>(baseUrl, "GET", `/api/v2/buildings/${encodeURIComponent(code)}`, undefined, undefined, init);
+
+/** Rooms with availability */
+export const getApiV2Rooms = (baseUrl: string, query: { building?: string; at?: string; limit?: number; cursor?: string; day?: number; time?: number } = {}, init?: RequestInit) =>
+    request<CursorPageResponseRoomResponse>(baseUrl, "GET", `/api/v2/rooms`, query, undefined, init);
+
+/** A room with availability */
+export const getApiV2RoomsById = (baseUrl: string, id: string, query: { at?: string } = {}, init?: RequestInit) =>
+    request<RoomResponse>(baseUrl, "GET", `/api/v2/rooms/${encodeURIComponent(id)}`, query, undefined, init);
+
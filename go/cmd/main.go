@@ -1,50 +1,140 @@
 package main
 
 import (
-	"log"
+	"cmp"
+	"context"
+	"log/slog"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 
 	"github.com/google-dev-groups-gmu/ghost/go/internal/api"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/auth"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/cron"
 	"github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/graph"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/logging"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/middleware"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/notify"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/openapi"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/scraper"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/search"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/stream"
 )
 
 func main() {
 	// load env var
 	err := godotenv.Load()
+
+	// structured logs with request IDs, LOG_LEVEL / LOG_FORMAT pick the output
+	logging.Init()
 	if err != nil {
-		log.Println("no .env file found, relying on system env vars")
+		slog.Info("no .env file found, relying on system env vars")
 	}
 
 	// init firestore client
 	if err := firestore.Init(); err != nil {
-		log.Fatalf("failed to initialize Firestore: %v", err)
+		fatal("failed to initialize Firestore", err)
 	}
 	defer firestore.Close()
 
+	// signing secret for anonymous device tokens
+	if err := auth.Init(); err != nil {
+		fatal("failed to initialize auth", err)
+	}
+
+	// notifications for watched rooms, checked once a minute in the background
+	// NOTE: only run this on one instance or people get pinged twice
+	notify.Init()
+	if os.Getenv("NOTIFICATIONS") != "false" {
+		ctx, stop := context.WithCancel(context.Background())
+		defer stop()
+		go notify.Run(ctx)
+	}
+
+	// search index, rebuilt in the background whenever the scraper publishes new data
+	searchCtx, stopSearch := context.WithCancel(context.Background())
+	defer stopSearch()
+	go search.Run(searchCtx, time.Minute)
+
+	// nightly (or whatever SCRAPE_SCHEDULE says) scrape in the background, off when it's not set
+	// the scrape lock keeps instances, `scraper daemon` and hand runs from overlapping
+	if expr := os.Getenv("SCRAPE_SCHEDULE"); expr != "" {
+		sched, err := cron.Parse(expr)
+		if err != nil {
+			fatal("invalid SCRAPE_SCHEDULE", err)
+		}
+		opts := scraper.DefaultOptions()
+		opts.Term = cmp.Or(os.Getenv("SCRAPE_TERM"), opts.Term)
+		opts.Trigger = "schedule"
+		ctx, stop := context.WithCancel(context.Background())
+		defer stop()
+		go scraper.Schedule(ctx, sched, opts, nil)
+	}
+
+	// live room state for /api/stream
+	streamCtx, stopStream := context.WithCancel(context.Background())
+	defer stopStream()
+	go stream.Run(streamCtx)
+
 	// initialize Gin router
 	if os.Getenv("DEV") == "false" {
 		gin.SetMode(gin.ReleaseMode)
 	}
-	r := gin.Default()
-
-	// CORS configuration
-	config := cors.DefaultConfig()
 
 	FRONTEND_URL := os.Getenv("FRONTEND_URL")
 	if FRONTEND_URL == "" {
-		log.Printf("frontend url not set in env")
+		slog.Warn("frontend url not set in env")
 		FRONTEND_URL = "http://localhost:3000"
 	}
-	config.AllowOrigins = []string{FRONTEND_URL}
+	// google sign-in, needs FRONTEND_URL to send people back after the callback
+	if err := auth.InitOAuth(FRONTEND_URL); err != nil {
+		fatal("failed to initialize sign-in", err)
+	}
+
+	r := router(FRONTEND_URL)
+
+	// only trust X-Forwarded-For from our own proxies, otherwise anyone can pick their rate limit IP
+	// ex) TRUSTED_PROXIES=10.0.0.0/8,127.0.0.1
+	if proxies := os.Getenv("TRUSTED_PROXIES"); proxies != "" {
+		if err := r.SetTrustedProxies(strings.Split(proxies, ",")); err != nil {
+			fatal("invalid TRUSTED_PROXIES", err)
+		}
+	}
+
+	// start server
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "5000"
+	}
+	r.Run(":" + port)
+}
+
+// every route of the API, the OpenAPI test (main_test.go) drives this same router
+func router(frontendURL string) *gin.Engine {
+	r := gin.New()
+	// lets handlers pass the gin context to slog and keep the request ID
+	r.ContextWithFallback = true
+	r.Use(middleware.RequestID(), middleware.AccessLog(), gin.Recovery(), metrics.Middleware())
+
+	// CORS configuration
+	config := cors.DefaultConfig()
+	config.AllowOrigins = []string{frontendURL}
 	config.AllowCredentials = true
 	config.AddAllowMethods("GET", "POST", "PUT", "DELETE")
+	config.AddAllowHeaders(auth.DeviceHeader, middleware.RequestIDHeader)
+	config.AddExposeHeaders("Retry-After", "X-Next-Cursor", middleware.RequestIDHeader)
 	r.Use(cors.New(config))
 
+	// writes that carry the session cookie have to come from the frontend, see auth.RequireSameOrigin
+	r.Use(auth.RequireSameOrigin())
+
 	// health check route
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "health check ok"})
@@ -55,8 +145,22 @@ func main() {
 		c.JSON(http.StatusOK, gin.H{"message": "GDG ghost map API", "version": "v1.0"})
 	})
 
+	// prometheus metrics, protected by METRICS_TOKEN when set
+	r.GET("/metrics", metrics.Handler())
+
+	// sign-in routes, these redirect the browser so they live outside /api
+	au := r.Group("/auth")
+	{
+		au.GET("/google", api.BeginLogin)
+		au.GET("/google/callback", api.LoginCallback)
+		au.POST("/logout", api.Logout)
+	}
+
+	// rate limit buckets, in memory since we run a single instance
+	limits := middleware.NewMemoryStore(10 * time.Minute)
+
 	// API routes
-	a := r.Group("/api")
+	a := r.Group("/api", middleware.RateLimit(limits, "api", middleware.PerMinute(120, 60), middleware.JSONError, middleware.ByIP))
 	{
 		// schedule for a specific room
 		a.GET("/room", api.GetSpecificRoom)
@@ -64,14 +168,126 @@ func main() {
 		// list of rooms and schedules for a specific building
 		a.GET("/rooms", api.GetRooms)
 
+		// anonymous device token for write endpoints
+		a.POST("/device", middleware.RateLimit(limits, "device", middleware.PerMinute(1, 5), middleware.JSONError, middleware.ByIP), api.PostDevice)
+
 		// static building lat/long data
 		a.GET("/buildings", api.GetBuildings)
+
+		// course and section lookup from the scraped banner data
+		a.GET("/courses", api.GetCourses)
+		a.GET("/sections/:crn", api.GetSection)
+		a.GET("/instructors/:id/schedule", api.GetInstructorSchedule)
+
+		// search across rooms, buildings, courses and instructors
+		a.GET("/search", api.Search)
+
+		// OpenAPI document for the frontend and bots
+		a.GET("/openapi.json", openapi.Handler)
+
+		// live room state changes as server-sent events
+		a.GET("/stream", api.Stream)
+
+		// buildings -> rooms -> meetings -> sections -> instructors in one request
+		a.POST("/graphql", middleware.MaxBodySize(16<<10), graph.Handler)
 	}
 
-	// start server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "5000"
+	// v2 API with typed responses and a consistent error envelope
+	// v1 above stays as is for the current frontend
+	// its own recovery and rate limit errors too, so a panic or a 429 isn't a v1 body
+	v2 := r.Group("/api/v2", api.RecoveryV2(), middleware.RateLimit(limits, "api", middleware.PerMinute(120, 60), api.ErrorV2, middleware.ByIP))
+	{
+		v2.GET("/buildings", api.GetBuildingsV2)
+		v2.GET("/buildings/:code", api.GetBuildingV2)
+		v2.GET("/rooms", api.GetRoomsV2)
+		v2.GET("/rooms/:id", api.GetRoomV2)
 	}
-	r.Run(":" + port)
+	// everything else keeps gin's default 404
+	r.NoRoute(func(c *gin.Context) {
+		if strings.HasPrefix(c.Request.URL.Path, "/api/v2/") {
+			api.NotFoundV2(c)
+		}
+	})
+
+	// write routes need a device token and get a much smaller budget per IP and per device
+	w := a.Group("",
+		middleware.MaxBodySize(4<<10),
+		middleware.RequireJSON(),
+		auth.RequireDevice(),
+		middleware.RateLimit(limits, "write", middleware.PerMinute(10, 5), middleware.JSONError, middleware.ByIP, auth.ByDevice),
+	)
+	{
+		// crowd-sourced status report for a room
+		w.POST("/rooms/:id/reports", api.PostReport)
+	}
+
+	// routes for signed in students
+	me := a.Group("/me", auth.RequireUser())
+	{
+		me.GET("", api.GetMe)
+
+		// favorite rooms and their current free/busy state
+		me.GET("/favorites", api.GetFavorites)
+		me.GET("/favorites/status", api.GetFavoritesStatus)
+
+		// saved searches
+		me.GET("/searches", api.GetSavedSearches)
+
+		// "ping me before this room frees up"
+		me.GET("/watches", api.GetWatches)
+
+		// study group holds
+		me.GET("/holds", api.GetMyHolds)
+	}
+
+	// writes for signed in students, same budget as anonymous writes but keyed by account
+	mw := me.Group("",
+		middleware.MaxBodySize(4<<10),
+		middleware.RequireJSON(),
+		middleware.RateLimit(limits, "write", middleware.PerMinute(10, 5), middleware.JSONError, middleware.ByIP, auth.ByUser),
+	)
+	{
+		mw.PUT("/favorites/:room", api.PutFavorite)
+		mw.DELETE("/favorites/:room", api.DeleteFavorite)
+		mw.POST("/searches", api.PostSavedSearch)
+		mw.DELETE("/searches/:id", api.DeleteSavedSearch)
+		mw.POST("/watches", api.PostWatch)
+		mw.DELETE("/watches/:id", api.DeleteWatch)
+		mw.DELETE("/holds/:id", api.DeleteHold)
+	}
+
+	// soft-holds are tied to an account so people can't squat rooms anonymously
+	a.POST("/rooms/:id/holds",
+		auth.RequireUser(),
+		middleware.MaxBodySize(4<<10),
+		middleware.RequireJSON(),
+		middleware.RateLimit(limits, "write", middleware.PerMinute(10, 5), middleware.JSONError, middleware.ByIP, auth.ByUser),
+		api.PostHold,
+	)
+
+	// admin routes, see auth.RequireAdmin for who gets in
+	ad := a.Group("/admin", auth.RequireAdmin())
+	{
+		// the whole dataset as NDJSON, and loading one back in
+		ad.GET("/dataset", api.GetDataset)
+		ad.POST("/dataset", middleware.MaxBodySize(64<<20), api.PostDataset)
+
+		// scheduled scrapes and the history of every run
+		ad.GET("/scrapes", api.GetScrapes)
+
+		// corrections merged over the scraped data, see availability.Overrides
+		ad.GET("/overrides", api.GetOverrides)
+		ad.POST("/overrides", middleware.MaxBodySize(4<<10), middleware.RequireJSON(), api.PostOverride)
+		ad.DELETE("/overrides/:id", api.DeleteOverride)
+
+		// who or what changed which room or building, see internal/audit
+		ad.GET("/audit", api.GetAudit)
+	}
+
+	return r
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
 }
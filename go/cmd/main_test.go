@@ -0,0 +1,525 @@
+package main
+
+// drives the server's router with httptest and checks it against the OpenAPI document:
+// every route is documented and every documented route exists, and every response is either
+// the documented status with a body matching its schema, or an error with the error envelope.
+// rooms and datasets come from store.Memory, so the operations in served answer with real data.
+// there is no database in tests, everything else (sign-in, /api/me, sections, overrides, scrapes, audit)
+// answers 401 or its 5xx and is only route-checked: it exists, it's documented, its error body is the envelope
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/api"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/auth"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/openapi"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/store"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+const testAdminToken = "test-admin-token"
+
+// the request sent for an operation, beyond its path params and auth
+type sample struct {
+	query string
+	body  string
+}
+
+var samples = map[string]sample{
+	"GET /api/room":                   {query: "room=HORIZN_2014"},
+	"GET /api/rooms":                  {query: "building=HORIZN"},
+	"GET /api/courses":                {query: "q=CS+310"},
+	"GET /api/search":                 {query: "q=horizon"},
+	"POST /api/graphql":               {body: `{"query": "{ buildings { code name } }"}`},
+	"POST /api/rooms/:id/reports":     {body: `{"status": "locked"}`},
+	"POST /api/rooms/:id/holds":       {body: `{"group": "CS 310", "end": "2030-01-01T16:00:00Z"}`},
+	"POST /api/me/searches":           {body: `{"name": "quiet", "building": "HORIZN"}`},
+	"POST /api/me/watches":            {body: `{"room_id": "HORIZN_2014", "lead_minutes": 5, "channel": "email"}`},
+	"POST /api/admin/overrides":       {body: `{"kind": "hide_building", "building": "HORIZN"}`},
+	"GET /api/v2/rooms":               {query: "building=HORIZN"},
+	"POST /api/admin/dataset":         {query: "force=true"},
+	"GET /api/admin/scrapes":          {query: "limit=5"},
+	"GET /api/admin/audit":            {query: "limit=5"},
+	"DELETE /api/admin/overrides/:id": {},
+}
+
+// operations store.Memory can answer, they have to come back with their documented status
+var served = map[string]bool{
+	"GET /health":                 true,
+	"GET /":                       true,
+	"GET /metrics":                true,
+	"GET /api/openapi.json":       true,
+	"POST /auth/logout":           true,
+	"GET /api/buildings":          true,
+	"GET /api/rooms":              true,
+	"POST /api/device":            true,
+	"GET /api/search":             true,
+	"POST /api/graphql":           true,
+	"GET /api/v2/buildings":       true,
+	"GET /api/v2/buildings/:code": true,
+	"GET /api/v2/rooms":           true,
+	"GET /api/admin/dataset":      true,
+	"POST /api/admin/dataset":     true,
+}
+
+// values for path params
+var pathValues = map[string]string{
+	"id":   "HORIZN_2014",
+	"room": "HORIZN_2014",
+	"code": "HORIZN",
+	"crn":  "10492",
+}
+
+func testRooms() []types.Room {
+	return []types.Room{
+		{ID: "HORIZN_2014", Building: "HORIZN", Number: "2014", Capacity: 40,
+			Schedule: []types.Meeting{{Day: 2, StartTime: 9 * 60, EndTime: 10*60 + 15, Location: "HORIZN 2014",
+				Label: []types.MeetingInfo{{ID: "10492", CourseID: "CS310", Section: "001", Professor: "Doe, Jane"}}}}},
+		{ID: "HORIZN_1010", Building: "HORIZN", Number: "1010", Capacity: 25},
+		{ID: "ENGR_1103", Building: "ENGR", Number: "1103", Capacity: 90},
+	}
+}
+
+func testRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	t.Setenv("ADMIN_TOKEN", testAdminToken)
+	t.Setenv("DEVICE_TOKEN_SECRET", "test-device-secret")
+	if err := auth.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	mem := store.NewMemory()
+	if err := mem.ImportDataset(t.Context(), store.Dataset{Term: "202610", Rooms: testRooms()}); err != nil {
+		t.Fatal(err)
+	}
+	rooms, datasets := api.RoomStore, api.DatasetStore
+	api.RoomStore, api.DatasetStore = mem, mem
+	t.Cleanup(func() { api.RoomStore, api.DatasetStore = rooms, datasets })
+
+	return router("http://localhost:3000")
+}
+
+func TestOpenAPIRoutes(t *testing.T) {
+	r := testRouter(t)
+
+	documented := make(map[string]bool, len(openapi.Operations))
+	for _, op := range openapi.Operations {
+		documented[op.Method+" "+op.Path] = true
+	}
+	registered := make(map[string]bool)
+	for _, route := range r.Routes() {
+		key := route.Method + " " + route.Path
+		registered[key] = true
+		if !documented[key] {
+			t.Errorf("undocumented route %s, add it to openapi.Operations", key)
+		}
+	}
+	for key := range documented {
+		if !registered[key] {
+			t.Errorf("documented route is not registered %s", key)
+		}
+	}
+}
+
+func TestOpenAPIResponses(t *testing.T) {
+	r := testRouter(t)
+	doc := document(t)
+
+	for _, op := range openapi.Operations {
+		key := op.Method + " " + op.Path
+		if op.Stream {
+			// long lived, nothing to compare a single body with
+			continue
+		}
+		t.Run(key, func(t *testing.T) {
+			req := newRequest(t, r, op)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			spec := doc.operation(t, op)
+			status := op.Status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if served[key] && w.Code != status {
+				t.Fatalf("answered %d, want %d: %s", w.Code, status, w.Body)
+			}
+			responses := spec["responses"].(map[string]any)
+			resp, documented := responses[strconv.Itoa(w.Code)].(map[string]any)
+			switch {
+			case documented:
+				content, _ := resp["content"].(map[string]any)
+				doc.checkContent(t, content, w)
+			case w.Code >= 400:
+				resp := responses["default"].(map[string]any)
+				doc.checkContent(t, resp["content"].(map[string]any), w)
+			default:
+				t.Errorf("status %d is not documented (want %d or an error)", w.Code, status)
+			}
+		})
+	}
+}
+
+// frontend/lib/api.ts is generated, regenerate it with go run ./cmd/openapi -ts ../frontend/lib/api.ts
+func TestTypeScriptClient(t *testing.T) {
+	got, err := os.ReadFile("../../frontend/lib/api.ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != openapi.TypeScript() {
+		t.Error("frontend/lib/api.ts is out of date, regenerate it with go run ./cmd/openapi -ts ../frontend/lib/api.ts")
+	}
+}
+
+// a room listing only has fields the spec documents for ?fields=, and each of them works on its own
+func TestOpenAPIRoomFields(t *testing.T) {
+	r := testRouter(t)
+
+	var documented []string
+	for _, op := range openapi.Operations {
+		if op.Method != "GET" || op.Path != "/api/rooms" {
+			continue
+		}
+		for _, p := range op.Params {
+			if p.Name == "fields" {
+				_, list, _ := strings.Cut(p.Description, "subset of ")
+				documented = strings.Split(list, ", ")
+			}
+		}
+	}
+	if len(documented) == 0 {
+		t.Fatal("GET /api/rooms doesn't document fields")
+	}
+
+	var rooms []map[string]any
+	get(t, r, "/api/rooms?building=HORIZN", &rooms)
+	for k := range rooms[0] {
+		if !slices.Contains(documented, strings.ToLower(k)) {
+			t.Errorf("rooms have a %s field the spec doesn't list in fields (%v)", k, documented)
+		}
+	}
+	for _, f := range documented {
+		var projected []map[string]any
+		get(t, r, "/api/rooms?building=HORIZN&fields="+f, &projected)
+		if len(projected[0]) != 1 {
+			t.Errorf("fields=%s returned %v", f, projected[0])
+		}
+	}
+}
+
+// v2 rooms page through RoomStore with next_cursor, offset is refused
+func TestV2RoomsCursor(t *testing.T) {
+	r := testRouter(t)
+
+	var ids []string
+	url := "/api/v2/rooms?building=horizn&limit=1"
+	for range 5 {
+		var page api.CursorPageResponse[api.RoomResponse]
+		get(t, r, url, &page)
+		for _, room := range page.Data {
+			ids = append(ids, room.ID)
+		}
+		if page.NextCursor == nil {
+			break
+		}
+		url = "/api/v2/rooms?building=horizn&limit=1&cursor=" + *page.NextCursor
+	}
+	if want := []string{"HORIZN_1010", "HORIZN_2014"}; !slices.Equal(ids, want) {
+		t.Errorf("paged through %v, want %v", ids, want)
+	}
+
+	for _, url := range []string{"/api/v2/rooms?offset=50", "/api/v2/rooms?cursor=nope", "/api/v2/rooms?limit=0"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		var body api.ErrorResponse
+		if w.Code != http.StatusBadRequest || json.Unmarshal(w.Body.Bytes(), &body) != nil || body.Error.Code != api.CodeBadRequest {
+			t.Errorf("GET %s: %d %s, want a 400 bad_request", url, w.Code, w.Body)
+		}
+	}
+}
+
+// a client over the limit gets a 429 in the envelope of the API version it called
+func TestRateLimitEnvelope(t *testing.T) {
+	r := testRouter(t)
+
+	call := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		return w
+	}
+	// v1 and v2 share the 60 request burst
+	var w *httptest.ResponseRecorder
+	for range 61 {
+		if w = call("/api/v2/buildings"); w.Code == http.StatusTooManyRequests {
+			break
+		}
+	}
+	var v2 api.ErrorResponse
+	if w.Code != http.StatusTooManyRequests || json.Unmarshal(w.Body.Bytes(), &v2) != nil || v2.Error.Code != api.CodeRateLimited {
+		t.Errorf("v2: %d %s, want a 429 rate_limited", w.Code, w.Body)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("v2: no Retry-After")
+	}
+
+	w = call("/api/buildings")
+	var v1 struct {
+		Error string `json:"error"`
+	}
+	if w.Code != http.StatusTooManyRequests || json.Unmarshal(w.Body.Bytes(), &v1) != nil || v1.Error == "" {
+		t.Errorf("v1: %d %s, want a 429 with an error string", w.Code, w.Body)
+	}
+}
+
+func get(t *testing.T, r http.Handler, url string, v any) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s: %d %s", url, w.Code, w.Body)
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+}
+
+func newRequest(t *testing.T, r http.Handler, op openapi.Operation) *http.Request {
+	t.Helper()
+	key := op.Method + " " + op.Path
+	s := samples[key]
+
+	parts := strings.Split(op.Path, "/")
+	for i, p := range parts {
+		if name, ok := strings.CutPrefix(p, ":"); ok {
+			parts[i] = pathValues[name]
+		}
+	}
+	url := strings.Join(parts, "/")
+	if s.query != "" {
+		url += "?" + s.query
+	}
+
+	body := s.body
+	if op.Body != nil && body == "" {
+		body = "{}"
+	}
+	contentType := "application/json"
+	if op.BodyType != "" {
+		contentType = op.BodyType
+		if op.Path == "/api/admin/dataset" {
+			// the dataset being served, imported back in
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, op.Path, nil)
+			req.Header.Set("Authorization", "Bearer "+testAdminToken)
+			r.ServeHTTP(w, req)
+			body = w.Body.String()
+		}
+	}
+
+	var req *http.Request
+	if body != "" {
+		req = httptest.NewRequest(op.Method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+	} else {
+		req = httptest.NewRequest(op.Method, url, nil)
+	}
+	switch op.Path {
+	case "/api/rooms/:id/reports":
+		token, _, err := auth.NewDeviceToken(time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(auth.DeviceHeader, token)
+	}
+	if strings.HasPrefix(op.Path, "/api/admin/") {
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	}
+	return req
+}
+
+// the OpenAPI document as plain JSON values, the way a client reads it
+type spec struct {
+	paths   map[string]any
+	schemas map[string]any
+}
+
+func document(t *testing.T) spec {
+	t.Helper()
+	b, err := json.Marshal(openapi.Document())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	return spec{
+		paths:   doc["paths"].(map[string]any),
+		schemas: doc["components"].(map[string]any)["schemas"].(map[string]any),
+	}
+}
+
+func (d spec) operation(t *testing.T, op openapi.Operation) map[string]any {
+	t.Helper()
+	p := op.Path
+	for _, part := range strings.Split(p, "/") {
+		if name, ok := strings.CutPrefix(part, ":"); ok {
+			p = strings.Replace(p, part, "{"+name+"}", 1)
+		}
+	}
+	o, ok := d.paths[p].(map[string]any)[strings.ToLower(op.Method)].(map[string]any)
+	if !ok {
+		t.Fatalf("%s %s is not in the document", op.Method, p)
+	}
+	return o
+}
+
+// checks the recorded response against the documented content, nil content means no body to check
+func (d spec) checkContent(t *testing.T, content map[string]any, w *httptest.ResponseRecorder) {
+	t.Helper()
+	if content == nil {
+		return
+	}
+	got := w.Header().Get("Content-Type")
+	for mediaType, c := range content {
+		if !strings.HasPrefix(got, mediaType) {
+			t.Errorf("content type %q, documented %q (body %s)", got, mediaType, w.Body)
+			return
+		}
+		schema := c.(map[string]any)["schema"].(map[string]any)
+		if mediaType == "application/x-ndjson" {
+			sc := bufio.NewScanner(bytes.NewReader(w.Body.Bytes()))
+			for line := 1; sc.Scan(); line++ {
+				d.checkJSON(t, fmt.Sprintf("line %d", line), sc.Bytes(), schema)
+			}
+			continue
+		}
+		d.checkJSON(t, "body", w.Body.Bytes(), schema)
+	}
+}
+
+func (d spec) checkJSON(t *testing.T, where string, b []byte, schema map[string]any) {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		t.Errorf("%s is not JSON: %v (%q)", where, err, b)
+		return
+	}
+	for _, problem := range d.validate("$", v, schema) {
+		t.Errorf("%s: %s", where, problem)
+	}
+}
+
+// the schema rules openapi.Document uses: $ref, type, format, nullable, enum, properties,
+// required, items and additionalProperties. objects with properties may not have other keys,
+// so a field added to a response without going through a go type shows up here
+// NOTE: encoding/json writes nil slices and maps as null, so null passes for arrays and maps
+func (d spec) validate(at string, v any, schema map[string]any) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		s, ok := d.schemas[name].(map[string]any)
+		if !ok {
+			return []string{at + ": unknown schema " + ref}
+		}
+		return d.validate(at, v, s)
+	}
+
+	typ, _ := schema["type"].(string)
+	if v == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable || typ == "" || typ == "array" || typ == "object" {
+			return nil
+		}
+		return []string{at + ": null, want " + typ}
+	}
+
+	var problems []string
+	wrong := func() []string {
+		return []string{fmt.Sprintf("%s: %T %v, want %s", at, v, v, typ)}
+	}
+	switch typ {
+	case "":
+		return nil
+	case "string":
+		s, ok := v.(string)
+		if !ok {
+			return wrong()
+		}
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+				problems = append(problems, at+": not a date-time "+s)
+			}
+		}
+		if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, any(s)) {
+			problems = append(problems, fmt.Sprintf("%s: %q not one of %v", at, s, enum))
+		}
+	case "integer":
+		n, ok := v.(float64)
+		if !ok || n != math.Trunc(n) {
+			return wrong()
+		}
+	case "number":
+		if _, ok := v.(float64); !ok {
+			return wrong()
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return wrong()
+		}
+	case "array":
+		list, ok := v.([]any)
+		if !ok {
+			return wrong()
+		}
+		items, _ := schema["items"].(map[string]any)
+		for i, item := range list {
+			problems = append(problems, d.validate(fmt.Sprintf("%s[%d]", at, i), item, items)...)
+		}
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return wrong()
+		}
+		props, _ := schema["properties"].(map[string]any)
+		extra, _ := schema["additionalProperties"].(map[string]any)
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := obj[name.(string)]; !ok {
+				problems = append(problems, at+": missing "+name.(string))
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			switch {
+			case props[k] != nil:
+				problems = append(problems, d.validate(at+"."+k, obj[k], props[k].(map[string]any))...)
+			case extra != nil:
+				problems = append(problems, d.validate(at+"."+k, obj[k], extra)...)
+			case len(props) > 0:
+				problems = append(problems, at+": undocumented field "+k)
+			}
+		}
+	default:
+		problems = append(problems, at+": unknown schema type "+typ)
+	}
+	return problems
+}
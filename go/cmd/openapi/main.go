@@ -0,0 +1,42 @@
+package main
+
+// writes the OpenAPI document and the generated typescript client
+//
+//	go run ./cmd/openapi                          # spec to stdout
+//	go run ./cmd/openapi -out openapi.json
+//	go run ./cmd/openapi -ts ../frontend/lib/api.ts
+//
+// go test ./cmd/ drives the server's router and checks every route and response against the same spec
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/openapi"
+)
+
+func main() {
+	out := flag.String("out", "", "write the OpenAPI JSON here instead of stdout")
+	ts := flag.String("ts", "", "also write the typescript client here")
+	flag.Parse()
+
+	doc, err := json.MarshalIndent(openapi.Document(), "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	doc = append(doc, '\n')
+
+	if *out == "" {
+		os.Stdout.Write(doc)
+	} else if err := os.WriteFile(*out, doc, 0644); err != nil {
+		log.Fatal(err)
+	}
+
+	if *ts != "" {
+		if err := os.WriteFile(*ts, []byte(openapi.TypeScript()), 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
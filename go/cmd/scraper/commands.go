@@ -0,0 +1,361 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/audit"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/banner"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/scraper"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/store"
+)
+
+// firestore commands shouldn't hang forever when it can't be reached
+const storeTimeout = 2 * time.Minute
+
+// runs fn holding the scrape lock so it never writes over a running scrape or another import
+func locked(ctx context.Context, fn func(ctx context.Context) int) int {
+	code := 1
+	err := scraper.Locked(ctx, scraper.ImportLockID(), func(ctx context.Context) error {
+		code = fn(ctx)
+		return nil
+	})
+	if errors.Is(err, scraper.ErrRunning) {
+		slog.Error("a scrape or import is running, see GET /api/admin/scrapes")
+		return 1
+	}
+	if err != nil {
+		slog.Error("taking the scrape lock", "err", err)
+		return 1
+	}
+	return code
+}
+
+func cmdSubjects(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("subjects", flag.ExitOnError)
+	term := fs.String("term", scraper.DefaultTerm, "banner term code")
+	fs.Parse(args)
+
+	client := banner.New(banner.Config{BaseURL: scraper.DefaultOptions().BaseURL, Term: *term})
+	subjects, err := client.Subjects(ctx)
+	if err != nil {
+		slog.Error("fetching subjects", "err", err)
+		return 1
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, s := range subjects {
+		fmt.Fprintf(w, "%s\t%s\n", s.Code, s.Description)
+	}
+	w.Flush()
+	return 0
+}
+
+func cmdTerms(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("terms", flag.ExitOnError)
+	fs.Parse(args)
+
+	client := banner.New(banner.Config{BaseURL: scraper.DefaultOptions().BaseURL})
+	terms, err := client.Terms(ctx)
+	if err != nil {
+		slog.Error("fetching terms", "err", err)
+		return 1
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, t := range terms {
+		fmt.Fprintf(w, "%s\t%s\n", t.Code, t.Description)
+	}
+	w.Flush()
+	return 0
+}
+
+func cmdValidate(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	limits := thresholdFlags(fs)
+	out := fs.String("report", "-", "where the report is written, - for stdout")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: scraper validate [flags] SOURCE")
+		return 2
+	}
+	if !initStore() {
+		return 1
+	}
+	ctx, cancel := context.WithTimeout(ctx, storeTimeout)
+	defer cancel()
+
+	ds, err := loadDataset(ctx, fs.Arg(0))
+	if err != nil {
+		slog.Error("loading dataset", "err", err)
+		return 1
+	}
+	prev, err := scraper.PreviousSummary(ctx)
+	if err != nil {
+		slog.Error("reading the live data to compare against", "err", err)
+		return 1
+	}
+	// a dataset file doesn't know which subjects came back empty, that check is skipped
+	rep := scraper.Validate(ds, prev, 0, nil, *limits)
+	if *out == "-" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(rep)
+	} else {
+		err = rep.WriteFile(*out)
+	}
+	if err != nil {
+		slog.Error("writing report", "err", err)
+		return 1
+	}
+	if !rep.Passed {
+		slog.Error("validation failed", "failed", rep.Failed())
+		return 1
+	}
+	return 0
+}
+
+func cmdPublish(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("publish", flag.ExitOnError)
+	limits := thresholdFlags(fs)
+	report := fs.String("report", "scrape.report.json", "where the validation report is written")
+	force := fs.Bool("force", false, "publish even if validation fails")
+	keep := fs.Int("keep", scraper.DefaultKeep, "how many snapshots to keep after publishing")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: scraper publish [flags] FILE")
+		return 2
+	}
+	if !initStore() {
+		return 1
+	}
+
+	ds, err := scraper.ReadDatasetFile(fs.Arg(0))
+	if err != nil {
+		slog.Error("loading dataset", "err", err)
+		return 1
+	}
+	if ds.Term == "" {
+		slog.Error("dataset has no term")
+		return 1
+	}
+	return locked(ctx, func(ctx context.Context) int {
+		live, err := firestore.GetAllRooms(ctx)
+		if err != nil {
+			slog.Error("reading the live data to compare against", "err", err)
+			return 1
+		}
+		rep := scraper.Validate(ds, scraper.SummaryOf(live), 0, nil, *limits)
+		if err := rep.WriteFile(*report); err != nil {
+			slog.Error("writing validation report", "path", *report, "err", err)
+		}
+		if !rep.Passed {
+			if !*force {
+				slog.Error("validation failed, nothing was saved", "failed", rep.Failed(), "report", *report)
+				return 1
+			}
+			slog.Warn("validation failed, publishing anyway because of --force", "failed", rep.Failed(), "report", *report)
+		}
+
+		version, err := scraper.Save(ctx, ds)
+		if err != nil {
+			slog.Error("saving dataset", "err", err)
+			return 1
+		}
+		if err := scraper.Publish(ctx, version, *keep); err != nil {
+			slog.Error("the snapshot is saved but not live, run `scraper rollback "+version+"` to publish it", "err", err)
+			return 1
+		}
+		audit.Record(ctx, audit.Published(audit.CLIActor(), version, live, ds.Rooms)...)
+		return 0
+	})
+}
+
+// no validation and no term checks, for seeding a dev project or restoring a shared export
+func cmdImport(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: scraper import FILE")
+		return 2
+	}
+	if !initStore() {
+		return 1
+	}
+
+	ds, err := scraper.ReadDatasetFile(fs.Arg(0))
+	if err != nil {
+		slog.Error("loading dataset", "err", err)
+		return 1
+	}
+	return locked(ctx, func(ctx context.Context) int {
+		live, err := firestore.GetAllRooms(ctx)
+		if err != nil {
+			slog.Error("reading the live data", "err", err)
+			return 1
+		}
+		var to store.Datasets = firestore.Store{}
+		if err := to.ImportDataset(ctx, ds); err != nil {
+			slog.Error("importing dataset", "err", err)
+			return 1
+		}
+		audit.Record(ctx, audit.Published(audit.CLIActor(), "import "+fs.Arg(0), live, ds.Rooms)...)
+		slog.Info("imported dataset", "term", ds.Term, "rooms", len(ds.Rooms), "sections", len(ds.Sections), "instructors", len(ds.Instructors))
+		return 0
+	})
+}
+
+func cmdDiff(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print the diff as JSON")
+	fs.Parse(args)
+
+	from, to := "live", ""
+	switch fs.NArg() {
+	case 1:
+		to = fs.Arg(0)
+	case 2:
+		from, to = fs.Arg(0), fs.Arg(1)
+	default:
+		fmt.Fprintln(os.Stderr, "usage: scraper diff [--json] [FROM] TO")
+		return 2
+	}
+	ctx, cancel := context.WithTimeout(ctx, storeTimeout)
+	defer cancel()
+
+	a, err := loadDataset(ctx, from)
+	if err != nil {
+		slog.Error("loading dataset", "source", from, "err", err)
+		return 1
+	}
+	b, err := loadDataset(ctx, to)
+	if err != nil {
+		slog.Error("loading dataset", "source", to, "err", err)
+		return 1
+	}
+	d := scraper.Diff(a, b)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(d)
+	} else {
+		d.WriteText(os.Stdout)
+	}
+	return 0
+}
+
+func cmdExport(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	out := fs.String("out", "-", "file to write, - for stdout")
+	format := fs.String("format", "", "json or ndjson, defaults to ndjson for .ndjson/.jsonl files and json otherwise")
+	fs.Parse(args)
+	if *format == "" {
+		*format = scraper.FormatOf(*out)
+	}
+	if *format != scraper.FormatJSON && *format != scraper.FormatNDJSON {
+		fmt.Fprintln(os.Stderr, "--format must be json or ndjson")
+		return 2
+	}
+	src := "live"
+	if fs.NArg() > 0 {
+		src = fs.Arg(0)
+	}
+	ctx, cancel := context.WithTimeout(ctx, storeTimeout)
+	defer cancel()
+
+	ds, err := loadDataset(ctx, src)
+	if err != nil {
+		slog.Error("loading dataset", "source", src, "err", err)
+		return 1
+	}
+	if err := scraper.WriteDatasetFile(*out, ds, *format); err != nil {
+		slog.Error("writing dataset", "err", err)
+		return 1
+	}
+	return 0
+}
+
+func cmdSnapshots(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("snapshots", flag.ExitOnError)
+	fs.Parse(args)
+	if !initStore() {
+		return 1
+	}
+	ctx, cancel := context.WithTimeout(ctx, storeTimeout)
+	defer cancel()
+
+	list, err := firestore.ListSnapshots(ctx)
+	if err != nil {
+		slog.Error("listing snapshots", "err", err)
+		return 1
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tVERSION\tTERM\tCREATED\tROOMS\tSECTIONS\tINSTRUCTORS\tSTATE")
+	for _, snap := range list {
+		mark, state := "", "complete"
+		if snap.Active {
+			mark = "*"
+		}
+		if !snap.Complete {
+			state = "incomplete"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%d\t%s\n", mark, snap.Version, snap.Term,
+			snap.CreatedAt.Local().Format(time.DateTime), snap.Rooms, snap.Sections, snap.Instructors, state)
+	}
+	w.Flush()
+	return 0
+}
+
+func cmdRollback(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("rollback", flag.ExitOnError)
+	fs.Parse(args)
+	if !initStore() {
+		return 1
+	}
+	ctx, cancel := context.WithTimeout(ctx, storeTimeout)
+	defer cancel()
+
+	// moving the pointer under a running scrape would be undone when it publishes
+	return locked(ctx, func(ctx context.Context) int {
+		live, err := firestore.GetAllRooms(ctx)
+		if err != nil {
+			slog.Error("reading the live data", "err", err)
+			return 1
+		}
+		version, err := firestore.RollbackSnapshot(ctx, fs.Arg(0))
+		if err != nil {
+			slog.Error("rolling back", "err", err)
+			return 1
+		}
+		if rolled, err := firestore.ReadSnapshot(ctx, version); err != nil {
+			slog.Warn("reading the snapshot for the audit log", "err", err)
+		} else {
+			audit.Record(ctx, audit.Published(audit.CLIActor(), version, live, rolled.Rooms)...)
+		}
+		slog.Info("rolled back, the API serves this snapshot within a minute", "snapshot", version)
+		return 0
+	})
+}
+
+// SOURCE: a dataset JSON or NDJSON file (- for stdin), "live", or a snapshot version
+func loadDataset(ctx context.Context, src string) (store.Dataset, error) {
+	if src == "-" {
+		return scraper.ReadDatasetFile(src)
+	}
+	if _, err := os.Stat(src); err == nil {
+		return scraper.ReadDatasetFile(src)
+	}
+	if !initStore() {
+		return store.Dataset{}, errors.New("no firestore to read " + src + " from")
+	}
+	if src == "live" {
+		return firestore.Store{}.ExportDataset(ctx)
+	}
+	return firestore.ReadSnapshot(ctx, src)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/cron"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/scraper"
+)
+
+func cmdScrape(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("scrape", flag.ExitOnError)
+	options := scrapeFlags(fs)
+	dryRun := fs.Bool("dry-run", false, "don't write to firestore, print the dataset instead (see --out)")
+	out := fs.String("out", "", "also write the dataset as JSON to this file, - for stdout (default - with --dry-run)")
+	resume := fs.Bool("resume", false, "continue from the checkpoint of an interrupted run")
+	fs.Parse(args)
+
+	opts := options()
+	opts.DryRun = *dryRun
+	opts.Out = *out
+	opts.Resume = *resume
+	opts.Trigger = "cli"
+	if len(opts.Subjects) > 0 && !opts.DryRun {
+		fmt.Fprintln(os.Stderr, "--subjects only fetches part of the term, publishing it would remove every other subject's rooms. add --dry-run (and --out FILE)")
+		return 2
+	}
+	if opts.DryRun {
+		if opts.Out == "" {
+			opts.Out = "-"
+		}
+		// only to compare against the live data, a dry run works without firestore
+		if !initStore() {
+			slog.Warn("no firestore, validating without the live data")
+		}
+	} else if !initStore() {
+		return 1
+	}
+
+	started := time.Now()
+	defer func() {
+		metrics.ScrapeDuration.Set(time.Since(started).Seconds())
+		if err := metrics.Push("scraper"); err != nil {
+			slog.Error("pushing metrics", "err", err)
+		}
+	}()
+
+	if _, err := scraper.Run(ctx, opts); err != nil {
+		switch {
+		case errors.Is(err, scraper.ErrRunning):
+			slog.Error("another scrape is running, see GET /api/admin/scrapes")
+		case errors.Is(err, scraper.ErrValidation):
+			// the checkpoint stays, --resume --force publishes this scrape without fetching again
+			slog.Error("nothing was saved, rerun with --resume --force to publish it anyway", "err", err, "checkpoint", opts.Checkpoint)
+		default:
+			slog.Error("scrape failed, rerun with --resume to continue", "err", err, "checkpoint", opts.Checkpoint)
+		}
+		return 1
+	}
+	slog.Info("== all subjects processed ==", "duration", time.Since(started).Round(time.Second))
+	return 0
+}
+
+// the same runs as `scrape` on a schedule, for machines that don't run the API server
+// (the server does it in-process with SCRAPE_SCHEDULE)
+func cmdDaemon(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("daemon", flag.ExitOnError)
+	options := scrapeFlags(fs)
+	schedule := fs.String("schedule", cmp.Or(os.Getenv("SCRAPE_SCHEDULE"), scraper.DefaultSchedule),
+		"cron expression, defaults to SCRAPE_SCHEDULE or 3am eastern every night")
+	fs.Parse(args)
+
+	sched, err := cron.Parse(*schedule)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	opts := options()
+	opts.Trigger = "daemon"
+	if len(opts.Subjects) > 0 {
+		fmt.Fprintln(os.Stderr, "--subjects only fetches part of the term, scheduled runs publish every subject")
+		return 2
+	}
+	if !initStore() {
+		return 1
+	}
+
+	// every run starts from scratch, a resume could publish yesterday's subjects
+	scraper.Schedule(ctx, sched, opts, func(res *scraper.Result, err error) {
+		if errors.Is(err, scraper.ErrRunning) {
+			return
+		}
+		if err := metrics.Push("scraper"); err != nil {
+			slog.Error("pushing metrics", "err", err)
+		}
+	})
+	return 0
+}
+
+// flags shared by scrape and daemon, the returned func builds the options after fs.Parse
+func scrapeFlags(fs *flag.FlagSet) func() scraper.Options {
+	def := scraper.DefaultOptions()
+	term := fs.String("term", def.Term, "banner term code, see `scraper terms`")
+	subjects := fs.String("subjects", "", "comma separated subjects to fetch instead of all of them, ex) CS,MATH (dry runs only)")
+	checkpoint := fs.String("checkpoint", def.Checkpoint, "where scrape progress is kept")
+	allowPartial := fs.Bool("allow-partial", false, "save even if some subjects failed")
+	keep := fs.Int("keep", def.Keep, "how many snapshots to keep after publishing")
+	sessions := fs.Int("sessions", def.Sessions, "banner sessions fetching subjects in parallel")
+	rate := fs.Float64("rate", def.Rate, "requests per second to banner across all sessions, 0 for no limit")
+	limits := thresholdFlags(fs)
+	report := fs.String("report", "scrape.report.json", "where the validation report is written")
+	force := fs.Bool("force", false, "publish even if validation fails")
+
+	return func() scraper.Options {
+		opts := def
+		opts.Term = *term
+		opts.Checkpoint = *checkpoint
+		opts.AllowPartial = *allowPartial
+		opts.Keep = *keep
+		opts.Sessions = max(*sessions, 1)
+		opts.Rate = *rate
+		opts.Limits = *limits
+		opts.Report = *report
+		opts.Force = *force
+		for _, s := range strings.Split(*subjects, ",") {
+			if s = strings.ToUpper(strings.TrimSpace(s)); s != "" {
+				opts.Subjects = append(opts.Subjects, s)
+			}
+		}
+		return opts
+	}
+}
+
+// sanity checks before a dataset replaces the live data
+func thresholdFlags(fs *flag.FlagSet) *scraper.Thresholds {
+	limits := scraper.DefaultThresholds
+	fs.IntVar(&limits.MinRooms, "min-rooms", limits.MinRooms, "fewest rooms a scrape can have")
+	fs.Float64Var(&limits.MaxRoomDrop, "max-room-drop", limits.MaxRoomDrop, "largest drop in rooms vs the live data, 0.2 = 20%")
+	fs.Float64Var(&limits.MaxMeetingDrop, "max-meeting-drop", limits.MaxMeetingDrop, "largest drop in meetings vs the live data")
+	fs.Float64Var(&limits.MaxBuildingDrop, "max-building-drop", limits.MaxBuildingDrop, "largest drop in rooms of a single building")
+	fs.IntVar(&limits.MinBuildingRooms, "min-building-rooms", limits.MinBuildingRooms, "buildings with fewer rooms skip the building check")
+	fs.Float64Var(&limits.MaxEmptySubjects, "max-empty-subjects", limits.MaxEmptySubjects, "largest share of subjects without sections")
+	fs.IntVar(&limits.MaxBadMeetings, "max-bad-meetings", limits.MaxBadMeetings, "most meetings with impossible times")
+	return &limits
+}
@@ -2,432 +2,116 @@ package main
 
 // scraper for GMU courses
 // performs guest handshake to get session cookie + synchronizer token
-// then fetches course data for a given subject and term
-// outputs the raw JSON response to stdout
+// then fetches course data for every subject of a term
+// the banner HTTP side (retries, session handling) lives in internal/banner,
+// checkpointing, parsing, validation and snapshots live in internal/scraper
+//
+// usage: scraper <command> [flags], run `scraper help` for the list
+// with no command (or only flags) it runs scrape
 //
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"log"
-	"math/rand"
-	"net/http"
-	"net/http/cookiejar"
-	"net/url"
+	"log/slog"
 	"os"
-	"regexp"
-	"strconv"
+	"os/signal"
 	"strings"
-	"sync"
-	"time"
+	"syscall"
 
 	"github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
-	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/logging"
 
 	"github.com/joho/godotenv"
 )
 
-const (
-	BaseURL = "https://ssbstureg.gmu.edu/StudentRegistrationSsb"
-	Term    = "202610" // spring 2026 term
-)
+type command struct {
+	name  string
+	args  string
+	about string
+	run   func(ctx context.Context, args []string) int
+}
 
-func main() {
-	// initialize HTTP client with cookie jar
-	// NOTE: handles JSESSIONID automatically
-	jar, _ := cookiejar.New(nil)
-	client := &http.Client{
-		Jar:     jar,
-		Timeout: 15 * time.Second,
+var commands []command
+
+func init() {
+	// assigned here since help refers back to the list
+	commands = []command{
+		{"scrape", "[flags]", "fetch a term from banner, validate it and publish it (or print it with --dry-run)", cmdScrape},
+		{"daemon", "[flags]", "scrape on a cron schedule until stopped, takes the scrape flags too", cmdDaemon},
+		{"subjects", "[--term T]", "list the subjects offered in a term", cmdSubjects},
+		{"terms", "", "list the terms banner knows about", cmdTerms},
+		{"validate", "SOURCE", "check a dataset against the live data and print the report", cmdValidate},
+		{"publish", "FILE", "validate a dataset file, save it as a new snapshot and make it live", cmdPublish},
+		{"import", "FILE", "make a dataset file live as is, for seeding a dev project or restoring an export", cmdImport},
+		{"diff", "[FROM] TO", "what changed between two datasets, FROM defaults to live", cmdDiff},
+		{"export", "[SOURCE]", "write a dataset as JSON or NDJSON, SOURCE defaults to live", cmdExport},
+		{"snapshots", "", "list snapshots, * marks the one the API serves", cmdSnapshots},
+		{"rollback", "[VERSION]", "serve an older snapshot again, default the one before the active one", cmdRollback},
+		{"help", "", "show this list", cmdHelp},
 	}
+}
 
-	// initialize firestore
-	// NOTE: this is not an API endpoint, so we init firebase here
+func main() {
+	// load env var
 	err := godotenv.Load()
+	logging.Init()
 	if err != nil {
-		log.Fatal(".env file failed to load")
-	}
-
-	if err := firestore.Init(); err != nil {
-		log.Printf("Failed to initialize Firestore: %v", err)
-	}
-	defer firestore.Close()
-
-	// guest handshake to get X-Synchronizer-Token
-	fmt.Println("== 1 == visiting Search Page to get Token...")
-
-	// visit the main page just to parse the token from the HTML
-	targetURL := BaseURL + "/ssb/classSearch/classSearch"
-
-	req, _ := http.NewRequest("GET", targetURL, nil)
-	setHeaders(req, "")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer resp.Body.Close()
-
-	bodyBytes, _ := io.ReadAll(resp.Body)
-	bodyString := string(bodyBytes)
-
-	if resp.StatusCode != 200 {
-		log.Fatalf("server returned %d", resp.StatusCode)
-	}
-
-	// regex to find window.synchronizerToken
-	re := regexp.MustCompile(`name="synchronizerToken"\s+content="([^"]+)"`)
-	matches := re.FindStringSubmatch(bodyString)
-
-	if len(matches) < 2 {
-		// debugging html dump
-		os.WriteFile("debug_page.html", bodyBytes, 0644)
-		log.Fatal("could not find X-Synchronizer-Token in HTML.")
-	}
-	token := matches[1]
-	fmt.Printf("	> Token Found: %s\n", token)
-
-	// setting the term
-	// all requests will happen after setting the term
-	fmt.Println("== 2 == setting term to", Term, "...")
-
-	formData := url.Values{}
-	formData.Set("term", Term)
-	formData.Set("studyPath", "")
-	formData.Set("studyPathText", "")
-	formData.Set("startDatepicker", "")
-	formData.Set("endDatepicker", "")
-
-	// NOTE: use the "uniqueSessionId" param from the cookies
-	// to mimic a real user session
-	uniqueID := fmt.Sprintf("guest%d", time.Now().Unix())
-	termUrl := fmt.Sprintf("%s/ssb/term/search?mode=search&uniqueSessionId=%s", BaseURL, uniqueID)
-
-	req, _ = http.NewRequest("POST", termUrl, strings.NewReader(formData.Encode()))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	// attach the token header
-	setHeaders(req, token)
-
-	resp, err = client.Do(req)
-	if err != nil {
-		log.Fatal(err)
+		slog.Info("no .env file found, relying on system env vars")
 	}
-	resp.Body.Close()
 
-	// search for classes
-	// use this to get all subjects in prod
-	subjects, err := GetSubjects(client, token)
-	if err != nil {
-		log.Fatal(err)
+	args := os.Args[1:]
+	name := "scrape"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
 	}
 
-	// in local we are only testing CS and MATH
-	// var subjects = []string{"CS", "MATH"}
-
-	fmt.Printf("== 3 == fetching classes for %s...\n", subjects)
-
-	// for concurrency when saving to firestore
-	// using worker pool pattern
-	var wg sync.WaitGroup
-
-	// room aggregation
-	// sync.Mutex is used to protect concurrent map writes
-	// what sync.Mutex does is it allows only one goroutine
-	// to access the critical section of code at a time
-	rooms := make(map[string]*types.Room)
-	var roomMu sync.Mutex
-
-	// NOTE: bodyBytes and response goes in system RAM, in go heap.
-	// they exist in RAM only while specific loop iteration is running, and when the
-	// iteration ends, they become eligible for garbage collection.
-	// so even though we are processing many subjects, the memory usage
-	// should not grow unboundedly.
-	// 8000 sections * ~2KB JSON each = ~16MB RAM worst case
-
-	for _, subj := range subjects {
-		// NOTE: banner api is stateful. which means we need to reset the search
-		// every time we change the subject
-		// otherwise it will keep appending to the previous search
-		resetSearch(client, token)
-
-		offset := 0
-		maxSize := 50
-
-		for {
-			fmt.Printf("fetching %s (offset %d)...\n", subj, offset)
-
-			// build URL with dynamic offset
-			apiURL := fmt.Sprintf(
-				"%s/ssb/searchResults/searchResults?txt_subject=%s&txt_term=%s&pageOffset=%d&pageMaxSize=%d",
-				BaseURL, subj, Term, offset, maxSize,
-			)
-
-			// fetch
-			req, _ = http.NewRequest("GET", apiURL, nil)
-
-			// attach the token header
-			setHeaders(req, token)
-
-			resp, err = client.Do(req)
-			if err != nil {
-				log.Fatal(err)
-			}
-			defer resp.Body.Close()
-
-			bodyBytes, _ = io.ReadAll(resp.Body)
-
-			if resp.StatusCode != 200 {
-				log.Fatalf("error %d: %s", resp.StatusCode, string(bodyBytes))
-			}
-
-			fmt.Printf("received %d bytes of JSON.\n", len(bodyBytes))
-			fmt.Println(string(bodyBytes[:1000]))
-
-			// unmarshal into struct
-			var response types.BannerResponse
-			json.Unmarshal(bodyBytes, &response)
-
-			if len(response.Data) == 0 {
-				break
-			}
-
-			// process and save
-			for _, rawSec := range response.Data {
-				// get all meetings for this section
-				meetings := parseBannerMeetings(rawSec)
-
-				roomMu.Lock()
-				for _, meeting := range meetings {
-
-					// filter unknown locations
-					if strings.Contains(meeting.Location, "ON LINE") || strings.Contains(meeting.Location, "Online") ||
-						strings.Contains(meeting.Location, "OFF CAMPUS") ||
-						strings.Contains(meeting.Location, "TBA") {
-						continue
-					}
-
-					roomID := strings.ReplaceAll(meeting.Location, " ", "_")
+	// ctrl-c stops between requests, the checkpoint keeps everything fetched so far
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 
-					if _, exists := rooms[roomID]; !exists {
-						parts := strings.Split(meeting.Location, " ")
-						number := ""
-						building := meeting.Location
-						if len(parts) > 1 {
-							number = parts[len(parts)-1]
-							building = strings.Join(parts[:len(parts)-1], " ")
-						}
-
-						rooms[roomID] = &types.Room{
-							ID:       roomID,
-							Building: building,
-							Number:   number,
-							Schedule: []types.Meeting{},
-						}
-					}
-
-					// add to schedule
-					rooms[roomID].Schedule = append(rooms[roomID].Schedule, meeting)
-				}
-				roomMu.Unlock()
-			}
-
-			// pagination: increment offset
-			offset += maxSize
-			if offset >= response.TotalCount {
-				break
-			}
-
-			// no classes found, skip to next subject
-			if response.TotalCount == 0 {
-				fmt.Printf("   > no classes found for %s. Skipping.\n", subj)
-				break
-			}
-
-			// this is just to be polite
-			// and not get rate limited and ip banned by the server lol
-			delay := 500*time.Millisecond + time.Duration(rand.Intn(1000))*time.Millisecond
-			fmt.Printf("sleeping %dms before next page...\n", delay)
-			time.Sleep(delay)
-		}
-		fmt.Printf("sleeping 5s before next subject\n")
-		time.Sleep(5 * time.Second)
-
-		// NOTE: it is quite low risk that we hit rate limits here
-		// since we are only making a few hundred requests total
-		// and we iterate through subjects sequentially
-		// also, note:
-		// - wg (waitgroup) spawning goroutines only happens for firestore saves.
-		// - sleep (500ms + random) between pagination requests
-		// - sleep 5s between subject changes
-		// - we also mimic a real user with cookiejar and headers so
-		// the traffic look like a legit SPA user looking at classes
-	}
-	// wait for all goroutines to finish
-	wg.Wait()
-
-	// save rooms
-	fmt.Println("== 4 == saving room schedules...")
-	var roomWg sync.WaitGroup
-	// semaphore to limit concurrency
-	sem := make(chan struct{}, 20)
-
-	for _, r := range rooms {
-		roomWg.Add(1)
-		sem <- struct{}{}
-		go func(room *types.Room) {
-			defer roomWg.Done()
-			defer func() { <-sem }()
-
-			if err := firestore.SaveRoom(context.Background(), *room); err != nil {
-				log.Printf("Error saving room %s: %v", room.ID, err)
-			} else {
-				fmt.Printf("   > Saved Room: %s\n", room.ID)
-			}
-		}(r)
-	}
-	roomWg.Wait()
-
-	fmt.Println("== all subjects processed. ==")
-}
-
-// clears the search criteria in the session
-func resetSearch(client *http.Client, token string) {
-	resetURL := fmt.Sprintf("%s/ssb/classSearch/resetDataForm", BaseURL)
-	req, _ := http.NewRequest("POST", resetURL, nil)
-	setHeaders(req, token)
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Printf("Warning: Failed to reset search: %v", err)
+	code := 2
+	if cmd, ok := lookup(name); ok {
+		code = cmd.run(ctx, args)
 	} else {
-		resp.Body.Close()
-	}
-}
-
-// set the headers
-func setHeaders(req *http.Request, token string) {
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-	req.Header.Set("Accept", "application/json, text/javascript, */*; q=0.01")
-	req.Header.Set("X-Requested-With", "XMLHttpRequest")
-
-	if token != "" {
-		req.Header.Set("X-Synchronizer-Token", token)
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		cmdHelp(ctx, nil)
 	}
-}
 
-// parsing time: 1330 -> 13 + 30
-// return 0 if nil or invalid
-func parseTimeStr(t *string) int {
-	if t == nil {
-		return 0
-	}
-	val := *t
-	if len(val) < 4 {
-		return 0
-	}
-	// "1330" -> 13, 30
-	hh, _ := strconv.Atoi(val[:2])
-	mm, _ := strconv.Atoi(val[2:])
-	return (hh * 60) + mm
+	stop()
+	firestore.Close()
+	os.Exit(code)
 }
 
-// safely dereference string pointer
-func getStr(s *string) string {
-	if s == nil {
-		return ""
-	}
-	return *s
-}
-
-// returns a list of meetings with the course info
-func parseBannerMeetings(raw types.BannerSection) []types.Meeting {
-	var meetings []types.Meeting
-
-	// extract info
-	profName := "Unknown"
-	if len(raw.Faculty) > 0 {
-		profName = raw.Faculty[0].DisplayName
-	}
-
-	info := types.MeetingInfo{
-		ID:        raw.CRN,
-		CourseID:  raw.Subject + raw.CourseNumber,
-		Section:   raw.SequenceNumber,
-		Professor: profName,
-	}
-
-	for _, mf := range raw.MeetingsFaculty {
-		mt := mf.MeetingTime
-
-		startMin := parseTimeStr(mt.BeginTime)
-		endMin := parseTimeStr(mt.EndTime)
-
-		if startMin == 0 || endMin == 0 {
-			continue
-		}
-
-		bldg := getStr(mt.Building)
-		room := getStr(mt.Room)
-		location := fmt.Sprintf("%s %s", bldg, room)
-		if bldg == "" || room == "" {
-			location = "TBA"
-		}
-
-		daysMap := map[int]bool{
-			0: mt.Sunday, 1: mt.Monday, 2: mt.Tuesday,
-			3: mt.Wednesday, 4: mt.Thursday, 5: mt.Friday, 6: mt.Saturday,
-		}
-
-		for dayCode, isActive := range daysMap {
-			if isActive {
-				meetings = append(meetings, types.Meeting{
-					Day:       dayCode,
-					StartTime: startMin,
-					EndTime:   endMin,
-					Location:  location,
-					Label:     []types.MeetingInfo{info},
-				})
-			}
+func lookup(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
 		}
 	}
-	return meetings
+	return command{}, false
 }
 
-// fetch all subjects from banner
-func GetSubjects(client *http.Client, token string) ([]string, error) {
-	fmt.Println("== 0 == fetching subject list...")
-
-	// fetch all subjects (max=500 should cover it)
-	apiURL := fmt.Sprintf(
-		"%s/ssb/classSearch/get_subject?searchTerm=&term=%s&offset=1&max=500",
-		BaseURL, Term,
-	)
-
-	req, _ := http.NewRequest("GET", apiURL, nil)
-	setHeaders(req, token)
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
+func cmdHelp(ctx context.Context, args []string) int {
+	fmt.Fprintln(os.Stderr, "usage: scraper <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %-12s %s\n", cmd.name, cmd.args, cmd.about)
 	}
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "SOURCE is a dataset JSON or NDJSON file (- for stdin), a snapshot version, or live")
+	fmt.Fprintln(os.Stderr, "run `scraper <command> -h` for a command's flags")
+	return 0
+}
 
-	var subjects []types.BannerSubject
-	if err := json.Unmarshal(body, &subjects); err != nil {
-		return nil, err
+// connects to firestore for commands that need it
+// NOTE: this is not an API endpoint, so we init firebase here
+func initStore() bool {
+	if firestore.Client != nil {
+		return true
 	}
-
-	var codes []string
-	for _, s := range subjects {
-		codes = append(codes, s.Code)
+	if err := firestore.Init(); err != nil {
+		slog.Error("failed to initialize Firestore", "err", err)
+		return false
 	}
-
-	fmt.Printf("   > found %d active subjects.\n", len(codes))
-	return codes, nil
+	return true
 }
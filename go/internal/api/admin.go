@@ -0,0 +1,198 @@
+package api
+
+// admin routes, behind auth.RequireAdmin
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/audit"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/auth"
+	db "github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/scraper"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/store"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// where the whole dataset is exported from and imported into
+var DatasetStore store.Datasets = db.Store{}
+
+// reading or writing every room, section and instructor takes a while
+const datasetTimeout = 2 * time.Minute
+
+type AuditResponse struct {
+	Entries    []types.AuditEntry `json:"entries"`     // newest first
+	NextCursor string             `json:"next_cursor"` // empty on the last page
+}
+
+type DatasetImportResponse struct {
+	Imported bool            `json:"imported"`
+	Report   *scraper.Report `json:"report"`
+}
+
+// the dataset being served as NDJSON, see store.WriteNDJSON for the format
+// GET /api/admin/dataset
+func GetDataset(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), datasetTimeout)
+	defer cancel()
+
+	ds, err := DatasetStore.ExportDataset(ctx)
+	if err != nil {
+		slog.ErrorContext(c, "exporting dataset", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export dataset"})
+		return
+	}
+
+	name := "ghost-dataset.ndjson"
+	if ds.Term != "" {
+		name = "ghost-" + ds.Term + ".ndjson"
+	}
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+	c.Status(http.StatusOK)
+	if err := store.WriteNDJSON(c.Writer, ds, time.Now()); err != nil {
+		// headers are out already, all we can do is cut the body short
+		slog.WarnContext(c, "writing dataset export", "err", err)
+	}
+}
+
+// replaces the dataset with an NDJSON (or JSON) export
+// validated against the current dataset like a scrape, 422 with the report if it fails
+// 409 while a scrape or another import holds the scrape lock
+// POST /api/admin/dataset?force=true
+//   - force imports even if validation fails
+func PostDataset(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), datasetTimeout)
+	defer cancel()
+
+	ds, err := store.ReadDataset(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// held from reading the current data to the import, so a scrape can't publish in between
+	err = scraper.Locked(ctx, scraper.ImportLockID(), func(ctx context.Context) error {
+		current, err := DatasetStore.ExportDataset(ctx)
+		if err != nil {
+			slog.ErrorContext(c, "reading current dataset", "err", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read the current dataset"})
+			return nil
+		}
+		var prev *scraper.Summary
+		if len(current.Rooms) > 0 {
+			s := scraper.Summarize(current.Rooms)
+			prev = &s
+		}
+		// an export doesn't know which subjects came back empty, that check is skipped
+		rep := scraper.Validate(ds, prev, 0, nil, scraper.DefaultThresholds)
+		if !rep.Passed && c.Query("force") != "true" {
+			c.JSON(http.StatusUnprocessableEntity, DatasetImportResponse{Report: rep})
+			return nil
+		}
+
+		if err := DatasetStore.ImportDataset(ctx, ds); err != nil {
+			slog.ErrorContext(c, "importing dataset", "err", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to import dataset"})
+			return nil
+		}
+		slog.InfoContext(c, "dataset imported", "admin", auth.Admin(c), "term", ds.Term,
+			"rooms", len(ds.Rooms), "sections", len(ds.Sections), "instructors", len(ds.Instructors), "forced", !rep.Passed)
+		audit.Record(ctx, audit.Published(auth.Admin(c), "import "+ds.Term, current.Rooms, ds.Rooms)...)
+		c.JSON(http.StatusOK, DatasetImportResponse{Imported: true, Report: rep})
+		return nil
+	})
+	if errors.Is(err, scraper.ErrRunning) {
+		c.JSON(http.StatusConflict, gin.H{"error": "a scrape or import is running, try again when it's done"})
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(c, "taking the scrape lock", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to import dataset"})
+	}
+}
+
+// scrape schedule, whether one is running and the newest runs
+// GET /api/admin/scrapes?limit=20
+//   - limit is 1 to 100 (default 20)
+func GetScrapes(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	limit := 20
+	if l := c.Query("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 || n > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+			return
+		}
+		limit = n
+	}
+
+	st, err := scraper.GetStatus(ctx, limit)
+	if err != nil {
+		slog.ErrorContext(c, "reading scrape status", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read scrape status"})
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// the audit log, newest first
+// GET /api/admin/audit?room=HORIZN_2014&building=HORIZN&actor=token&action=room.removed&ref=...&since=...&until=...&limit=50&cursor=...
+//   - since and until are RFC 3339, since is inclusive and until exclusive
+//   - limit is 1 to 200 (default 50), cursor is the next_cursor of the previous page
+func GetAudit(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	q := db.AuditQuery{
+		RoomID:   c.Query("room"),
+		Building: strings.ToUpper(c.Query("building")),
+		Actor:    c.Query("actor"),
+		Action:   c.Query("action"),
+		Ref:      c.Query("ref"),
+		Limit:    50,
+		Cursor:   c.Query("cursor"),
+	}
+	if l := c.Query("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 || n > 200 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+			return
+		}
+		q.Limit = n
+	}
+	for _, p := range []struct {
+		name string
+		to   *time.Time
+	}{{"since", &q.Since}, {"until", &q.Until}} {
+		if s := c.Query(p.name); s != "" {
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": p.name + " must be an RFC 3339 time"})
+				return
+			}
+			*p.to = t
+		}
+	}
+
+	entries, next, err := db.ListAudit(ctx, q)
+	if errors.Is(err, store.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(c, "reading audit log", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read the audit log"})
+		return
+	}
+	c.JSON(http.StatusOK, AuditResponse{Entries: entries, NextCursor: next})
+}
@@ -2,51 +2,122 @@ package api
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
-	"cloud.google.com/go/firestore"
 	"github.com/gin-gonic/gin"
-	"google.golang.org/api/iterator"
 
+	"github.com/google-dev-groups-gmu/ghost/go/internal/availability"
 	db "github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/store"
 	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
 )
 
-// returns static lat/long data for the map
+// Cache-Control values, scraped data only changes when a scrape publishes
+// but reports, holds, overrides and free/busy state can change any minute
+const (
+	cacheLive = "no-cache"
+	// scraped fields only, an admin hiding a building still shows within a minute
+	cacheScraped = "public, max-age=60"
+)
+
+// returns static lat/long data for the map, minus buildings hidden by an admin
 // GET /api/buildings
 func GetBuildings(c *gin.Context) {
-	c.JSON(http.StatusOK, types.Buildings)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	overrides := activeOverrides(ctx, c, time.Now())
+	buildings := make(map[string]types.BuildingInfo, len(types.Buildings))
+	for code, b := range types.Buildings {
+		if !overrides.Hidden(code) {
+			buildings[code] = b
+		}
+	}
+	c.JSON(http.StatusOK, buildings)
+}
+
+// where room listings are read from
+var RoomStore store.Rooms = db.Store{}
+
+// fields a room listing can be narrowed to with ?fields=
+// reports, holds, overrides and availability are computed, the rest map to the stored fields in store.RoomFields
+var roomFields = map[string]string{
+	"id":           "ID",
+	"building":     "Building",
+	"number":       "Number",
+	"capacity":     "Capacity",
+	"schedule":     "Schedule",
+	"reports":      "Reports",
+	"holds":        "Holds",
+	"overrides":    "Overrides",
+	"availability": "Availability",
 }
 
-// returns the full list of rooms and their schedules
-// GET /api/rooms?building=HORIZN
+// fields that aren't stored on the room doc
+var computedFields = []string{"reports", "holds", "overrides", "availability"}
+
+// the names ?fields= accepts, sorted, for the OpenAPI spec
+func RoomFieldNames() []string {
+	return slices.Sorted(maps.Keys(roomFields))
+}
+
+// returns rooms and their schedules, every room in one response unless limit is set
+// GET /api/rooms?building=HORIZN&sort=-free_duration&fields=id,number&limit=50&cursor=...
+//   - sort is id (default), number, capacity or free_duration, prefix with - for descending
+//   - fields is a comma separated subset of id, building, number, capacity, schedule, reports, holds, overrides, availability
+//   - availability is free/busy right now from the whole week's schedule and the overrides, day and time only filter schedule
+//   - rooms in buildings hidden by an admin are left out, so a page can come back short
+//   - the cursor for the next page is sent back in the X-Next-Cursor header, absent on the last page
 func GetRooms(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	client := db.Client
-	if client == nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "database not initialized"})
+	q, fields, err := parseRoomQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// filter by building via query param ?building=HORIZN
-	buildingFilter := c.Query("building")
 	dayFilterStr := c.Query("day")
 	timeFilterStr := c.Query("time")
 
-	var iter *firestore.DocumentIterator
-	if buildingFilter != "" {
-		iter = client.Collection("rooms").Where("building", "==", buildingFilter).Documents(ctx)
-	} else {
-		iter = client.Collection("rooms").Documents(ctx)
+	page, err := RoomStore.ListRooms(ctx, q)
+	if errors.Is(err, store.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
+		return
 	}
-	defer iter.Stop()
 
-	var rooms []types.Room
+	// crowd-sourced reports are best effort, rooms still render without them
+	now := time.Now()
+	var reports map[string][]types.Report
+	if wants(fields, "reports") {
+		reports, err = db.GetActiveReports(ctx, now)
+		if err != nil {
+			slog.WarnContext(c, "firestore error reading reports", "err", err)
+		}
+	}
+	var holds map[string][]types.Hold
+	if wants(fields, "holds") {
+		holds, err = db.GetActiveHolds(ctx, now)
+		if err != nil {
+			slog.WarnContext(c, "firestore error reading holds", "err", err)
+		}
+	}
+
+	overrides := activeOverrides(ctx, c, now)
 
 	var filterDay int = -1
 	if dayFilterStr != "" {
@@ -62,46 +133,112 @@ func GetRooms(c *gin.Context) {
 		}
 	}
 
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
+	rooms := page.Rooms
+	for i := range rooms {
+		// before the day filter, a room's next class may be on another day
+		if wants(fields, "availability") {
+			rooms[i].Availability = availability.Available(rooms[i], now, overrides)
 		}
-		if err != nil {
-			log.Printf("firestore error: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
-			return
+		if filterDay != -1 {
+			rooms[i].Schedule = filterSchedule(rooms[i].Schedule, filterDay, filterTime)
 		}
+		rooms[i].Reports = availability.SummarizeReports(reports[rooms[i].ID], now)
+		rooms[i].Holds = holds[rooms[i].ID]
+	}
+	rooms = overrides.Apply(rooms)
 
-		var room types.Room
-		if err := doc.DataTo(&room); err != nil {
-			continue
+	if page.NextCursor != "" {
+		c.Header("X-Next-Cursor", page.NextCursor)
+	}
+
+	if slices.ContainsFunc(computedFields, func(f string) bool { return wants(fields, f) }) {
+		c.Header("Cache-Control", cacheLive)
+	} else {
+		c.Header("Cache-Control", cacheScraped)
+	}
+	if len(fields) == 0 {
+		c.JSON(http.StatusOK, rooms)
+		return
+	}
+	c.JSON(http.StatusOK, projectRooms(rooms, fields))
+}
+
+// reads building, sort, fields, limit and cursor into a store query
+// also returns the requested fields (nil = all) for projectRooms
+func parseRoomQuery(c *gin.Context) (store.RoomQuery, []string, error) {
+	q := store.RoomQuery{
+		Building: c.Query("building"),
+		Cursor:   c.Query("cursor"),
+		Sort:     strings.TrimPrefix(c.Query("sort"), "-"),
+		Desc:     strings.HasPrefix(c.Query("sort"), "-"),
+	}
+
+	if s := c.Query("limit"); s != "" {
+		l, err := strconv.Atoi(s)
+		if err != nil || l < 1 || l > maxPageLimit {
+			return q, nil, fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
 		}
+		q.Limit = l
+	}
 
-		if filterDay != -1 {
-			var todaysSchedule []types.Meeting
-
-			for _, item := range room.Schedule {
-				if item.Day == filterDay {
-					// filter only the classes that are ongoing at the specified time
-					if filterTime != -1 {
-						if item.StartTime <= filterTime && item.EndTime >= filterTime {
-							todaysSchedule = append(todaysSchedule, item)
-						}
-					} else {
-						todaysSchedule = append(todaysSchedule, item)
-					}
-				}
+	var fields []string
+	if s := c.Query("fields"); s != "" {
+		for _, f := range strings.Split(s, ",") {
+			f = strings.ToLower(strings.TrimSpace(f))
+			if _, ok := roomFields[f]; !ok {
+				return q, nil, fmt.Errorf("unknown field %q", f)
+			}
+			fields = append(fields, f)
+			if !slices.Contains(computedFields, f) {
+				q.Fields = append(q.Fields, f)
 			}
-			room.Schedule = todaysSchedule
 		}
+		// rooms always need an id to attach reports, holds and overrides to,
+		// and a building to leave out the hidden ones
+		need := []string{"id", "building"}
+		if slices.Contains(fields, "availability") {
+			need = append(need, "schedule")
+		}
+		for _, f := range need {
+			if !slices.Contains(q.Fields, f) {
+				q.Fields = append(q.Fields, f)
+			}
+		}
+	}
 
-		rooms = append(rooms, room)
+	if err := q.Validate(); err != nil {
+		return q, nil, err
 	}
+	return q, fields, nil
+}
 
-	// cache for 60min lets save costs
-	c.Header("Cache-Control", "public, max-age=3600")
-	c.JSON(http.StatusOK, rooms)
+// true if field was requested, everything is when fields is empty
+func wants(fields []string, field string) bool {
+	return len(fields) == 0 || slices.Contains(fields, field)
+}
+
+// keeps only the requested fields of each room, keyed like the full v1 response
+func projectRooms(rooms []types.Room, fields []string) []map[string]any {
+	list := make([]map[string]any, len(rooms))
+	for i, r := range rooms {
+		full := map[string]any{
+			"ID":           r.ID,
+			"Building":     r.Building,
+			"Number":       r.Number,
+			"Capacity":     r.Capacity,
+			"Schedule":     r.Schedule,
+			"Reports":      r.Reports,
+			"Holds":        r.Holds,
+			"Overrides":    r.Overrides,
+			"Availability": r.Availability,
+		}
+		m := make(map[string]any, len(fields))
+		for _, f := range fields {
+			m[roomFields[f]] = full[roomFields[f]]
+		}
+		list[i] = m
+	}
+	return list
 }
 
 func GetSpecificRoom(c *gin.Context) {
@@ -116,17 +253,50 @@ func GetSpecificRoom(c *gin.Context) {
 
 	// filter by room via query param ?room=HORIZN_2014
 	roomFilter := c.Query("room")
-	doc, err := client.Collection("rooms").Doc(roomFilter).Get(ctx)
+	room, err := db.GetRoom(ctx, roomFilter)
 	if err != nil {
-		log.Printf("firestore error: %v", err)
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "room not found"})
+			return
+		}
+		slog.ErrorContext(c, "firestore error", "err", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
 		return
 	}
 
-	var room types.Room
-	if err := doc.DataTo(&room); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "error parsing data"})
+	now := time.Now()
+	if reports, err := db.GetActiveReports(ctx, now); err == nil {
+		room.Reports = availability.SummarizeReports(reports[room.ID], now)
+	}
+	if holds, err := db.GetActiveHolds(ctx, now); err == nil {
+		room.Holds = holds[room.ID]
+	}
+	overrides := activeOverrides(ctx, c, now)
+	if overrides.Hidden(room.Building) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "room not found"})
 		return
 	}
+	room.Overrides = overrides.Room(room.ID)
+	room.Availability = availability.Available(*room, now, overrides)
+	c.Header("Cache-Control", cacheLive)
 	c.JSON(http.StatusOK, room)
 }
+
+// keeps the meetings on day, and if t is not -1 only the ones ongoing at t (minutes since midnight)
+func filterSchedule(schedule []types.Meeting, day, t int) []types.Meeting {
+	var todaysSchedule []types.Meeting
+
+	for _, item := range schedule {
+		if item.Day == day {
+			// filter only the classes that are ongoing at the specified time
+			if t != -1 {
+				if item.StartTime <= t && item.EndTime >= t {
+					todaysSchedule = append(todaysSchedule, item)
+				}
+			} else {
+				todaysSchedule = append(todaysSchedule, item)
+			}
+		}
+	}
+	return todaysSchedule
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/markbates/goth/gothic"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/auth"
+	db "github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// redirects to the google consent screen
+// GET /auth/google
+func BeginLogin(c *gin.Context) {
+	if !auth.OAuthEnabled() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "sign-in is not configured"})
+		return
+	}
+	req := gothic.GetContextWithProvider(c.Request, auth.Provider)
+	gothic.BeginAuthHandler(c.Writer, req)
+}
+
+// finishes the OAuth dance, creates the user and session, then sends the browser back to the frontend
+// GET /auth/google/callback
+func LoginCallback(c *gin.Context) {
+	if !auth.OAuthEnabled() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "sign-in is not configured"})
+		return
+	}
+
+	req := gothic.GetContextWithProvider(c.Request, auth.Provider)
+	gUser, err := gothic.CompleteUserAuth(c.Writer, req)
+	if err != nil {
+		slog.WarnContext(c, "oauth error", "err", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "sign-in failed"})
+		return
+	}
+
+	if err := auth.VerifyDomain(gUser); err != nil {
+		if errors.Is(err, auth.ErrDomainNotAllowed) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "please sign in with your @gmu.edu account"})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "sign-in failed"})
+		return
+	}
+
+	now := time.Now().UTC()
+	user := types.User{
+		ID:          gUser.UserID,
+		Email:       gUser.Email,
+		Name:        gUser.Name,
+		AvatarURL:   gUser.AvatarURL,
+		CreatedAt:   now,
+		LastLoginAt: now,
+	}
+	if err := db.SaveUser(c.Request.Context(), &user); err != nil {
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error saving user"})
+		return
+	}
+
+	if err := auth.StartSession(c, user.ID); err != nil {
+		slog.ErrorContext(c, "session error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error creating session"})
+		return
+	}
+
+	// the gothic cookie was only needed for the round trip
+	gothic.Logout(c.Writer, c.Request)
+	c.Redirect(http.StatusFound, auth.RedirectURL())
+}
+
+// ends the current session
+// POST /auth/logout
+func Logout(c *gin.Context) {
+	if err := auth.EndSession(c); err != nil {
+		slog.ErrorContext(c, "session error", "err", err)
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// returns the signed in user
+// GET /api/me
+func GetMe(c *gin.Context) {
+	c.JSON(http.StatusOK, auth.CurrentUser(c))
+}
@@ -0,0 +1,254 @@
+package api
+
+// sign-in against an OIDC provider running in httptest, the browser is an http.Client with a
+// cookie jar that follows every redirect until it lands on the frontend
+// rejections never reach firestore, the signed in path needs the emulator:
+//
+//	FIRESTORE_EMULATOR_HOST=localhost:8081 go test ./internal/api/ -run Login
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/gin-gonic/gin"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/auth"
+	db "github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+const (
+	testClientID = "ghost-test"
+	testFrontend = "http://frontend.test"
+)
+
+// an OIDC provider that signs everyone in as claims
+// state replaces the one the app sent when it isn't empty, like a forged callback
+func fakeOIDC(t *testing.T, claims map[string]any, state string) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{
+				"issuer":                 srv.URL,
+				"authorization_endpoint": srv.URL + "/authorize",
+				"token_endpoint":         srv.URL + "/token",
+				"userinfo_endpoint":      srv.URL + "/userinfo",
+			})
+		case "/authorize":
+			q := r.URL.Query()
+			if q.Get("client_id") != testClientID || q.Get("response_type") != "code" || q.Get("hd") != "gmu.edu" ||
+				!strings.Contains(q.Get("scope"), "openid") {
+				t.Errorf("authorize request %s", r.URL.RawQuery)
+			}
+			if state == "" {
+				state = q.Get("state")
+			}
+			callback := q.Get("redirect_uri") + "?" + url.Values{"code": {"good-code"}, "state": {state}}.Encode()
+			http.Redirect(w, r, callback, http.StatusFound)
+		case "/token":
+			id, _, _ := r.BasicAuth()
+			if r.FormValue("code") != "good-code" || (id != testClientID && r.FormValue("client_id") != testClientID) {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error": "invalid_grant"}`)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{
+				"access_token": "good-token",
+				"token_type":   "Bearer",
+				"expires_in":   3600,
+				"id_token":     idToken(claims),
+			})
+		case "/userinfo":
+			if r.Header.Get("Authorization") != "Bearer good-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(claims)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// goth takes the id_token straight from the token endpoint and only decodes it, the signature isn't checked
+func idToken(claims map[string]any) string {
+	enc := base64.RawURLEncoding
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	return enc.EncodeToString(header) + "." + enc.EncodeToString(payload) + "." + enc.EncodeToString([]byte("signature"))
+}
+
+// a gmu.edu student, issued by idp
+func student(idp string) map[string]any {
+	return map[string]any{
+		"iss":            idp,
+		"aud":            testClientID,
+		"sub":            "104958372615",
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"email":          "gpatriot@gmu.edu",
+		"email_verified": true,
+		"hd":             "gmu.edu",
+		"name":           "George Patriot",
+	}
+}
+
+// the app with sign-in pointed at the provider at discovery, and a browser for it
+func loginApp(t *testing.T, discovery string) (*httptest.Server, *http.Client) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/auth/google", BeginLogin)
+	r.GET("/auth/google/callback", LoginCallback)
+	r.GET("/api/me", auth.RequireUser(), GetMe)
+	app := httptest.NewServer(r)
+	t.Cleanup(app.Close)
+
+	t.Setenv("GOOGLE_CLIENT_ID", testClientID)
+	t.Setenv("GOOGLE_CLIENT_SECRET", "secret")
+	t.Setenv("SESSION_SECRET", "test-session-secret")
+	t.Setenv("OIDC_DISCOVERY_URL", discovery+"/.well-known/openid-configuration")
+	t.Setenv("OAUTH_CALLBACK_URL", app.URL+"/auth/google/callback")
+	if err := auth.InitOAuth(testFrontend); err != nil {
+		t.Fatal(err)
+	}
+
+	jar, _ := cookiejar.New(nil)
+	browser := &http.Client{Jar: jar, CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if req.URL.String() == testFrontend {
+			return http.ErrUseLastResponse
+		}
+		return nil
+	}}
+	return app, browser
+}
+
+// goes through /auth/google, the provider and the callback, returns the callback's response
+func signIn(t *testing.T, app *httptest.Server, browser *http.Client) *http.Response {
+	t.Helper()
+	res, err := browser.Get(app.URL + "/auth/google")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	return res
+}
+
+func sessionCookie(browser *http.Client, app *httptest.Server) *http.Cookie {
+	u, _ := url.Parse(app.URL)
+	for _, c := range browser.Jar.Cookies(u) {
+		if c.Name == auth.SessionCookie {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestLoginRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(claims map[string]any)
+		state  string
+		status int
+	}{
+		{"outside the domain", func(c map[string]any) { c["hd"], c["email"] = "gmail.com", "gpatriot@gmail.com" }, "", http.StatusForbidden},
+		{"no hosted domain", func(c map[string]any) { delete(c, "hd") }, "", http.StatusForbidden},
+		{"gmu.edu hd with another email", func(c map[string]any) { c["email"] = "gpatriot@gmail.com" }, "", http.StatusForbidden},
+		{"unverified email", func(c map[string]any) { c["email_verified"] = false }, "", http.StatusForbidden},
+		{"expired id token", func(c map[string]any) { c["exp"] = time.Now().Add(-time.Hour).Unix() }, "", http.StatusUnauthorized},
+		{"another client's id token", func(c map[string]any) { c["aud"] = "someone-else" }, "", http.StatusUnauthorized},
+		{"another issuer", func(c map[string]any) { c["iss"] = "https://evil.test" }, "", http.StatusUnauthorized},
+		{"forged state", func(map[string]any) {}, "forged", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := map[string]any{}
+			idp := fakeOIDC(t, claims, tt.state)
+			for k, v := range student(idp.URL) {
+				claims[k] = v
+			}
+			tt.change(claims)
+			app, browser := loginApp(t, idp.URL)
+
+			res := signIn(t, app, browser)
+			if res.StatusCode != tt.status {
+				t.Errorf("callback answered %d, want %d", res.StatusCode, tt.status)
+			}
+			if c := sessionCookie(browser, app); c != nil {
+				t.Errorf("session cookie set: %v", c)
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+		t.Skip("FIRESTORE_EMULATOR_HOST is not set")
+	}
+	ctx := context.Background()
+	client, err := firestore.NewClient(ctx, fmt.Sprintf("ghost-test-%d", time.Now().UnixNano()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := db.Client
+	db.Client = client
+	t.Cleanup(func() {
+		client.Close()
+		db.Client = prev
+	})
+
+	claims := map[string]any{}
+	idp := fakeOIDC(t, claims, "")
+	for k, v := range student(idp.URL) {
+		claims[k] = v
+	}
+	app, browser := loginApp(t, idp.URL)
+
+	// the callback sends the browser back to the frontend with the session cookie set
+	res := signIn(t, app, browser)
+	if res.StatusCode != http.StatusFound || res.Header.Get("Location") != testFrontend {
+		t.Fatalf("callback answered %d to %q, want a redirect to the frontend", res.StatusCode, res.Header.Get("Location"))
+	}
+	var set *http.Cookie
+	for _, c := range res.Cookies() {
+		if c.Name == auth.SessionCookie {
+			set = c
+		}
+	}
+	if set == nil || !set.HttpOnly || set.MaxAge != int(auth.SessionTTL.Seconds()) {
+		t.Fatalf("session cookie %v, want an HttpOnly cookie for %s", set, auth.SessionTTL)
+	}
+
+	// the cookie signs the browser in, the user is saved from the id token
+	res, err = browser.Get(app.URL + "/api/me")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	var me types.User
+	if err := json.NewDecoder(res.Body).Decode(&me); err != nil || res.StatusCode != http.StatusOK {
+		t.Fatalf("GET /api/me: %d, %v", res.StatusCode, err)
+	}
+	if me.ID != "104958372615" || me.Email != "gpatriot@gmu.edu" || me.Name != "George Patriot" {
+		t.Errorf("signed in as %+v", me)
+	}
+	if _, err := db.GetUser(ctx, me.ID); errors.Is(err, db.ErrNotFound) {
+		t.Error("the user wasn't saved")
+	}
+}
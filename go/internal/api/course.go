@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	db "github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// "CS 310", "cs310", "MATH" ...
+var courseCodeRe = regexp.MustCompile(`^([A-Z]{2,5})\s*(\d{3}[A-Z]?)?$`)
+
+// caps how many sections a subject or title search reads
+const maxSearchSections = 300
+
+// searches courses by code ("CS 310"), subject ("CS") or title prefix ("data str")
+// GET /api/courses?q=CS 310
+func GetCourses(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	q := strings.TrimSpace(c.Query("q"))
+	if len(q) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q must be at least 2 characters"})
+		return
+	}
+
+	var sections []types.Section
+	var err error
+	if m := courseCodeRe.FindStringSubmatch(strings.ToUpper(q)); m != nil {
+		if m[2] != "" {
+			sections, err = db.GetSectionsByCourse(ctx, m[1]+m[2])
+		} else {
+			sections, err = db.GetSectionsBySubject(ctx, m[1], maxSearchSections)
+		}
+		// "data" matches the subject pattern too, fall back to titles when it isn't a subject
+		if err == nil && len(sections) == 0 && m[2] == "" {
+			sections, err = db.GetSectionsByTitlePrefix(ctx, q, maxSearchSections)
+		}
+	} else {
+		sections, err = db.GetSectionsByTitlePrefix(ctx, q, maxSearchSections)
+	}
+	if err != nil {
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, groupCourses(sections))
+}
+
+// returns a single section with its instructors and meetings
+// GET /api/sections/:crn
+func GetSection(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	section, err := db.GetSection(ctx, c.Param("crn"))
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "section not found"})
+			return
+		}
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, section)
+}
+
+// groups sections by course, sorted by course then section number
+func groupCourses(sections []types.Section) []types.Course {
+	byID := make(map[string]*types.Course)
+	var order []string
+	for _, s := range sections {
+		course, ok := byID[s.CourseID]
+		if !ok {
+			course = &types.Course{
+				CourseID:     s.CourseID,
+				Subject:      s.Subject,
+				CourseNumber: s.CourseNumber,
+				Title:        s.Title,
+			}
+			byID[s.CourseID] = course
+			order = append(order, s.CourseID)
+		}
+		course.Sections = append(course.Sections, s)
+	}
+
+	sort.Strings(order)
+	courses := make([]types.Course, 0, len(order))
+	for _, id := range order {
+		course := byID[id]
+		sort.Slice(course.Sections, func(i, j int) bool {
+			return course.Sections[i].SequenceNumber < course.Sections[j].SequenceNumber
+		})
+		courses = append(courses, *course)
+	}
+	return courses
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/auth"
+)
+
+type DeviceResponse struct {
+	DeviceID string `json:"device_id"`
+	Token    string `json:"token"`
+	// ask for a new token after this, the old one is refused with 401
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// issues a new anonymous device token
+// clients store it and send it back in the X-Device-Token header on writes
+// POST /api/device
+func PostDevice(c *gin.Context) {
+	now := time.Now()
+	id, token, err := auth.NewDeviceToken(now)
+	if err != nil {
+		slog.ErrorContext(c, "device token error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error issuing device token"})
+		return
+	}
+	c.JSON(http.StatusCreated, DeviceResponse{DeviceID: id, Token: token, ExpiresAt: now.Add(auth.DeviceTokenTTL).UTC()})
+}
@@ -0,0 +1,150 @@
+package api
+
+// v2 response types
+// v1 returns types.Room as is (which has no json tags), v2 maps everything to these
+// so the storage structs can change without breaking clients
+
+import (
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/availability"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+type BuildingResponse struct {
+	Code string  `json:"code"`
+	Name string  `json:"name"`
+	Lat  float64 `json:"lat"`
+	Lng  float64 `json:"lng"`
+}
+
+type RoomResponse struct {
+	ID           string               `json:"id"`
+	Building     string               `json:"building"`
+	BuildingName string               `json:"building_name"`
+	Number       string               `json:"number"`
+	Availability AvailabilityResponse `json:"availability"`
+	Schedule     []MeetingResponse    `json:"schedule"`
+	Reports      *types.ReportSummary `json:"reports"`
+	Holds        []HoldResponse       `json:"holds"`
+	Overrides    []OverrideResponse   `json:"overrides"`
+}
+
+type AvailabilityResponse struct {
+	At        time.Time  `json:"at"`
+	Free      bool       `json:"free"`
+	FreeUntil *time.Time `json:"free_until"`
+	BusyUntil *time.Time `json:"busy_until"`
+}
+
+type MeetingResponse struct {
+	Day       int             `json:"day"`
+	StartTime int             `json:"start_time"`
+	EndTime   int             `json:"end_time"`
+	Classes   []ClassResponse `json:"classes"`
+}
+
+type ClassResponse struct {
+	CRN         string   `json:"crn"`
+	CourseID    string   `json:"course_id"`
+	Section     string   `json:"section"`
+	Instructors []string `json:"instructors"`
+}
+
+type HoldResponse struct {
+	Group string    `json:"group"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// a block, closure or event an admin put on the room
+type OverrideResponse struct {
+	Kind  string     `json:"kind"`
+	Title string     `json:"title"`
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end"` // null for a closure with no end date
+}
+
+// list responses wrap the items with pagination info
+type PageResponse[T any] struct {
+	Data       []T        `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// lists too big to count page with an opaque cursor instead of an offset
+type CursorPageResponse[T any] struct {
+	Data       []T     `json:"data"`
+	NextCursor *string `json:"next_cursor"` // nil on the last page, pass it as ?cursor= for the next one
+}
+
+type Pagination struct {
+	Total      int  `json:"total"`
+	Limit      int  `json:"limit"`
+	Offset     int  `json:"offset"`
+	NextOffset *int `json:"next_offset"` // nil on the last page
+}
+
+func newBuildingResponse(code string, b types.BuildingInfo) BuildingResponse {
+	return BuildingResponse{Code: code, Name: b.Name, Lat: b.Lat, Lng: b.Lng}
+}
+
+func newRoomResponse(room types.Room, schedule []types.Meeting, at time.Time, reports []types.Report, holds []types.Hold, overrides *availability.Overrides) RoomResponse {
+	resp := RoomResponse{
+		ID:           room.ID,
+		Building:     room.Building,
+		BuildingName: types.Buildings[room.Building].Name,
+		Number:       room.Number,
+		Availability: newAvailabilityResponse(overrides.State(room, at), at),
+		Schedule:     []MeetingResponse{},
+		Reports:      availability.SummarizeReports(reports, at),
+		Holds:        []HoldResponse{},
+		Overrides:    []OverrideResponse{},
+	}
+	for _, m := range schedule {
+		meeting := MeetingResponse{
+			Day:       m.Day,
+			StartTime: m.StartTime,
+			EndTime:   m.EndTime,
+			Classes:   []ClassResponse{},
+		}
+		for _, l := range m.Label {
+			meeting.Classes = append(meeting.Classes, newClassResponse(l))
+		}
+		resp.Schedule = append(resp.Schedule, meeting)
+	}
+	for _, h := range holds {
+		resp.Holds = append(resp.Holds, HoldResponse{Group: h.Group, Start: h.Start, End: h.End})
+	}
+	for _, o := range overrides.Room(room.ID) {
+		resp.Overrides = append(resp.Overrides, OverrideResponse{Kind: o.Kind, Title: o.Title, Start: o.Start, End: o.End})
+	}
+	return resp
+}
+
+func newClassResponse(info types.MeetingInfo) ClassResponse {
+	class := ClassResponse{
+		CRN:         info.ID,
+		CourseID:    info.CourseID,
+		Section:     info.Section,
+		Instructors: []string{},
+	}
+	for _, inst := range info.Instructors {
+		class.Instructors = append(class.Instructors, inst.Name)
+	}
+	// rooms scraped before instructors were captured only have the professor label
+	if len(class.Instructors) == 0 && info.Professor != "" && info.Professor != "Unknown" {
+		class.Instructors = append(class.Instructors, info.Professor)
+	}
+	return class
+}
+
+func newAvailabilityResponse(state availability.State, at time.Time) AvailabilityResponse {
+	resp := AvailabilityResponse{At: at, Free: state.Free}
+	if !state.FreeUntil.IsZero() {
+		resp.FreeUntil = &state.FreeUntil
+	}
+	if !state.BusyUntil.IsZero() {
+		resp.BusyUntil = &state.BusyUntil
+	}
+	return resp
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// error codes for the v2 error envelope
+// clients should switch on these, the message is for humans and may change
+const (
+	CodeBadRequest  = "bad_request"
+	CodeNotFound    = "not_found"
+	CodeInternal    = "internal"
+	CodeUnavailable = "unavailable"
+	CodeRateLimited = "rate_limited"
+)
+
+// v2 error body
+// {"error": {"code": "not_found", "message": "room not found"}}
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// writes a v2 error and stops the handler chain
+func abortV2(c *gin.Context, status int, code, message string) {
+	c.AbortWithStatusJSON(status, ErrorResponse{Error: ErrorBody{Code: code, Message: message}})
+}
+
+// a middleware.ErrorWriter for the v2 group, the code comes from the status
+func ErrorV2(c *gin.Context, status int, message string) {
+	code := CodeBadRequest
+	switch {
+	case status == http.StatusNotFound:
+		code = CodeNotFound
+	case status == http.StatusTooManyRequests:
+		code = CodeRateLimited
+	case status == http.StatusServiceUnavailable:
+		code = CodeUnavailable
+	case status >= 500:
+		code = CodeInternal
+	}
+	abortV2(c, status, code, message)
+}
+
+// like gin.Recovery, but the 500 has the v2 error envelope
+func RecoveryV2() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, _ any) {
+		abortV2(c, http.StatusInternalServerError, CodeInternal, "internal error")
+	})
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRecoveryV2(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RecoveryV2())
+	r.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	var body ErrorResponse
+	if w.Code != http.StatusInternalServerError || json.Unmarshal(w.Body.Bytes(), &body) != nil || body.Error.Code != CodeInternal {
+		t.Errorf("got %d %s, want a 500 in the v2 envelope", w.Code, w.Body)
+	}
+}
+
+func TestErrorV2Codes(t *testing.T) {
+	tests := []struct {
+		status int
+		code   string
+	}{
+		{http.StatusBadRequest, CodeBadRequest},
+		{http.StatusRequestEntityTooLarge, CodeBadRequest},
+		{http.StatusNotFound, CodeNotFound},
+		{http.StatusTooManyRequests, CodeRateLimited},
+		{http.StatusInternalServerError, CodeInternal},
+		{http.StatusServiceUnavailable, CodeUnavailable},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		ErrorV2(c, tt.status, "nope")
+
+		var body ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != tt.status || body.Error.Code != tt.code || body.Error.Message != "nope" {
+			t.Errorf("%d: got %d %+v, want code %s", tt.status, w.Code, body.Error, tt.code)
+		}
+		if !c.IsAborted() {
+			t.Errorf("%d: chain not aborted", tt.status)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/auth"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/availability"
+	db "github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// keep these small, the status endpoint reads every favorite room at once
+const (
+	maxFavorites     = 50
+	maxSavedSearches = 20
+)
+
+// lists the signed in user's favorite rooms
+// GET /api/me/favorites
+func GetFavorites(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	favs, err := db.GetFavorites(ctx, auth.CurrentUser(c).ID)
+	if err != nil {
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
+		return
+	}
+	c.JSON(http.StatusOK, favs)
+}
+
+// adds a room to the signed in user's favorites
+// PUT /api/me/favorites/:room
+func PutFavorite(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user := auth.CurrentUser(c)
+	roomID := c.Param("room")
+
+	if _, err := db.GetRoom(ctx, roomID); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "room not found"})
+			return
+		}
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
+		return
+	}
+
+	favs, err := db.GetFavorites(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
+		return
+	}
+	for _, f := range favs {
+		if f.RoomID == roomID {
+			c.JSON(http.StatusOK, f)
+			return
+		}
+	}
+	if len(favs) >= maxFavorites {
+		c.JSON(http.StatusConflict, gin.H{"error": "too many favorites"})
+		return
+	}
+
+	fav := types.Favorite{RoomID: roomID, CreatedAt: time.Now().UTC()}
+	if err := db.SaveFavorite(ctx, user.ID, fav); err != nil {
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error saving favorite"})
+		return
+	}
+	c.JSON(http.StatusCreated, fav)
+}
+
+// removes a room from the signed in user's favorites
+// DELETE /api/me/favorites/:room
+func DeleteFavorite(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := db.DeleteFavorite(ctx, auth.CurrentUser(c).ID, c.Param("room")); err != nil {
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error deleting favorite"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// returns whether each favorite room is free right now and until when
+// GET /api/me/favorites/status
+func GetFavoritesStatus(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	favs, err := db.GetFavorites(ctx, auth.CurrentUser(c).ID)
+	if err != nil {
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
+		return
+	}
+
+	ids := make([]string, len(favs))
+	for i, f := range favs {
+		ids[i] = f.RoomID
+	}
+	rooms, err := db.GetRoomsByID(ctx, ids)
+	if err != nil {
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
+		return
+	}
+
+	now := time.Now()
+	reports, err := db.GetActiveReports(ctx, now)
+	if err != nil {
+		slog.WarnContext(c, "firestore error reading reports", "err", err)
+	}
+
+	overrides := activeOverrides(ctx, c, now)
+
+	statuses := make([]types.RoomStatus, 0, len(rooms))
+	for _, room := range overrides.Apply(rooms) {
+		statuses = append(statuses, availability.Status(room, now, reports[room.ID], overrides))
+	}
+	c.JSON(http.StatusOK, statuses)
+}
+
+// lists the signed in user's saved searches
+// GET /api/me/searches
+func GetSavedSearches(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	list, err := db.GetSavedSearches(ctx, auth.CurrentUser(c).ID)
+	if err != nil {
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// saves a named search
+// POST /api/me/searches {"name": "quiet horizon", "building": "HORIZN", "min_duration": 60}
+func PostSavedSearch(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user := auth.CurrentUser(c)
+
+	var search types.SavedSearch
+	if err := c.ShouldBindJSON(&search); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	search.Building = strings.ToUpper(strings.TrimSpace(search.Building))
+	if search.Building != "" {
+		if _, ok := types.Buildings[search.Building]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown building"})
+			return
+		}
+	}
+	search.CreatedAt = time.Now().UTC()
+
+	existing, err := db.GetSavedSearches(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
+		return
+	}
+	if len(existing) >= maxSavedSearches {
+		c.JSON(http.StatusConflict, gin.H{"error": "too many saved searches"})
+		return
+	}
+
+	if err := db.SaveSearch(ctx, user.ID, &search); err != nil {
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error saving search"})
+		return
+	}
+	c.JSON(http.StatusCreated, search)
+}
+
+// deletes a saved search
+// DELETE /api/me/searches/:id
+func DeleteSavedSearch(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := db.DeleteSearch(ctx, auth.CurrentUser(c).ID, c.Param("id")); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "search not found"})
+			return
+		}
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error deleting search"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
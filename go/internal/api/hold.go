@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/auth"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/availability"
+	db "github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// holds can only be placed for the near future, this is not a booking system
+const maxHoldLeadTime = 24 * time.Hour
+
+type HoldRequest struct {
+	Group string     `json:"group" binding:"max=64"`
+	Start *time.Time `json:"start"` // optional, defaults to now
+	End   time.Time  `json:"end" binding:"required"`
+}
+
+// places a soft-hold on a free room
+// POST /api/rooms/:id/holds {"group": "CS 310 study group", "end": "2026-01-20T16:00:00-05:00"}
+func PostHold(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user := auth.CurrentUser(c)
+
+	var body HoldRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	now := time.Now().Truncate(time.Minute)
+	start := now
+	if body.Start != nil {
+		start = body.Start.Truncate(time.Minute)
+	}
+	end := body.End.Truncate(time.Minute)
+
+	switch {
+	case start.Before(now):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start is in the past"})
+		return
+	case !end.After(start):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end must be after start"})
+		return
+	case end.Sub(start) > types.MaxHoldDuration:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("holds can be at most %s", types.MaxHoldDuration)})
+		return
+	case start.Sub(now) > maxHoldLeadTime:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start is too far in the future"})
+		return
+	case !availability.SameDay(start, end):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "holds can't go past midnight"})
+		return
+	}
+
+	room, err := db.GetRoom(ctx, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "room not found"})
+			return
+		}
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
+		return
+	}
+
+	// never let a hold cover a scheduled class
+	if m := availability.Conflict(room.Schedule, start, end); m != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "room has a class during that time",
+			"meeting": m,
+		})
+		return
+	}
+
+	// or a room an admin blocked, closed or booked, unlike listings this can't go without them
+	list, err := db.GetActiveOverrides(ctx, now)
+	if err != nil {
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
+		return
+	}
+	overrides := availability.NewOverrides(list, now)
+	if overrides.Hidden(room.Building) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "room not found"})
+		return
+	}
+	if ov := overrides.Conflict(room.ID, start, end); ov != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":    "room is unavailable during that time",
+			"override": ov,
+		})
+		return
+	}
+
+	hold := types.Hold{
+		RoomID: room.ID,
+		UserID: user.ID,
+		Group:  strings.TrimSpace(body.Group),
+		Start:  start.UTC(),
+		End:    end.UTC(),
+	}
+	if err := db.CreateHold(ctx, &hold, now); err != nil {
+		if errors.Is(err, db.ErrConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "room is already held during that time"})
+			return
+		}
+		if errors.Is(err, db.ErrTooManyHolds) {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("you can have at most %d active holds, release one first", types.MaxActiveHolds)})
+			return
+		}
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error saving hold"})
+		return
+	}
+	c.JSON(http.StatusCreated, hold)
+}
+
+// lists the signed in user's active holds
+// GET /api/me/holds
+func GetMyHolds(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	holds, err := db.GetUserHolds(ctx, auth.CurrentUser(c).ID, time.Now())
+	if err != nil {
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
+		return
+	}
+	c.JSON(http.StatusOK, holds)
+}
+
+// releases a hold early
+// DELETE /api/me/holds/:id
+func DeleteHold(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := db.DeleteHold(ctx, auth.CurrentUser(c).ID, c.Param("id")); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "hold not found"})
+			return
+		}
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error deleting hold"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
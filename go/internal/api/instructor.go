@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	db "github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+type InstructorScheduleResponse struct {
+	Instructor types.Instructor     `json:"instructor"`
+	Schedule   []types.TeachingSlot `json:"schedule"`
+}
+
+// returns where and when an instructor teaches during the week
+// GET /api/instructors/:id/schedule
+func GetInstructorSchedule(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	id := c.Param("id")
+	instructor, err := db.GetInstructor(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "instructor not found"})
+			return
+		}
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
+		return
+	}
+
+	sections, err := db.GetSectionsByInstructor(ctx, id)
+	if err != nil {
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, InstructorScheduleResponse{
+		Instructor: *instructor,
+		Schedule:   teachingSlots(sections, id),
+	})
+}
+
+// flattens the meetings the instructor teaches, sorted by first day then start time
+// co-taught sections can split meetings between instructors, so meetings are matched one by one
+func teachingSlots(sections []types.Section, instructorID string) []types.TeachingSlot {
+	slots := []types.TeachingSlot{}
+	for _, s := range sections {
+		for _, m := range s.Meetings {
+			if !teaches(m.Instructors, instructorID) {
+				continue
+			}
+			slots = append(slots, types.TeachingSlot{
+				CRN:       s.CRN,
+				CourseID:  s.CourseID,
+				Section:   s.SequenceNumber,
+				Title:     s.Title,
+				Days:      m.Days,
+				StartTime: m.StartTime,
+				EndTime:   m.EndTime,
+				Location:  m.Location,
+				RoomID:    m.RoomID,
+			})
+		}
+	}
+
+	firstDay := func(days []int) int {
+		if len(days) == 0 {
+			return 7
+		}
+		return days[0]
+	}
+	sort.SliceStable(slots, func(i, j int) bool {
+		di, dj := firstDay(slots[i].Days), firstDay(slots[j].Days)
+		if di != dj {
+			return di < dj
+		}
+		return slots[i].StartTime < slots[j].StartTime
+	})
+	return slots
+}
+
+func teaches(instructors []types.Instructor, id string) bool {
+	for _, inst := range instructors {
+		if inst.ID == id {
+			return true
+		}
+	}
+	return false
+}
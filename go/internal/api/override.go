@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/audit"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/auth"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/availability"
+	db "github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/stream"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+type OverrideRequest struct {
+	Kind     string     `json:"kind" binding:"required"` // block, closed, event or hide_building
+	RoomID   string     `json:"room_id"`                 // every kind but hide_building
+	Building string     `json:"building"`                // hide_building only
+	Title    string     `json:"title" binding:"max=120"` // required for events
+	Start    *time.Time `json:"start"`                   // required for blocks and events, defaults to now otherwise
+	End      *time.Time `json:"end"`                     // required for blocks and events, optional otherwise
+}
+
+// overrides are best effort like reports and holds, nil (none) if they can't be read
+// c is only for logging, it carries the request ID the timeout ctx doesn't
+func activeOverrides(ctx context.Context, c *gin.Context, now time.Time) *availability.Overrides {
+	list, err := db.GetActiveOverrides(ctx, now)
+	if err != nil {
+		slog.WarnContext(c, "firestore error reading overrides", "err", err)
+		return nil
+	}
+	return availability.NewOverrides(list, now)
+}
+
+// lists overrides, newest first
+// GET /api/admin/overrides?active=true
+//   - active leaves out the ones that have ended
+func GetOverrides(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var list []types.Override
+	var err error
+	if c.Query("active") == "true" {
+		list, err = db.GetActiveOverrides(ctx, time.Now())
+	} else {
+		list, err = db.GetOverrides(ctx)
+	}
+	if err != nil {
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// adds a correction on top of the scraped data
+// POST /api/admin/overrides {"kind": "block", "room_id": "HORIZN_2014", "title": "Renovation", "start": "...", "end": "..."}
+func PostOverride(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var body OverrideRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	now := time.Now()
+	o := types.Override{
+		Kind:      body.Kind,
+		Title:     strings.TrimSpace(body.Title),
+		Start:     now.UTC(),
+		End:       body.End,
+		CreatedBy: auth.Admin(c),
+		CreatedAt: now.UTC(),
+	}
+	if body.Start != nil {
+		o.Start = body.Start.UTC()
+	}
+	if o.End != nil {
+		end := o.End.UTC()
+		o.End = &end
+	}
+
+	dated := body.Kind == types.OverrideBlock || body.Kind == types.OverrideEvent
+	switch {
+	case body.Kind != types.OverrideBlock && body.Kind != types.OverrideClosed &&
+		body.Kind != types.OverrideEvent && body.Kind != types.OverrideHideBuilding:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be one of block, closed, event, hide_building"})
+		return
+	case dated && (body.Start == nil || body.End == nil):
+		c.JSON(http.StatusBadRequest, gin.H{"error": body.Kind + " needs a start and an end"})
+		return
+	case body.Kind != types.OverrideHideBuilding && strings.TrimSpace(body.RoomID) == "":
+		c.JSON(http.StatusBadRequest, gin.H{"error": body.Kind + " needs a room_id"})
+		return
+	case body.Kind == types.OverrideEvent && o.Title == "":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "events need a title"})
+		return
+	case o.End != nil && !o.End.After(o.Start):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end must be after start"})
+		return
+	case o.End != nil && !o.End.After(now):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end is in the past"})
+		return
+	}
+
+	if body.Kind == types.OverrideHideBuilding {
+		o.Building = strings.ToUpper(strings.TrimSpace(body.Building))
+		if _, ok := types.Buildings[o.Building]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown building"})
+			return
+		}
+	} else {
+		room, err := db.GetRoom(ctx, strings.TrimSpace(body.RoomID))
+		if err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "room not found"})
+				return
+			}
+			slog.ErrorContext(c, "firestore error", "err", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
+			return
+		}
+		o.RoomID = room.ID
+		o.Building = room.Building
+	}
+
+	if err := db.CreateOverride(ctx, &o); err != nil {
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error saving override"})
+		return
+	}
+	slog.InfoContext(c, "override added", "admin", o.CreatedBy, "id", o.ID, "kind", o.Kind, "room", o.RoomID, "building", o.Building)
+	audit.Record(ctx, audit.Override(o.CreatedBy, types.AuditOverrideCreated, o))
+	stream.Touch(o.Building)
+	c.JSON(http.StatusCreated, o)
+}
+
+// removes an override, the scraped data shows through again
+// DELETE /api/admin/overrides/:id
+func DeleteOverride(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	o, err := db.DeleteOverride(ctx, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "override not found"})
+			return
+		}
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error deleting override"})
+		return
+	}
+	slog.InfoContext(c, "override deleted", "admin", auth.Admin(c), "id", o.ID, "kind", o.Kind)
+	audit.Record(ctx, audit.Override(auth.Admin(c), types.AuditOverrideDeleted, *o))
+	stream.Touch(o.Building)
+	c.Status(http.StatusNoContent)
+}
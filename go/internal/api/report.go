@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/audit"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/auth"
+	db "github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/stream"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// allowed clock drift for client supplied timestamps
+const reportClockSkew = 2 * time.Minute
+
+type ReportRequest struct {
+	Status    string     `json:"status" binding:"required"`
+	Timestamp *time.Time `json:"timestamp"` // optional, defaults to now
+}
+
+// submits a crowd-sourced status report for a room
+// POST /api/rooms/:id/reports {"status": "locked", "timestamp": "2026-01-20T15:04:05Z"}
+func PostReport(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client := db.Client
+	if client == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database not initialized"})
+		return
+	}
+
+	var body ReportRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if !types.IsValidReportStatus(body.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of occupied, locked, available"})
+		return
+	}
+
+	now := time.Now()
+	reportedAt := now
+	if body.Timestamp != nil {
+		reportedAt = *body.Timestamp
+	}
+	// reject reports from the future or ones that would already be expired
+	if reportedAt.After(now.Add(reportClockSkew)) || reportedAt.Before(now.Add(-types.ReportTTL)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "timestamp is out of range"})
+		return
+	}
+
+	// make sure the room exists so we don't collect reports for typos
+	roomID := c.Param("id")
+	room, err := db.GetRoom(ctx, roomID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "room not found"})
+			return
+		}
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
+		return
+	}
+
+	report := types.Report{
+		RoomID:     roomID,
+		Status:     body.Status,
+		ReportedAt: reportedAt.UTC(),
+		ExpiresAt:  reportedAt.Add(types.ReportTTL).UTC(),
+		DeviceID:   auth.DeviceID(c),
+	}
+	if err := db.SaveReport(ctx, &report); err != nil {
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error saving report"})
+		return
+	}
+
+	audit.Record(ctx, audit.Report(report, room.Building))
+	// live streams of the building get the new status right away
+	stream.Touch(room.Building)
+
+	c.JSON(http.StatusCreated, report)
+}
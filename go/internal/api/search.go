@@ -0,0 +1,51 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/search"
+)
+
+type SearchResponse struct {
+	Query   string          `json:"query"`
+	Results []search.Result `json:"results"`
+}
+
+// searches rooms, buildings, courses and instructors
+// buildings hidden by an admin are left out with their rooms
+// GET /api/search?q=johnson&type=room&limit=20
+func Search(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+
+	typ := c.Query("type")
+	switch typ {
+	case "", search.TypeBuilding, search.TypeRoom, search.TypeCourse, search.TypeInstructor:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be one of building, room, course, instructor"})
+		return
+	}
+
+	limit := 20
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	overrides := activeOverrides(ctx, c, time.Now())
+	c.JSON(http.StatusOK, SearchResponse{
+		Query:   q,
+		Results: search.Current().Search(q, typ, limit, overrides.Hidden),
+	})
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/stream"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// proxies close idle connections, a comment line every so often keeps the stream open
+const streamKeepAlive = 25 * time.Second
+
+// pushes room state changes for a building as server-sent events
+// GET /api/stream?building=HORIZN
+//   - every room is sent once as a "room" event on connect, then again whenever it changes
+//   - "ping" events are sent when nothing happened for a while
+func Stream(c *gin.Context) {
+	building := strings.ToUpper(c.Query("building"))
+	if _, ok := types.Buildings[building]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown building"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	sub, snapshot, err := stream.Subscribe(ctx, building)
+	cancel()
+	if errors.Is(err, stream.ErrTooManySubscribers) {
+		c.Header("Retry-After", "30")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "too many open streams, try again later"})
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
+		return
+	}
+	defer stream.Unsubscribe(sub)
+
+	c.Header("Cache-Control", "no-cache")
+	// nginx and friends buffer responses unless told otherwise
+	c.Header("X-Accel-Buffering", "no")
+
+	for _, status := range snapshot {
+		c.SSEvent("room", status)
+	}
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case status, ok := <-sub.C:
+			if !ok {
+				// dropped for being too slow, the client reconnects and gets a fresh snapshot
+				return false
+			}
+			c.SSEvent("room", status)
+		case <-keepAlive.C:
+			c.SSEvent("ping", time.Now().UTC())
+		}
+		return true
+	})
+}
@@ -0,0 +1,267 @@
+package api
+
+// /api/v2 handlers
+// same data as v1 but with the response types from dto.go, pagination and the error envelope from errors.go
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	db "github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/store"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// GET /api/v2/buildings
+func GetBuildingsV2(c *gin.Context) {
+	limit, offset, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	overrides := activeOverrides(ctx, c, time.Now())
+	codes := make([]string, 0, len(types.Buildings))
+	for code := range types.Buildings {
+		if !overrides.Hidden(code) {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+
+	all := make([]BuildingResponse, len(codes))
+	for i, code := range codes {
+		all[i] = newBuildingResponse(code, types.Buildings[code])
+	}
+
+	c.Header("Cache-Control", cacheScraped)
+	c.JSON(http.StatusOK, paginate(all, limit, offset))
+}
+
+// GET /api/v2/buildings/:code
+func GetBuildingV2(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	code := strings.ToUpper(c.Param("code"))
+	b, ok := types.Buildings[code]
+	if !ok || activeOverrides(ctx, c, time.Now()).Hidden(code) {
+		abortV2(c, http.StatusNotFound, CodeNotFound, "building not found")
+		return
+	}
+	c.Header("Cache-Control", cacheScraped)
+	c.JSON(http.StatusOK, newBuildingResponse(code, b))
+}
+
+// lists rooms sorted by ID, a page at a time from RoomStore
+// GET /api/v2/rooms?building=HORIZN&day=1&time=600&at=2026-01-20T10:00:00-05:00&limit=50&cursor=...
+//   - day/time narrow the schedule like v1 does
+//   - at is when availability is computed for (defaults to now)
+//   - cursor is next_cursor from the previous page, rooms in hidden buildings are left out after
+//     the page is read so a page can be shorter than limit without being the last
+func GetRoomsV2(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	limit, ok := parseLimit(c)
+	if !ok {
+		return
+	}
+	if c.Query("offset") != "" {
+		abortV2(c, http.StatusBadRequest, CodeBadRequest, "rooms are paged with cursor, pass next_cursor from the previous page")
+		return
+	}
+	day, minute, ok := parseDayTime(c)
+	if !ok {
+		return
+	}
+	at, ok := parseAt(c)
+	if !ok {
+		return
+	}
+
+	page, err := RoomStore.ListRooms(ctx, store.RoomQuery{
+		Building: strings.ToUpper(c.Query("building")),
+		Limit:    limit,
+		Cursor:   c.Query("cursor"),
+	})
+	if errors.Is(err, store.ErrInvalidCursor) {
+		abortV2(c, http.StatusBadRequest, CodeBadRequest, "invalid cursor")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(c, "firestore error", "err", err)
+		abortV2(c, http.StatusInternalServerError, CodeInternal, "error reading database")
+		return
+	}
+	overrides := activeOverrides(ctx, c, at)
+	rooms := overrides.Apply(page.Rooms)
+
+	// reports, holds and overrides are best effort, rooms still render without them
+	reports, err := db.GetActiveReports(ctx, at)
+	if err != nil {
+		slog.WarnContext(c, "firestore error reading reports", "err", err)
+	}
+	holds, err := db.GetActiveHolds(ctx, at)
+	if err != nil {
+		slog.WarnContext(c, "firestore error reading holds", "err", err)
+	}
+
+	resp := CursorPageResponse[RoomResponse]{Data: []RoomResponse{}}
+	if page.NextCursor != "" {
+		resp.NextCursor = &page.NextCursor
+	}
+	for _, room := range rooms {
+		schedule := room.Schedule
+		if day != -1 {
+			schedule = filterSchedule(schedule, day, minute)
+		}
+		resp.Data = append(resp.Data, newRoomResponse(room, schedule, at, reports[room.ID], holds[room.ID], overrides))
+	}
+
+	c.Header("Cache-Control", cacheLive)
+	c.JSON(http.StatusOK, resp)
+}
+
+// GET /api/v2/rooms/:id?at=2026-01-20T10:00:00-05:00
+func GetRoomV2(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	at, ok := parseAt(c)
+	if !ok {
+		return
+	}
+
+	room, err := db.GetRoom(ctx, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			abortV2(c, http.StatusNotFound, CodeNotFound, "room not found")
+			return
+		}
+		slog.ErrorContext(c, "firestore error", "err", err)
+		abortV2(c, http.StatusInternalServerError, CodeInternal, "error reading database")
+		return
+	}
+	overrides := activeOverrides(ctx, c, at)
+	if overrides.Hidden(room.Building) {
+		abortV2(c, http.StatusNotFound, CodeNotFound, "room not found")
+		return
+	}
+
+	reports, err := db.GetActiveReports(ctx, at)
+	if err != nil {
+		slog.WarnContext(c, "firestore error reading reports", "err", err)
+	}
+	holds, err := db.GetActiveHolds(ctx, at)
+	if err != nil {
+		slog.WarnContext(c, "firestore error reading holds", "err", err)
+	}
+
+	c.Header("Cache-Control", cacheLive)
+	c.JSON(http.StatusOK, newRoomResponse(*room, room.Schedule, at, reports[room.ID], holds[room.ID], overrides))
+}
+
+// unknown routes under /api/v2 get the error envelope too
+func NotFoundV2(c *gin.Context) {
+	abortV2(c, http.StatusNotFound, CodeNotFound, "route not found")
+}
+
+// reads ?limit and ?offset, writing a 400 and returning false if they are invalid
+func parsePage(c *gin.Context) (int, int, bool) {
+	limit, ok := parseLimit(c)
+	if !ok {
+		return 0, 0, false
+	}
+	offset := 0
+	if s := c.Query("offset"); s != "" {
+		o, err := strconv.Atoi(s)
+		if err != nil || o < 0 {
+			abortV2(c, http.StatusBadRequest, CodeBadRequest, "offset must be a non-negative integer")
+			return 0, 0, false
+		}
+		offset = o
+	}
+	return limit, offset, true
+}
+
+// reads ?limit, writing a 400 and returning false if it's invalid
+func parseLimit(c *gin.Context) (int, bool) {
+	limit := defaultPageLimit
+	if s := c.Query("limit"); s != "" {
+		l, err := strconv.Atoi(s)
+		if err != nil || l < 1 || l > maxPageLimit {
+			abortV2(c, http.StatusBadRequest, CodeBadRequest, "limit must be between 1 and "+strconv.Itoa(maxPageLimit))
+			return 0, false
+		}
+		limit = l
+	}
+	return limit, true
+}
+
+// reads ?day and ?time, -1 when not set
+func parseDayTime(c *gin.Context) (int, int, bool) {
+	day, minute := -1, -1
+	if s := c.Query("day"); s != "" {
+		d, err := strconv.Atoi(s)
+		if err != nil || d < 0 || d > 6 {
+			abortV2(c, http.StatusBadRequest, CodeBadRequest, "day must be between 0 (sunday) and 6")
+			return 0, 0, false
+		}
+		day = d
+	}
+	if s := c.Query("time"); s != "" {
+		t, err := strconv.Atoi(s)
+		if err != nil || t < 0 || t >= 24*60 {
+			abortV2(c, http.StatusBadRequest, CodeBadRequest, "time must be minutes since midnight")
+			return 0, 0, false
+		}
+		minute = t
+	}
+	return day, minute, true
+}
+
+// reads ?at as RFC 3339, defaults to now
+func parseAt(c *gin.Context) (time.Time, bool) {
+	s := c.Query("at")
+	if s == "" {
+		return time.Now(), true
+	}
+	at, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		abortV2(c, http.StatusBadRequest, CodeBadRequest, "at must be an RFC 3339 timestamp")
+		return time.Time{}, false
+	}
+	return at, true
+}
+
+// cuts one page out of items
+func paginate[T any](items []T, limit, offset int) PageResponse[T] {
+	total := len(items)
+	start := min(offset, total)
+	end := min(start+limit, total)
+
+	page := PageResponse[T]{
+		Data:       items[start:end],
+		Pagination: Pagination{Total: total, Limit: limit, Offset: offset},
+	}
+	if end < total {
+		page.Pagination.NextOffset = &end
+	}
+	return page
+}
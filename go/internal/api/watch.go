@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/auth"
+	db "github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/notify"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+const maxWatches = 20
+
+type WatchRequest struct {
+	RoomID      string `json:"room_id" binding:"required"`
+	LeadMinutes int    `json:"lead_minutes" binding:"required,min=1,max=120"`
+	Channel     string `json:"channel" binding:"required"`
+	// webhook URL or push subscription JSON, email always goes to the account address
+	Target string `json:"target"`
+}
+
+// lists the signed in user's room watches
+// GET /api/me/watches
+func GetWatches(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	watches, err := db.GetWatches(ctx, auth.CurrentUser(c).ID)
+	if err != nil {
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
+		return
+	}
+	c.JSON(http.StatusOK, watches)
+}
+
+// asks to be notified before a room frees up
+// POST /api/me/watches {"room_id": "ENGR_1103", "lead_minutes": 5, "channel": "email"}
+func PostWatch(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user := auth.CurrentUser(c)
+
+	var body WatchRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if !notify.Enabled(body.Channel) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "notification channel is not available"})
+		return
+	}
+
+	target := body.Target
+	switch body.Channel {
+	case types.ChannelEmail:
+		target = user.Email
+	case types.ChannelWebhook:
+		if err := notify.CheckWebhookTarget(ctx, target); err != nil {
+			if errors.Is(err, notify.ErrPrivateAddress) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "target must be on the public internet"})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	case types.ChannelPush:
+		if !notify.ValidSubscription(target) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "target must be a push subscription"})
+			return
+		}
+	}
+
+	if _, err := db.GetRoom(ctx, body.RoomID); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "room not found"})
+			return
+		}
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
+		return
+	}
+
+	existing, err := db.GetWatches(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error reading database"})
+		return
+	}
+	if len(existing) >= maxWatches {
+		c.JSON(http.StatusConflict, gin.H{"error": "too many watches"})
+		return
+	}
+
+	watch := types.Watch{
+		UserID:      user.ID,
+		RoomID:      body.RoomID,
+		LeadMinutes: body.LeadMinutes,
+		Channel:     body.Channel,
+		Target:      target,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := db.SaveWatch(ctx, &watch); err != nil {
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error saving watch"})
+		return
+	}
+	c.JSON(http.StatusCreated, watch)
+}
+
+// stops watching a room
+// DELETE /api/me/watches/:id
+func DeleteWatch(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := db.DeleteWatch(ctx, auth.CurrentUser(c).ID, c.Param("id")); err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "watch not found"})
+			return
+		}
+		slog.ErrorContext(c, "firestore error", "err", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "error deleting watch"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,204 @@
+package audit
+
+// builds and records audit log entries: who or what changed which room or building, and how
+// recording is best effort, a change that went through is never failed because its entry couldn't be written
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/user"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// entries are small but a first publish has one per room
+const recordTimeout = time.Minute
+
+// writes the entries, stamping the ones without a time with now
+func Record(ctx context.Context, entries ...types.AuditEntry) {
+	if len(entries) == 0 || firestore.Client == nil {
+		return
+	}
+	now := time.Now().UTC()
+	for i := range entries {
+		if entries[i].At.IsZero() {
+			entries[i].At = now
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+	if err := firestore.AppendAudit(ctx, entries); err != nil {
+		slog.Error("writing audit log", "entries", len(entries), "action", entries[0].Action, "err", err)
+	}
+}
+
+// the scraper CLI run by hand, ex) "cli:jdoe@build-box"
+func CLIActor() string {
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	host, _ := os.Hostname()
+	return "cli:" + name + "@" + host
+}
+
+// a scrape, by what started it, ex) "scraper:schedule"
+func ScraperActor(trigger string) string {
+	if trigger == "" {
+		return "scraper"
+	}
+	return "scraper:" + trigger
+}
+
+// what publishing ref (a snapshot version, or what was imported) changed
+// one entry for the publish, one per added, removed or changed room,
+// and one per building that gained its first or lost its last room
+func Published(actor, ref string, before, after []types.Room) []types.AuditEntry {
+	entries := []types.AuditEntry{{
+		Actor:  actor,
+		Action: types.AuditDatasetPublished,
+		Ref:    ref,
+		Before: map[string]any{"rooms": len(before)},
+		After:  map[string]any{"rooms": len(after)},
+	}}
+	room := func(action string, r types.Room, before, after map[string]any) {
+		entries = append(entries, types.AuditEntry{
+			Actor: actor, Action: action, RoomID: r.ID, Building: r.Building, Ref: ref, Before: before, After: after,
+		})
+	}
+
+	old := make(map[string]types.Room, len(before))
+	buildingsBefore := make(map[string]int)
+	for _, r := range before {
+		old[r.ID] = r
+		buildingsBefore[r.Building]++
+	}
+	buildingsAfter := make(map[string]int)
+	for _, r := range after {
+		buildingsAfter[r.Building]++
+		prev, ok := old[r.ID]
+		if !ok {
+			room(types.AuditRoomAdded, r, nil, roomFields(r))
+			continue
+		}
+		delete(old, r.ID)
+		if b, a := changed(roomFields(prev), roomFields(r)); a != nil {
+			room(types.AuditRoomChanged, r, b, a)
+		}
+	}
+	removed := make([]types.Room, 0, len(old))
+	for _, r := range old {
+		removed = append(removed, r)
+	}
+	sort.Slice(removed, func(i, j int) bool { return removed[i].ID < removed[j].ID })
+	for _, r := range removed {
+		room(types.AuditRoomRemoved, r, roomFields(r), nil)
+	}
+
+	for _, code := range sortedKeys(buildingsBefore, buildingsAfter) {
+		n, m := buildingsBefore[code], buildingsAfter[code]
+		switch {
+		case n == 0 && m > 0:
+			entries = append(entries, types.AuditEntry{Actor: actor, Action: types.AuditBuildingAdded, Building: code, Ref: ref,
+				After: map[string]any{"rooms": m}})
+		case n > 0 && m == 0:
+			entries = append(entries, types.AuditEntry{Actor: actor, Action: types.AuditBuildingRemoved, Building: code, Ref: ref,
+				Before: map[string]any{"rooms": n}})
+		}
+	}
+	return entries
+}
+
+// an override that was created or deleted
+func Override(actor, action string, o types.Override) types.AuditEntry {
+	e := types.AuditEntry{Actor: actor, Action: action, RoomID: o.RoomID, Building: o.Building, Ref: o.ID}
+	if action == types.AuditOverrideDeleted {
+		e.Before = plain(o)
+	} else {
+		e.After = plain(o)
+	}
+	return e
+}
+
+// a crowd-sourced report
+func Report(r types.Report, building string) types.AuditEntry {
+	return types.AuditEntry{
+		Actor:    "device:" + r.DeviceID,
+		Action:   types.AuditReportCreated,
+		RoomID:   r.RoomID,
+		Building: building,
+		Ref:      r.ID,
+		After:    map[string]any{"status": r.Status, "reported_at": r.ReportedAt},
+	}
+}
+
+// the stored fields of a room, with the schedule in a stable order so reordering isn't a change
+func roomFields(r types.Room) map[string]any {
+	schedule := append(make([]types.Meeting, 0, len(r.Schedule)), r.Schedule...)
+	sort.SliceStable(schedule, func(i, j int) bool {
+		a, b := schedule[i], schedule[j]
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		if a.StartTime != b.StartTime {
+			return a.StartTime < b.StartTime
+		}
+		return fmt.Sprint(a.EndTime, a.Label) < fmt.Sprint(b.EndTime, b.Label)
+	})
+	return plain(map[string]any{
+		"building": r.Building,
+		"number":   r.Number,
+		"capacity": r.Capacity,
+		"schedule": schedule,
+	})
+}
+
+// the fields that differ between a and b, nil, nil if none do
+func changed(a, b map[string]any) (map[string]any, map[string]any) {
+	var before, after map[string]any
+	for k, v := range b {
+		if reflect.DeepEqual(a[k], v) {
+			continue
+		}
+		if before == nil {
+			before, after = map[string]any{}, map[string]any{}
+		}
+		before[k], after[k] = a[k], v
+	}
+	return before, after
+}
+
+// v as JSON values (maps, slices, strings, float64s), so entries read back from firestore compare and print the same
+func plain(v any) map[string]any {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil
+	}
+	return m
+}
+
+func sortedKeys(maps ...map[string]int) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
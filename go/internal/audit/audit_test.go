@@ -0,0 +1,104 @@
+package audit
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+func room(id, building string, capacity int, schedule ...types.Meeting) types.Room {
+	return types.Room{ID: id, Building: building, Number: id[len(building)+1:], Capacity: capacity, Schedule: schedule}
+}
+
+var (
+	mon = types.Meeting{Day: 1, StartTime: 9 * 60, EndTime: 10 * 60, Label: []types.MeetingInfo{{ID: "10492", CourseID: "CS110"}}}
+	wed = types.Meeting{Day: 3, StartTime: 9 * 60, EndTime: 10 * 60, Label: []types.MeetingInfo{{ID: "10492", CourseID: "CS110"}}}
+)
+
+// action, room and building of every entry, enough to see what was recorded
+type entry struct{ action, room, building string }
+
+func summarize(entries []types.AuditEntry) []entry {
+	out := make([]entry, len(entries))
+	for i, e := range entries {
+		out[i] = entry{e.Action, e.RoomID, e.Building}
+	}
+	return out
+}
+
+func TestPublished(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after []types.Room
+		want          []entry
+	}{
+		{"nothing changed", []types.Room{room("HORIZN_2014", "HORIZN", 40, mon)}, []types.Room{room("HORIZN_2014", "HORIZN", 40, mon)}, nil},
+		{"schedule reordered", []types.Room{room("HORIZN_2014", "HORIZN", 40, mon, wed)}, []types.Room{room("HORIZN_2014", "HORIZN", 40, wed, mon)}, nil},
+		{"room added", []types.Room{room("HORIZN_2014", "HORIZN", 40)}, []types.Room{room("HORIZN_2014", "HORIZN", 40), room("HORIZN_2016", "HORIZN", 30)},
+			[]entry{{types.AuditRoomAdded, "HORIZN_2016", "HORIZN"}}},
+		{"room removed", []types.Room{room("HORIZN_2014", "HORIZN", 40), room("HORIZN_2016", "HORIZN", 30)}, []types.Room{room("HORIZN_2014", "HORIZN", 40)},
+			[]entry{{types.AuditRoomRemoved, "HORIZN_2016", "HORIZN"}}},
+		{"room changed", []types.Room{room("HORIZN_2014", "HORIZN", 40, mon)}, []types.Room{room("HORIZN_2014", "HORIZN", 40, mon, wed)},
+			[]entry{{types.AuditRoomChanged, "HORIZN_2014", "HORIZN"}}},
+		{"building emptied", []types.Room{room("HORIZN_2014", "HORIZN", 40), room("ENGR_1103", "ENGR", 60), room("ENGR_1107", "ENGR", 20)},
+			[]types.Room{room("HORIZN_2014", "HORIZN", 40)},
+			[]entry{{types.AuditRoomRemoved, "ENGR_1103", "ENGR"}, {types.AuditRoomRemoved, "ENGR_1107", "ENGR"}, {types.AuditBuildingRemoved, "", "ENGR"}}},
+		{"first publish", nil, []types.Room{room("HORIZN_2014", "HORIZN", 40), room("ENGR_1103", "ENGR", 60)},
+			[]entry{{types.AuditRoomAdded, "HORIZN_2014", "HORIZN"}, {types.AuditRoomAdded, "ENGR_1103", "ENGR"},
+				{types.AuditBuildingAdded, "", "ENGR"}, {types.AuditBuildingAdded, "", "HORIZN"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := Published("cli:test", "v42", tt.before, tt.after)
+			if len(entries) == 0 || entries[0].Action != types.AuditDatasetPublished {
+				t.Fatalf("first entry %+v, want the publish", entries)
+			}
+			for _, e := range entries {
+				if e.Actor != "cli:test" || e.Ref != "v42" {
+					t.Errorf("entry %+v, want actor and ref on every entry", e)
+				}
+			}
+			if got := summarize(entries[1:]); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublishedContents(t *testing.T) {
+	before := []types.Room{room("HORIZN_2014", "HORIZN", 40, mon), room("HORIZN_2016", "HORIZN", 30)}
+	after := []types.Room{room("HORIZN_2014", "HORIZN", 45, mon), room("HORIZN_2018", "HORIZN", 20, wed)}
+	entries := Published("cli:test", "v42", before, after)
+	if len(entries) != 4 {
+		t.Fatalf("got %v, want publish, change, add and remove", summarize(entries))
+	}
+
+	publish := entries[0]
+	if publish.Before["rooms"] != 2 || publish.After["rooms"] != 2 {
+		t.Errorf("publish %v -> %v, want 2 rooms on both sides", publish.Before, publish.After)
+	}
+
+	// only the fields that differ, as JSON values
+	changed := entries[1]
+	if want := map[string]any{"capacity": 40.0}; !reflect.DeepEqual(changed.Before, want) {
+		t.Errorf("changed before %v, want %v", changed.Before, want)
+	}
+	if want := map[string]any{"capacity": 45.0}; !reflect.DeepEqual(changed.After, want) {
+		t.Errorf("changed after %v, want %v", changed.After, want)
+	}
+
+	// an added room has only an after, a removed one only a before, both with every field
+	added, removed := entries[2], entries[3]
+	if added.Action != types.AuditRoomAdded || added.Before != nil || added.After["number"] != "2018" || added.After["capacity"] != 20.0 {
+		t.Errorf("added %+v", added)
+	}
+	schedule, _ := added.After["schedule"].([]any)
+	if len(schedule) != 1 || schedule[0].(map[string]any)["day"] != 3.0 {
+		t.Errorf("added schedule %v, want the wednesday meeting", added.After["schedule"])
+	}
+	if removed.Action != types.AuditRoomRemoved || removed.After != nil || removed.Before["number"] != "2016" || removed.Before["building"] != "HORIZN" {
+		t.Errorf("removed %+v", removed)
+	}
+}
@@ -0,0 +1,58 @@
+package auth
+
+// admin access for /api/admin
+// either a signed in user listed in ADMIN_EMAILS, or ADMIN_TOKEN as a bearer token for
+// scripts and cron jobs. with neither set every admin request is refused
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// key the admin's name is stored under in the gin context
+const adminKey = "admin"
+
+// rejects the request with 403 unless it comes from an admin
+// the admin is available to later handlers through Admin
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := os.Getenv("ADMIN_TOKEN"); token != "" {
+			got, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+			if ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+				c.Set(adminKey, "token")
+				c.Next()
+				return
+			}
+		}
+
+		if emails := adminEmails(); len(emails) > 0 {
+			if user, err := loadUser(c); err == nil && emails[strings.ToLower(user.Email)] {
+				c.Set(userKey, user)
+				c.Set(adminKey, user.Email)
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin only"})
+	}
+}
+
+// who made the admin request, the user's email or "token", empty if RequireAdmin did not run
+func Admin(c *gin.Context) string {
+	return c.GetString(adminKey)
+}
+
+// ADMIN_EMAILS, comma separated, ex) jdoe@gmu.edu,asmith@gmu.edu
+func adminEmails() map[string]bool {
+	emails := make(map[string]bool)
+	for _, e := range strings.Split(os.Getenv("ADMIN_EMAILS"), ",") {
+		if e = strings.ToLower(strings.TrimSpace(e)); e != "" {
+			emails[e] = true
+		}
+	}
+	return emails
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequireSameOrigin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	redirectURL = "https://ghost.example.edu/app"
+
+	r := gin.New()
+	r.Use(RequireSameOrigin())
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	r.GET("/x", ok)
+	r.POST("/x", ok)
+
+	tests := []struct {
+		name    string
+		method  string
+		cookie  bool
+		origin  string
+		referer string
+		want    int
+	}{
+		{"read with cookie", http.MethodGet, true, "https://evil.example.com", "", http.StatusNoContent},
+		{"write without cookie", http.MethodPost, false, "https://evil.example.com", "", http.StatusNoContent},
+		{"write from frontend", http.MethodPost, true, "https://ghost.example.edu", "", http.StatusNoContent},
+		{"write from frontend referer", http.MethodPost, true, "", "https://ghost.example.edu/rooms?b=JC", http.StatusNoContent},
+		{"write from another site", http.MethodPost, true, "https://evil.example.com", "", http.StatusForbidden},
+		{"origin wins over referer", http.MethodPost, true, "https://evil.example.com", "https://ghost.example.edu/", http.StatusForbidden},
+		{"another port", http.MethodPost, true, "https://ghost.example.edu:8443", "", http.StatusForbidden},
+		{"http frontend", http.MethodPost, true, "http://ghost.example.edu", "", http.StatusForbidden},
+		{"null origin", http.MethodPost, true, "null", "", http.StatusForbidden},
+		{"no origin or referer", http.MethodPost, true, "", "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/x", nil)
+			if tt.cookie {
+				req.AddCookie(&http.Cookie{Name: SessionCookie, Value: "token"})
+			}
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.referer != "" {
+				req.Header.Set("Referer", tt.referer)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("got %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package auth
+
+// anonymous device tokens
+// the API hands out a random device ID signed with a server secret, clients send it back
+// in the X-Device-Token header on writes. there is no account behind it, it just gives the
+// rate limiter something more stable than an IP to key on (campus wifi NATs a lot of students)
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DeviceHeader = "X-Device-Token"
+
+	// how long a device token is good for, clients ask for a new one when theirs is refused
+	DeviceTokenTTL = 90 * 24 * time.Hour
+
+	// key the verified device ID is stored under in the gin context
+	deviceKey = "device_id"
+
+	// clocks of instances issuing and checking tokens may be a little apart
+	clockSkew = time.Minute
+)
+
+var deviceSecret []byte
+
+var ErrInvalidDeviceToken = errors.New("invalid device token")
+
+// loads the signing secret from DEVICE_TOKEN_SECRET
+// if it is not set we make one up, which means tokens stop working after a restart
+func Init() error {
+	secret := os.Getenv("DEVICE_TOKEN_SECRET")
+	if secret == "" {
+		slog.Warn("DEVICE_TOKEN_SECRET not set, using a random secret (device tokens reset on restart)")
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return err
+		}
+		deviceSecret = b
+		return nil
+	}
+	deviceSecret = []byte(secret)
+	return nil
+}
+
+// creates a new device ID and its signed token
+// token format: <device id>.<issued at unix>.<signature>
+func NewDeviceToken(now time.Time) (string, string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	id := hex.EncodeToString(b)
+	payload := id + "." + strconv.FormatInt(now.Unix(), 10)
+	return id, payload + "." + sign(payload), nil
+}
+
+// checks the signature and age and returns the device ID
+func VerifyDeviceToken(token string, now time.Time) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", ErrInvalidDeviceToken
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(sign(payload)), []byte(parts[2])) {
+		return "", ErrInvalidDeviceToken
+	}
+	issued, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", ErrInvalidDeviceToken
+	}
+	if at := time.Unix(issued, 0); at.After(now.Add(clockSkew)) || now.Sub(at) > DeviceTokenTTL {
+		return "", ErrInvalidDeviceToken
+	}
+	return parts[0], nil
+}
+
+func sign(payload string) string {
+	mac := hmac.New(sha256.New, deviceSecret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// rejects requests without a valid device token
+// the device ID is available to later handlers through DeviceID
+func RequireDevice() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := VerifyDeviceToken(c.GetHeader(DeviceHeader), time.Now())
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid device token"})
+			return
+		}
+		c.Set(deviceKey, id)
+		c.Next()
+	}
+}
+
+// returns the verified device ID for the request, "" if RequireDevice did not run
+func DeviceID(c *gin.Context) string {
+	return c.GetString(deviceKey)
+}
+
+// rate limiter key func that buckets by device token
+func ByDevice(c *gin.Context) string {
+	if id := DeviceID(c); id != "" {
+		return "device:" + id
+	}
+	return ""
+}
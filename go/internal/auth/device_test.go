@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyDeviceToken(t *testing.T) {
+	deviceSecret = []byte("test-secret")
+	now := time.Date(2026, 1, 20, 12, 0, 0, 0, time.UTC)
+	id, token, err := NewDeviceToken(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(token, ".")
+	_, other, _ := NewDeviceToken(now)
+
+	tests := []struct {
+		name  string
+		token string
+		at    time.Time
+		ok    bool
+	}{
+		{"fresh", token, now, true},
+		{"almost expired", token, now.Add(DeviceTokenTTL), true},
+		{"expired", token, now.Add(DeviceTokenTTL + time.Second), false},
+		{"issuer's clock a little ahead", token, now.Add(-30 * time.Second), true},
+		{"issued in the future", token, now.Add(-time.Hour), false},
+		{"empty", "", now, false},
+		{"truncated", token[:len(token)-4], now, false},
+		{"no signature", parts[0] + "." + parts[1], now, false},
+		{"extra part", token + ".x", now, false},
+		{"another id", strings.Repeat("0", len(parts[0])) + "." + parts[1] + "." + parts[2], now, false},
+		{"pushed issued at", parts[0] + "." + "9999999999" + "." + parts[2], now, false},
+		{"another token's signature", parts[0] + "." + parts[1] + "." + strings.Split(other, ".")[2], now, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifyDeviceToken(tt.token, tt.at)
+			if tt.ok && (err != nil || got != id) {
+				t.Errorf("got %q, %v, want %q", got, err, id)
+			}
+			if !tt.ok && err == nil {
+				t.Errorf("accepted as %q", got)
+			}
+		})
+	}
+
+	// a token signed with another secret is refused
+	deviceSecret = []byte("another-secret")
+	if _, err := VerifyDeviceToken(token, now); err == nil {
+		t.Error("accepted a token signed with another secret")
+	}
+}
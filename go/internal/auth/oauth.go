@@ -0,0 +1,118 @@
+package auth
+
+// google sign-in through goth's OpenID Connect provider
+// the provider is configured from a discovery URL, so pointing OIDC_DISCOVERY_URL at a
+// local OIDC stand-in (dex, mock-oauth2-server...) works the same as google in dev
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/gorilla/sessions"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothic"
+	"github.com/markbates/goth/providers/openidConnect"
+)
+
+const (
+	Provider = "google"
+
+	googleDiscoveryURL = "https://accounts.google.com/.well-known/openid-configuration"
+)
+
+var (
+	// only accounts from this google workspace may sign in
+	allowedDomain = "gmu.edu"
+
+	// where the callback sends the browser once the session is set
+	redirectURL string
+
+	oauthEnabled bool
+)
+
+var ErrDomainNotAllowed = errors.New("account is not from an allowed domain")
+
+// registers the OIDC provider with goth
+// sign-in stays disabled if GOOGLE_CLIENT_ID is not set so local dev works without credentials
+func InitOAuth(frontendURL string) error {
+	redirectURL = frontendURL
+
+	clientID := os.Getenv("GOOGLE_CLIENT_ID")
+	if clientID == "" {
+		slog.Warn("GOOGLE_CLIENT_ID not set, sign-in is disabled")
+		return nil
+	}
+
+	sessionSecret := os.Getenv("SESSION_SECRET")
+	if sessionSecret == "" {
+		return errors.New("SESSION_SECRET is required when sign-in is enabled")
+	}
+
+	callbackURL := os.Getenv("OAUTH_CALLBACK_URL")
+	if callbackURL == "" {
+		callbackURL = "http://localhost:5000/auth/google/callback"
+	}
+
+	discoveryURL := os.Getenv("OIDC_DISCOVERY_URL")
+	if discoveryURL == "" {
+		discoveryURL = googleDiscoveryURL
+	}
+
+	if domain := os.Getenv("ALLOWED_DOMAIN"); domain != "" {
+		allowedDomain = domain
+	}
+
+	provider, err := openidConnect.New(clientID, os.Getenv("GOOGLE_CLIENT_SECRET"), callbackURL, discoveryURL, "openid", "email", "profile")
+	if err != nil {
+		return fmt.Errorf("oidc discovery: %w", err)
+	}
+	provider.SetName(Provider)
+	// hd only preselects the account on google's side, the claim is checked again in VerifyDomain
+	provider.SetAuthCodeOptions(map[string]string{"hd": allowedDomain, "prompt": "select_account"})
+	goth.UseProviders(provider)
+
+	// gothic keeps the OAuth state in this cookie during the login round trip only,
+	// the real session is server-side (see session.go)
+	store := sessions.NewCookieStore([]byte(sessionSecret))
+	store.Options.HttpOnly = true
+	store.Options.Secure = secureCookies()
+	store.Options.MaxAge = 10 * 60
+	gothic.Store = store
+
+	oauthEnabled = true
+	slog.Info("sign-in enabled", "domain", allowedDomain)
+	return nil
+}
+
+func OAuthEnabled() bool {
+	return oauthEnabled
+}
+
+func RedirectURL() string {
+	return redirectURL
+}
+
+// makes sure the account belongs to the allowed workspace
+// the hd claim is only present for workspace accounts, so a gmail address with a
+// forwarded gmu.edu email can't get through
+func VerifyDomain(user goth.User) error {
+	hd, _ := user.RawData["hd"].(string)
+	if hd != allowedDomain {
+		return ErrDomainNotAllowed
+	}
+	if !strings.HasSuffix(strings.ToLower(user.Email), "@"+allowedDomain) {
+		return ErrDomainNotAllowed
+	}
+	if verified, ok := user.RawData["email_verified"].(bool); ok && !verified {
+		return ErrDomainNotAllowed
+	}
+	return nil
+}
+
+// secure cookies everywhere except local dev over http
+func secureCookies() bool {
+	return os.Getenv("DEV") == "false"
+}
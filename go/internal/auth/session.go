@@ -0,0 +1,130 @@
+package auth
+
+// server-side sessions
+// the cookie only holds a random token, the session itself lives in firestore
+// so we can log people out (or ban them) without waiting for a cookie to expire
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	db "github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+const (
+	SessionCookie = "ghost_session"
+	SessionTTL    = 14 * 24 * time.Hour
+
+	// key the signed in user is stored under in the gin context
+	userKey = "user"
+)
+
+// creates a session for the user and sets the cookie
+func StartSession(c *gin.Context, userID string) error {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	now := time.Now().UTC()
+	session := types.Session{
+		UserID:    userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(SessionTTL),
+	}
+	if err := db.SaveSession(c.Request.Context(), sessionID(token), session); err != nil {
+		return err
+	}
+
+	setSessionCookie(c, token, int(SessionTTL.Seconds()))
+	return nil
+}
+
+// deletes the current session (if any) and clears the cookie
+func EndSession(c *gin.Context) error {
+	token, err := c.Cookie(SessionCookie)
+	setSessionCookie(c, "", -1)
+	if err != nil || token == "" {
+		return nil
+	}
+	return db.DeleteSession(c.Request.Context(), sessionID(token))
+}
+
+// rejects the request with 401 unless it has a valid session
+// the user is available to later handlers through CurrentUser
+func RequireUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := loadUser(c)
+		if err != nil {
+			if !errors.Is(err, db.ErrNotFound) && !errors.Is(err, http.ErrNoCookie) {
+				slog.ErrorContext(c, "session error", "err", err)
+			}
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "not signed in"})
+			return
+		}
+		c.Set(userKey, user)
+		c.Next()
+	}
+}
+
+// returns the signed in user, nil if RequireUser did not run
+func CurrentUser(c *gin.Context) *types.User {
+	if v, ok := c.Get(userKey); ok {
+		return v.(*types.User)
+	}
+	return nil
+}
+
+func loadUser(c *gin.Context) (*types.User, error) {
+	token, err := c.Cookie(SessionCookie)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	session, err := db.GetSession(ctx, sessionID(token))
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return nil, db.ErrNotFound
+	}
+	return db.GetUser(ctx, session.UserID)
+}
+
+// firestore doc ID for a session token
+func sessionID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func setSessionCookie(c *gin.Context, value string, maxAge int) {
+	// the frontend lives on another site in prod, so the cookie has to be SameSite=None there
+	if secureCookies() {
+		c.SetSameSite(http.SameSiteNoneMode)
+	} else {
+		c.SetSameSite(http.SameSiteLaxMode)
+	}
+	c.SetCookie(SessionCookie, value, maxAge, "/", "", secureCookies(), true)
+}
+
+// rate limiter key func that buckets by signed in user
+func ByUser(c *gin.Context) string {
+	if user := CurrentUser(c); user != nil {
+		return "user:" + user.ID
+	}
+	return ""
+}
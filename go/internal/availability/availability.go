@@ -0,0 +1,130 @@
+package availability
+
+// schedule math shared by the handlers
+// Meeting.Day/StartTime/EndTime are a weekly pattern in campus local time
+// (day 0 = sunday, times in minutes since midnight) so everything here works in that clock
+
+import (
+	"sort"
+	"time"
+	_ "time/tzdata" // containers don't always ship zoneinfo
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+const (
+	minutesPerDay  = 24 * 60
+	minutesPerWeek = 7 * minutesPerDay
+)
+
+// all of our campuses are in virginia
+var Campus = mustLoad("America/New_York")
+
+func mustLoad(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+// free/busy state of a room at a point in time
+type State struct {
+	Free bool
+	// when a free room gets its next class, zero if nothing is scheduled all week
+	FreeUntil time.Time
+	// when a busy room frees up (back-to-back classes are merged)
+	BusyUntil time.Time
+}
+
+// returns the day of week and minute of day of t on campus
+func Clock(t time.Time) (int, int) {
+	t = t.In(Campus)
+	return int(t.Weekday()), t.Hour()*60 + t.Minute()
+}
+
+// a meeting expressed as minutes since sunday midnight
+type interval struct {
+	start, end int
+}
+
+// flattens the weekly schedule into sorted, merged intervals
+func weekIntervals(schedule []types.Meeting) []interval {
+	var list []interval
+	for _, m := range schedule {
+		if m.EndTime <= m.StartTime {
+			continue
+		}
+		base := m.Day * minutesPerDay
+		list = append(list, interval{base + m.StartTime, base + m.EndTime})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].start < list[j].start })
+
+	var merged []interval
+	for _, iv := range list {
+		if n := len(merged); n > 0 && iv.start <= merged[n-1].end {
+			if iv.end > merged[n-1].end {
+				merged[n-1].end = iv.end
+			}
+			continue
+		}
+		merged = append(merged, iv)
+	}
+	return merged
+}
+
+// computes whether the room is free at t and until when
+func RoomState(schedule []types.Meeting, t time.Time) State {
+	ivs := weekIntervals(schedule)
+	if len(ivs) == 0 {
+		return State{Free: true}
+	}
+
+	day, minute := Clock(t)
+	now := day*minutesPerDay + minute
+
+	// start of the current minute on campus, offsets below are added to this
+	local := t.In(Campus).Truncate(time.Minute)
+	at := func(weekMinute int) time.Time {
+		return local.Add(time.Duration(weekMinute-now) * time.Minute)
+	}
+
+	// look at this week and next so a sunday night query still finds monday's classes
+	for week := 0; week < 2; week++ {
+		for _, iv := range ivs {
+			start := iv.start + week*minutesPerWeek
+			end := iv.end + week*minutesPerWeek
+			if end <= now {
+				continue
+			}
+			if start <= now {
+				return State{Free: false, BusyUntil: at(end)}
+			}
+			return State{Free: true, FreeUntil: at(start)}
+		}
+	}
+	return State{Free: true}
+}
+
+// returns the first class overlapping [start, end), nil if the room is free the whole time
+// start and end must fall on the same campus day
+func Conflict(schedule []types.Meeting, start, end time.Time) *types.Meeting {
+	day, from := Clock(start)
+	_, to := Clock(end)
+	if to == 0 && end.After(start) {
+		// ends exactly at midnight
+		to = minutesPerDay
+	}
+	for i, m := range schedule {
+		if m.Day == day && m.StartTime < to && from < m.EndTime {
+			return &schedule[i]
+		}
+	}
+	return nil
+}
+
+// returns true if start and end are on the same campus day (end may be midnight)
+func SameDay(start, end time.Time) bool {
+	s, e := start.In(Campus), end.In(Campus).Add(-time.Nanosecond)
+	return s.Year() == e.Year() && s.YearDay() == e.YearDay()
+}
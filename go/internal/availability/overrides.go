@@ -0,0 +1,138 @@
+package availability
+
+// manual overrides merged on top of the scraped schedule
+// blocks, closures and events make a room busy for their date range, hidden buildings drop out of listings
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// overrides that haven't ended, indexed for lookups
+// a nil *Overrides has none, so handlers can keep going when they can't be read
+type Overrides struct {
+	rooms     map[string][]types.Override // block, closed and event by room ID, sorted by start
+	buildings map[string]bool             // hidden at now
+}
+
+func NewOverrides(list []types.Override, now time.Time) *Overrides {
+	o := &Overrides{rooms: make(map[string][]types.Override), buildings: make(map[string]bool)}
+	for _, ov := range list {
+		if ov.EndedBy(now) {
+			continue
+		}
+		switch ov.Kind {
+		case types.OverrideHideBuilding:
+			if ov.ActiveAt(now) {
+				o.buildings[ov.Building] = true
+			}
+		case types.OverrideBlock, types.OverrideClosed, types.OverrideEvent:
+			o.rooms[ov.RoomID] = append(o.rooms[ov.RoomID], ov)
+		}
+	}
+	for _, list := range o.rooms {
+		sort.Slice(list, func(i, j int) bool { return list[i].Start.Before(list[j].Start) })
+	}
+	return o
+}
+
+// the room's overrides, nil if it has none
+func (o *Overrides) Room(id string) []types.Override {
+	if o == nil {
+		return nil
+	}
+	return o.rooms[id]
+}
+
+// true if the building was hidden by an admin
+func (o *Overrides) Hidden(building string) bool {
+	return o != nil && o.buildings[building]
+}
+
+// drops rooms in hidden buildings and attaches the overrides of the rest
+// rooms isn't modified, callers may be holding on to it (caches)
+func (o *Overrides) Apply(rooms []types.Room) []types.Room {
+	if o == nil {
+		return rooms
+	}
+	list := make([]types.Room, 0, len(rooms))
+	for _, r := range rooms {
+		if o.Hidden(r.Building) {
+			continue
+		}
+		r.Overrides = o.Room(r.ID)
+		list = append(list, r)
+	}
+	return list
+}
+
+// the first of the room's blocks, closures and events that overlaps [start, end), nil if none does
+func (o *Overrides) Conflict(roomID string, start, end time.Time) *types.Override {
+	for _, ov := range o.Room(roomID) {
+		if ov.Start.Before(end) && (ov.End == nil || start.Before(*ov.End)) {
+			return &ov
+		}
+	}
+	return nil
+}
+
+// RoomState with the room's overrides taken into account
+// a closure without an end makes the room busy with a zero BusyUntil
+func (o *Overrides) State(room types.Room, t time.Time) State {
+	state := RoomState(room.Schedule, t)
+	list := o.Room(room.ID)
+	if len(list) == 0 {
+		return state
+	}
+
+	covered := false
+	for _, ov := range list {
+		covered = covered || ov.ActiveAt(t)
+	}
+	if state.Free && !covered {
+		// the next override may start before the next class
+		for _, ov := range list {
+			if ov.Start.After(t) {
+				if state.FreeUntil.IsZero() || ov.Start.Before(state.FreeUntil) {
+					state.FreeUntil = ov.Start
+				}
+				break
+			}
+		}
+		return state
+	}
+
+	until := t
+	if !state.Free {
+		until = state.BusyUntil
+	}
+	return State{Free: false, BusyUntil: busyUntil(room.Schedule, list, until)}
+}
+
+// follows back-to-back classes and overrides from until, zero if one of the overrides never ends
+func busyUntil(schedule []types.Meeting, list []types.Override, until time.Time) time.Time {
+	// a room booked around the clock would never free up, give up after a while
+	for range 100 {
+		changed := false
+		for _, ov := range list {
+			if ov.Start.After(until) {
+				break
+			}
+			if ov.End == nil {
+				return time.Time{}
+			}
+			if ov.End.After(until) {
+				until, changed = *ov.End, true
+			}
+		}
+		if s := RoomState(schedule, until); !s.Free && s.BusyUntil.After(until) {
+			until, changed = s.BusyUntil, true
+		}
+		if !changed {
+			break
+		}
+	}
+	return until
+}
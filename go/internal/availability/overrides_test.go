@@ -0,0 +1,91 @@
+package availability
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// a Tuesday on campus
+func at(hour, minute int) time.Time {
+	return time.Date(2026, 1, 20, hour, minute, 0, 0, Campus)
+}
+
+func ptr(t time.Time) *time.Time { return &t }
+
+func TestOverridesConflict(t *testing.T) {
+	o := NewOverrides([]types.Override{
+		{ID: "block", Kind: types.OverrideBlock, RoomID: "A", Start: at(12, 0), End: ptr(at(14, 0))},
+		{ID: "closed", Kind: types.OverrideClosed, RoomID: "B", Start: at(9, 0)},
+		{ID: "ended", Kind: types.OverrideEvent, RoomID: "C", Start: at(8, 0), End: ptr(at(9, 0))},
+		{ID: "hidden", Kind: types.OverrideHideBuilding, Building: "HORIZN", Start: at(8, 0)},
+	}, at(10, 0))
+
+	tests := []struct {
+		name       string
+		room       string
+		start, end time.Time
+		want       string
+	}{
+		{"before the block", "A", at(10, 0), at(12, 0), ""},
+		{"into the block", "A", at(11, 0), at(12, 1), "block"},
+		{"inside the block", "A", at(12, 30), at(13, 0), "block"},
+		{"after the block", "A", at(14, 0), at(15, 0), ""},
+		{"closed until deleted", "B", at(18, 0), at(19, 0), "closed"},
+		{"ended override", "C", at(10, 0), at(11, 0), ""},
+		{"other room", "D", at(10, 0), at(11, 0), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if ov := o.Conflict(tt.room, tt.start, tt.end); ov != nil {
+				got = ov.ID
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	var none *Overrides
+	if none.Conflict("A", at(12, 0), at(13, 0)) != nil || none.Hidden("HORIZN") {
+		t.Error("nil Overrides should have none")
+	}
+	if !o.Hidden("HORIZN") || o.Hidden("ENGR") {
+		t.Error("only HORIZN is hidden")
+	}
+}
+
+func TestOverridesState(t *testing.T) {
+	// class 10:30-11:30 on tuesdays
+	room := types.Room{ID: "A", Schedule: []types.Meeting{{Day: 2, StartTime: 10*60 + 30, EndTime: 11*60 + 30}}}
+	tests := []struct {
+		name      string
+		overrides []types.Override
+		now       time.Time
+		want      State
+	}{
+		{"no overrides", nil, at(10, 0), State{Free: true, FreeUntil: at(10, 30)}},
+		{"block before the class", []types.Override{
+			{Kind: types.OverrideBlock, RoomID: "A", Start: at(10, 15), End: ptr(at(10, 20))},
+		}, at(10, 0), State{Free: true, FreeUntil: at(10, 15)}},
+		{"event running into the class", []types.Override{
+			{Kind: types.OverrideEvent, RoomID: "A", Start: at(9, 0), End: ptr(at(10, 45))},
+		}, at(10, 0), State{BusyUntil: at(11, 30)}},
+		{"block right after the class", []types.Override{
+			{Kind: types.OverrideBlock, RoomID: "A", Start: at(11, 30), End: ptr(at(13, 0))},
+		}, at(11, 0), State{BusyUntil: at(13, 0)}},
+		{"closed", []types.Override{
+			{Kind: types.OverrideClosed, RoomID: "A", Start: at(8, 0)},
+		}, at(10, 0), State{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewOverrides(tt.overrides, tt.now).State(room, tt.now)
+			if got.Free != tt.want.Free || !got.FreeUntil.Equal(tt.want.FreeUntil) || !got.BusyUntil.Equal(tt.want.BusyUntil) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
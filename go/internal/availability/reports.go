@@ -0,0 +1,83 @@
+package availability
+
+import (
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// aggregates active reports into a single status with a confidence score
+// each report is weighted by how fresh it is (1 when just reported, 0 at expiry).
+// confidence is the winning status' share of the total weight, scaled down
+// when there is little evidence so one report never reads as 100% sure
+func SummarizeReports(reports []types.Report, now time.Time) *types.ReportSummary {
+	if len(reports) == 0 {
+		return nil
+	}
+
+	weights := make(map[string]float64)
+	var total float64
+	var count int
+	var last time.Time
+	for _, r := range reports {
+		w := 1 - now.Sub(r.ReportedAt).Seconds()/types.ReportTTL.Seconds()
+		if w > 1 {
+			w = 1
+		}
+		if w <= 0 {
+			continue
+		}
+		weights[r.Status] += w
+		total += w
+		count++
+		if r.ReportedAt.After(last) {
+			last = r.ReportedAt
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+
+	best := ""
+	for s, w := range weights {
+		if best == "" || w > weights[best] || (w == weights[best] && s < best) {
+			best = s
+		}
+	}
+
+	return &types.ReportSummary{
+		Status: best,
+		// the winning share scaled by total/(total+1): one fresh report tops out at 0.5,
+		// two agreeing at 0.67, so a single report never reads as a sure thing
+		Confidence:     weights[best] / (total + 1),
+		Count:          count,
+		LastReportedAt: last,
+	}
+}
+
+// the schedule and overrides' verdict on a room at now
+func Available(room types.Room, now time.Time, overrides *Overrides) *types.Availability {
+	state := overrides.State(room, now)
+	a := &types.Availability{Free: state.Free}
+	if !state.FreeUntil.IsZero() {
+		a.FreeUntil = &state.FreeUntil
+	}
+	if !state.BusyUntil.IsZero() {
+		a.BusyUntil = &state.BusyUntil
+	}
+	return a
+}
+
+// builds the free/busy view of a room at now
+func Status(room types.Room, now time.Time, reports []types.Report, overrides *Overrides) types.RoomStatus {
+	a := Available(room, now, overrides)
+	return types.RoomStatus{
+		RoomID:    room.ID,
+		Building:  room.Building,
+		Number:    room.Number,
+		Free:      a.Free,
+		FreeUntil: a.FreeUntil,
+		BusyUntil: a.BusyUntil,
+		Reports:   SummarizeReports(reports, now),
+	}
+}
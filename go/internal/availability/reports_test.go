@@ -0,0 +1,84 @@
+package availability
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// a report ago before now
+func report(status string, ago time.Duration) types.Report {
+	return types.Report{Status: status, ReportedAt: at(12, 0).Add(-ago)}
+}
+
+func TestSummarizeReports(t *testing.T) {
+	half := types.ReportTTL / 2
+	tests := []struct {
+		name       string
+		reports    []types.Report
+		status     string
+		confidence float64
+		count      int
+	}{
+		{"one fresh report", []types.Report{report(types.ReportLocked, 0)}, types.ReportLocked, 0.5, 1},
+		{"one half way to expiry", []types.Report{report(types.ReportLocked, half)}, types.ReportLocked, 0.5 / 1.5, 1},
+		// a clock ahead of ours doesn't make a report count for more
+		{"from the future", []types.Report{report(types.ReportLocked, -time.Minute)}, types.ReportLocked, 0.5, 1},
+		{"two agreeing", []types.Report{report(types.ReportOccupied, 0), report(types.ReportOccupied, 0)}, types.ReportOccupied, 2.0 / 3, 2},
+		{"the newer one wins", []types.Report{report(types.ReportOccupied, half), report(types.ReportAvailable, 0)}, types.ReportAvailable, 1 / 2.5, 2},
+		{"two old ones lose to a fresh one", []types.Report{
+			report(types.ReportOccupied, types.ReportTTL*6/10), report(types.ReportOccupied, types.ReportTTL*6/10), report(types.ReportAvailable, 0),
+		}, types.ReportAvailable, 1 / 2.8, 3},
+		// a tie goes to the status that sorts first, so the answer doesn't flip between requests
+		{"tie", []types.Report{report(types.ReportOccupied, 0), report(types.ReportAvailable, 0)}, types.ReportAvailable, 1.0 / 3, 2},
+		{"expired ones don't count", []types.Report{report(types.ReportOccupied, types.ReportTTL), report(types.ReportLocked, 0)}, types.ReportLocked, 0.5, 1},
+		{"all expired", []types.Report{report(types.ReportOccupied, types.ReportTTL+time.Minute)}, "", 0, 0},
+		{"none", nil, "", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SummarizeReports(tt.reports, at(12, 0))
+			if tt.status == "" {
+				if got != nil {
+					t.Errorf("got %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("got nil")
+			}
+			if got.Status != tt.status || got.Count != tt.count || math.Abs(got.Confidence-tt.confidence) > 1e-9 {
+				t.Errorf("got %s at %.3f from %d, want %s at %.3f from %d",
+					got.Status, got.Confidence, got.Count, tt.status, tt.confidence, tt.count)
+			}
+		})
+	}
+}
+
+func TestSummarizeReportsLast(t *testing.T) {
+	got := SummarizeReports([]types.Report{
+		report(types.ReportOccupied, 10*time.Minute),
+		report(types.ReportOccupied, 2*time.Minute),
+		report(types.ReportOccupied, 5*time.Minute),
+	}, at(12, 0))
+	if want := at(11, 58); !got.LastReportedAt.Equal(want) {
+		t.Errorf("last reported %s, want %s", got.LastReportedAt, want)
+	}
+}
+
+// available agrees with State and leaves out the times it doesn't have
+func TestAvailable(t *testing.T) {
+	room := types.Room{ID: "A", Schedule: []types.Meeting{{Day: 2, StartTime: 10*60 + 30, EndTime: 11*60 + 30}}}
+	if a := Available(room, at(10, 0), nil); !a.Free || a.FreeUntil == nil || !a.FreeUntil.Equal(at(10, 30)) || a.BusyUntil != nil {
+		t.Errorf("before the class: %+v", a)
+	}
+	if a := Available(room, at(11, 0), nil); a.Free || a.BusyUntil == nil || !a.BusyUntil.Equal(at(11, 30)) || a.FreeUntil != nil {
+		t.Errorf("in the class: %+v", a)
+	}
+	closed := NewOverrides([]types.Override{{Kind: types.OverrideClosed, RoomID: "A", Start: at(8, 0)}}, at(10, 0))
+	if a := Available(room, at(10, 0), closed); a.Free || a.BusyUntil != nil {
+		t.Errorf("closed: %+v", a)
+	}
+}
@@ -0,0 +1,289 @@
+package banner
+
+// client for GMU's banner (Ellucian SSB) class search
+// banner search is stateful per session: a guest handshake gets a JSESSIONID cookie and a
+// synchronizer token, the term is set once, and every subject search has to be reset first
+// or results pile up on top of the previous subject
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+const DefaultBaseURL = "https://ssbstureg.gmu.edu/StudentRegistrationSsb"
+
+type Config struct {
+	BaseURL string
+	Term    string // ex) "202610"
+
+	MaxRetries int           // per request, default 4
+	BaseDelay  time.Duration // first backoff step, default 1s
+	MaxDelay   time.Duration // backoff cap, default 30s
+	Timeout    time.Duration // per attempt, default 15s
+
+	// pause between result pages, plus up to a second of jitter
+	PageDelay time.Duration // default 500ms
+	PageSize  int           // default 50
+
+	// re-handshakes allowed per subject before giving up on it
+	MaxHandshakes int // default 2
+
+	// shared by every session of a scrape, nil for no global limit
+	Limiter *Limiter
+}
+
+func (cfg *Config) defaults() {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 4
+	}
+	if cfg.BaseDelay == 0 {
+		cfg.BaseDelay = time.Second
+	}
+	if cfg.MaxDelay == 0 {
+		cfg.MaxDelay = 30 * time.Second
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 15 * time.Second
+	}
+	if cfg.PageDelay == 0 {
+		cfg.PageDelay = 500 * time.Millisecond
+	}
+	if cfg.PageSize == 0 {
+		cfg.PageSize = 50
+	}
+	if cfg.MaxHandshakes == 0 {
+		cfg.MaxHandshakes = 2
+	}
+}
+
+// one banner session, not safe for concurrent searches
+// run several clients (each with its own cookie jar and token) to search subjects in parallel
+type Client struct {
+	cfg   Config
+	http  *http.Client
+	token string
+}
+
+var tokenRe = regexp.MustCompile(`name="synchronizerToken"\s+content="([^"]+)"`)
+
+func New(cfg Config) *Client {
+	cfg.defaults()
+	return &Client{cfg: cfg}
+}
+
+func (c *Client) Term() string {
+	return c.cfg.Term
+}
+
+// starts a fresh guest session: new cookie jar, synchronizer token from the search page, term set
+func (c *Client) Handshake(ctx context.Context) error {
+	// NOTE: the jar handles JSESSIONID, a new one drops the expired session
+	jar, _ := cookiejar.New(nil)
+	c.http = &http.Client{Jar: jar, Timeout: c.cfg.Timeout}
+	c.token = ""
+
+	// visit the main page just to parse the token from the HTML
+	body, err := c.do(ctx, false, func() (*http.Request, error) {
+		req, err := http.NewRequest("GET", c.cfg.BaseURL+"/ssb/classSearch/classSearch", nil)
+		if err != nil {
+			return nil, err
+		}
+		c.setHeaders(req)
+		return req, nil
+	})
+	if err != nil {
+		return fmt.Errorf("loading search page: %w", err)
+	}
+	matches := tokenRe.FindSubmatch(body)
+	if len(matches) < 2 {
+		return errors.New("could not find synchronizer token in the search page")
+	}
+	c.token = string(matches[1])
+
+	// no term is enough for lookups that don't depend on one, like Terms
+	if c.cfg.Term == "" {
+		return nil
+	}
+
+	// all requests after this are for the term
+	form := url.Values{}
+	form.Set("term", c.cfg.Term)
+	form.Set("studyPath", "")
+	form.Set("studyPathText", "")
+	form.Set("startDatepicker", "")
+	form.Set("endDatepicker", "")
+
+	// NOTE: use the "uniqueSessionId" param to mimic a real user session
+	termURL := fmt.Sprintf("%s/ssb/term/search?mode=search&uniqueSessionId=guest%d", c.cfg.BaseURL, time.Now().UnixNano())
+	_, err = c.do(ctx, false, func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", termURL, strings.NewReader(form.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		c.setHeaders(req)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req, nil
+	})
+	if err != nil {
+		return fmt.Errorf("setting term %s: %w", c.cfg.Term, err)
+	}
+	return nil
+}
+
+// every term banner lists, newest first
+func (c *Client) Terms(ctx context.Context) ([]types.BannerTerm, error) {
+	apiURL := fmt.Sprintf("%s/ssb/classSearch/getTerms?searchTerm=&offset=1&max=100", c.cfg.BaseURL)
+
+	var terms []types.BannerTerm
+	err := c.withSession(ctx, func() error {
+		body, err := c.get(ctx, apiURL)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(body, &terms)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetching terms: %w", err)
+	}
+	return terms, nil
+}
+
+// every subject offered in the term
+func (c *Client) Subjects(ctx context.Context) ([]types.BannerSubject, error) {
+	// max=500 covers every subject
+	apiURL := fmt.Sprintf("%s/ssb/classSearch/get_subject?searchTerm=&term=%s&offset=1&max=500", c.cfg.BaseURL, c.cfg.Term)
+
+	var subjects []types.BannerSubject
+	err := c.withSession(ctx, func() error {
+		body, err := c.get(ctx, apiURL)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(body, &subjects)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetching subjects: %w", err)
+	}
+	return subjects, nil
+}
+
+// every section of a subject
+// on session expiry the search starts over from the first page after a new handshake
+func (c *Client) Sections(ctx context.Context, subject string) ([]types.BannerSection, error) {
+	var sections []types.BannerSection
+	err := c.withSession(ctx, func() error {
+		sections = nil
+		c.resetSearch(ctx)
+
+		for offset := 0; ; {
+			slog.Info("banner: fetching page", "subject", subject, "offset", offset)
+			apiURL := fmt.Sprintf(
+				"%s/ssb/searchResults/searchResults?txt_subject=%s&txt_term=%s&pageOffset=%d&pageMaxSize=%d",
+				c.cfg.BaseURL, url.QueryEscape(subject), c.cfg.Term, offset, c.cfg.PageSize,
+			)
+			body, err := c.get(ctx, apiURL)
+			if err != nil {
+				return err
+			}
+			metrics.ScrapePages.Inc()
+
+			var resp types.BannerResponse
+			if err := json.Unmarshal(body, &resp); err != nil {
+				return fmt.Errorf("decoding page at offset %d: %w", offset, err)
+			}
+			if len(resp.Data) == 0 {
+				return nil
+			}
+			sections = append(sections, resp.Data...)
+
+			offset += c.cfg.PageSize
+			if offset >= resp.TotalCount {
+				return nil
+			}
+
+			// this is just to be polite
+			// and not get rate limited and ip banned by the server lol
+			if err := sleep(ctx, c.cfg.PageDelay+time.Duration(rand.Intn(1000))*time.Millisecond); err != nil {
+				return err
+			}
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("subject %s: %w", subject, err)
+	}
+	return sections, nil
+}
+
+// runs fn, re-handshaking and running it again when the session expired
+func (c *Client) withSession(ctx context.Context, fn func() error) error {
+	if c.http == nil {
+		if err := c.Handshake(ctx); err != nil {
+			return err
+		}
+	}
+	for handshakes := 0; ; handshakes++ {
+		err := fn()
+		if !errors.Is(err, ErrSessionExpired) || handshakes >= c.cfg.MaxHandshakes {
+			return err
+		}
+		slog.Warn("banner: session expired, handshaking again")
+		metrics.ScrapeErrors.WithLabelValues("session").Inc()
+		if err := c.Handshake(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) get(ctx context.Context, apiURL string) ([]byte, error) {
+	return c.do(ctx, true, func() (*http.Request, error) {
+		req, err := http.NewRequest("GET", apiURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		c.setHeaders(req)
+		return req, nil
+	})
+}
+
+// clears the search criteria in the session
+// best effort, a failed reset shows up as an expired session on the next search
+func (c *Client) resetSearch(ctx context.Context) {
+	_, err := c.do(ctx, false, func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", c.cfg.BaseURL+"/ssb/classSearch/resetDataForm", nil)
+		if err != nil {
+			return nil, err
+		}
+		c.setHeaders(req)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req, nil
+	})
+	if err != nil {
+		slog.Warn("banner: failed to reset search", "err", err)
+	}
+}
+
+// headers of the banner SPA, so the traffic looks like a student looking at classes
+func (c *Client) setHeaders(req *http.Request) {
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	req.Header.Set("Accept", "application/json, text/javascript, */*; q=0.01")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	if c.token != "" {
+		req.Header.Set("X-Synchronizer-Token", c.token)
+	}
+}
@@ -0,0 +1,39 @@
+package banner
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// spaces requests out across every session sharing it,
+// so running more sessions doesn't mean hitting banner any harder
+type Limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// at most perSecond requests a second, 0 or less means no limit
+func NewLimiter(perSecond float64) *Limiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &Limiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// blocks until the caller's turn, a nil limiter never blocks
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+	return sleep(ctx, at.Sub(now))
+}
@@ -0,0 +1,101 @@
+package banner
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waits are measured from time.Now, so allow for the test itself taking a moment
+const slack = 20 * time.Millisecond
+
+func checkSpacing(t *testing.T, waits []time.Duration, interval time.Duration) {
+	t.Helper()
+	for i, d := range waits {
+		want := time.Duration(i) * interval
+		if d > want || d < want-slack {
+			t.Errorf("wait %d was %s, want %s", i, d, want)
+		}
+	}
+}
+
+func TestLimiterSpacing(t *testing.T) {
+	waits := recordSleeps(t)
+	l := NewLimiter(10)
+	for range 5 {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkSpacing(t, *waits, 100*time.Millisecond)
+}
+
+// an idle limiter lets the next request through right away but doesn't save up a burst
+func TestLimiterIdle(t *testing.T) {
+	waits := recordSleeps(t)
+	l := NewLimiter(10)
+	l.next = time.Now().Add(-time.Hour)
+	l.Wait(context.Background())
+	l.Wait(context.Background())
+	checkSpacing(t, *waits, 100*time.Millisecond)
+}
+
+// sessions sharing a limiter take turns, together they're no faster than one
+func TestLimiterShared(t *testing.T) {
+	var mu sync.Mutex
+	var waits []time.Duration
+	orig := sleep
+	sleep = func(ctx context.Context, d time.Duration) error {
+		mu.Lock()
+		waits = append(waits, d)
+		mu.Unlock()
+		return nil
+	}
+	t.Cleanup(func() { sleep = orig })
+
+	l := NewLimiter(4)
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 2 {
+				l.Wait(context.Background())
+			}
+		}()
+	}
+	wg.Wait()
+	slices.Sort(waits)
+	checkSpacing(t, waits, 250*time.Millisecond)
+}
+
+func TestLimiterNone(t *testing.T) {
+	waits := recordSleeps(t)
+	for _, rate := range []float64{0, -1} {
+		if l := NewLimiter(rate); l != nil {
+			t.Errorf("NewLimiter(%v) = %+v, want no limit", rate, l)
+		}
+	}
+	var l *Limiter
+	if err := l.Wait(context.Background()); err != nil || len(*waits) > 0 {
+		t.Errorf("nil limiter waited %v, %v", *waits, err)
+	}
+}
+
+// a session that gives up doesn't sit out its turn
+func TestLimiterCanceled(t *testing.T) {
+	l := NewLimiter(1)
+	l.Wait(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	if err := l.Wait(ctx); err != context.Canceled {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+	if waited := time.Since(start); waited > 500*time.Millisecond {
+		t.Errorf("waited %s after the context was canceled", waited)
+	}
+}
@@ -0,0 +1,172 @@
+package banner
+
+// retrying HTTP layer for banner
+// banner is slow, occasionally 5xx's under registration load and rate limits aggressive clients,
+// so every request goes through do: bounded retries with exponential backoff and full jitter,
+// Retry-After honoured on 429/503, and session expiry surfaced as ErrSessionExpired so callers
+// can re-handshake instead of getting an HTML login page back as "JSON"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+)
+
+// the synchronizer token or JSESSIONID is no longer valid, Handshake again
+var ErrSessionExpired = errors.New("banner session expired")
+
+// a response we won't retry (4xx other than 429)
+type StatusError struct {
+	Status int
+	Body   string // first few hundred bytes, for the log
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("banner returned %d: %s", e.Status, e.Body)
+}
+
+// never wait longer than this for a single Retry-After
+const maxRetryAfter = 5 * time.Minute
+
+// sends the request built by newReq, retrying transient failures
+// newReq is called again for every attempt since bodies can't be replayed
+// isJSON marks endpoints that only ever answer with JSON, an html page from those means the session is gone
+func (c *Client) do(ctx context.Context, isJSON bool, newReq func() (*http.Request, error)) ([]byte, error) {
+	var lastErr error
+	for attempt := 0; attempt <= c.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			wait := c.backoff(attempt)
+			var se *retryAfterError
+			if errors.As(lastErr, &se) && se.after > 0 {
+				wait = se.after
+			}
+			slog.Warn("banner: retrying", "attempt", attempt, "wait", wait, "err", lastErr)
+			metrics.ScrapeErrors.WithLabelValues("retry").Inc()
+			if err := sleep(ctx, wait); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := c.cfg.Limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		req, err := newReq()
+		if err != nil {
+			return nil, err
+		}
+		body, err := c.once(req.WithContext(ctx), isJSON)
+		if err == nil {
+			return body, nil
+		}
+		if !retryable(err) || ctx.Err() != nil {
+			return nil, err
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("giving up after %d attempts: %w", c.cfg.MaxRetries+1, lastErr)
+}
+
+// one attempt
+func (c *Client) once(req *http.Request, isJSON bool) ([]byte, error) {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, ErrSessionExpired
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		return nil, &retryAfterError{status: resp.StatusCode, after: retryAfter(resp.Header.Get("Retry-After"), time.Now())}
+	case resp.StatusCode >= 500:
+		return nil, &retryAfterError{status: resp.StatusCode}
+	case resp.StatusCode != http.StatusOK:
+		return nil, &StatusError{Status: resp.StatusCode, Body: snippet(body)}
+	}
+
+	// an expired session gets redirected to the html entry page with a 200
+	if isJSON && strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		return nil, ErrSessionExpired
+	}
+	return body, nil
+}
+
+// 429, 503 and other 5xx responses
+type retryAfterError struct {
+	status int
+	after  time.Duration // from Retry-After, 0 if not sent
+}
+
+func (e *retryAfterError) Error() string {
+	return fmt.Sprintf("banner returned %d", e.status)
+}
+
+func retryable(err error) bool {
+	var se *StatusError
+	if errors.As(err, &se) || errors.Is(err, ErrSessionExpired) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	// network errors, timeouts and the retryAfterError statuses
+	return true
+}
+
+// exponential backoff with full jitter: random between 0 and min(max, base * 2^attempt)
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.cfg.MaxDelay
+	if attempt < 30 {
+		d = min(c.cfg.MaxDelay, c.cfg.BaseDelay<<attempt)
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// parses Retry-After as seconds or an HTTP date, 0 if missing or invalid
+func retryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	var d time.Duration
+	if secs, err := strconv.Atoi(v); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(v); err == nil {
+		d = t.Sub(now)
+	}
+	if d < 0 {
+		return 0
+	}
+	return min(d, maxRetryAfter)
+}
+
+// up to 200 bytes of body for error messages
+func snippet(b []byte) string {
+	if len(b) > 200 {
+		b = b[:200]
+	}
+	return string(b)
+}
+
+// a var so tests can record the waits instead of sitting through them
+var sleep = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,201 @@
+package banner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// records every wait instead of sleeping
+func recordSleeps(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var waits []time.Duration
+	orig := sleep
+	sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	t.Cleanup(func() { sleep = orig })
+	return &waits
+}
+
+// one canned answer from the test server
+type response struct {
+	status      int
+	retryAfter  string
+	contentType string
+	body        string
+}
+
+// a client for a server that answers each attempt with the next of responses, repeating the last one
+func testClient(t *testing.T, responses ...response) (*Client, *atomic.Int32) {
+	t.Helper()
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(attempts.Add(1)) - 1
+		resp := responses[min(n, len(responses)-1)]
+		if resp.retryAfter != "" {
+			w.Header().Set("Retry-After", resp.retryAfter)
+		}
+		if resp.contentType == "" {
+			resp.contentType = "application/json"
+		}
+		w.Header().Set("Content-Type", resp.contentType)
+		w.WriteHeader(resp.status)
+		w.Write([]byte(resp.body))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(Config{BaseURL: srv.URL, MaxRetries: 4, BaseDelay: time.Millisecond, MaxDelay: 4 * time.Millisecond})
+	c.http = srv.Client()
+	return c, &attempts
+}
+
+func get(c *Client, isJSON bool) ([]byte, error) {
+	return c.do(context.Background(), isJSON, func() (*http.Request, error) {
+		return http.NewRequest("GET", c.cfg.BaseURL+"/ssb/searchResults/searchResults", nil)
+	})
+}
+
+func TestDoRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []response
+		attempts  int
+		// waits before each retry, 0 = any backoff up to MaxDelay
+		waits []time.Duration
+	}{
+		{"ok right away", []response{{status: 200, body: "ok"}}, 1, nil},
+		{"429 with Retry-After seconds", []response{{status: 429, retryAfter: "7"}, {status: 200, body: "ok"}},
+			2, []time.Duration{7 * time.Second}},
+		{"503 with Retry-After, then 500, then ok", []response{{status: 503, retryAfter: "2"}, {status: 500}, {status: 200, body: "ok"}},
+			3, []time.Duration{2 * time.Second, 0}},
+		{"Retry-After is capped", []response{{status: 429, retryAfter: "86400"}, {status: 200, body: "ok"}},
+			2, []time.Duration{maxRetryAfter}},
+		{"502s back off", []response{{status: 502}, {status: 502}, {status: 200, body: "ok"}},
+			3, []time.Duration{0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			waits := recordSleeps(t)
+			c, attempts := testClient(t, tt.responses...)
+
+			body, err := get(c, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != "ok" {
+				t.Errorf("body %q", body)
+			}
+			if int(attempts.Load()) != tt.attempts {
+				t.Errorf("%d attempts, want %d", attempts.Load(), tt.attempts)
+			}
+			if len(*waits) != len(tt.waits) {
+				t.Fatalf("waited %v, want %d waits", *waits, len(tt.waits))
+			}
+			for i, want := range tt.waits {
+				got := (*waits)[i]
+				if want != 0 && got != want {
+					t.Errorf("wait %d: %s, want %s", i+1, got, want)
+				}
+				if want == 0 && (got < 0 || got > c.cfg.MaxDelay) {
+					t.Errorf("wait %d: %s, want a backoff up to %s", i+1, got, c.cfg.MaxDelay)
+				}
+			}
+		})
+	}
+}
+
+func TestDoGivesUp(t *testing.T) {
+	recordSleeps(t)
+	c, attempts := testClient(t, response{status: 500})
+
+	_, err := get(c, true)
+	if err == nil || !strings.Contains(err.Error(), "giving up after 5 attempts") {
+		t.Errorf("got %v", err)
+	}
+	if attempts.Load() != 5 {
+		t.Errorf("%d attempts, want 5", attempts.Load())
+	}
+}
+
+func TestDoDoesNotRetry(t *testing.T) {
+	tests := []struct {
+		name string
+		resp response
+		json bool
+		want func(error) bool
+	}{
+		{"404", response{status: 404, body: "no such page"}, true, func(err error) bool {
+			var se *StatusError
+			return errors.As(err, &se) && se.Status == 404 && se.Body == "no such page"
+		}},
+		{"401 means the session expired", response{status: 401}, true, func(err error) bool { return errors.Is(err, ErrSessionExpired) }},
+		{"html from a JSON endpoint is the login page", response{status: 200, contentType: "text/html", body: "<html>"}, true,
+			func(err error) bool { return errors.Is(err, ErrSessionExpired) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			waits := recordSleeps(t)
+			c, attempts := testClient(t, tt.resp)
+
+			_, err := get(c, tt.json)
+			if !tt.want(err) {
+				t.Errorf("got %v", err)
+			}
+			if attempts.Load() != 1 || len(*waits) != 0 {
+				t.Errorf("%d attempts and waits %v, want one attempt", attempts.Load(), *waits)
+			}
+		})
+	}
+
+	// the search page is html, that's fine where JSON isn't expected
+	recordSleeps(t)
+	c, _ := testClient(t, response{status: 200, contentType: "text/html", body: "<html>"})
+	if _, err := get(c, false); err != nil {
+		t.Errorf("html page: %v", err)
+	}
+}
+
+func TestDoStopsWhenCanceled(t *testing.T) {
+	recordSleeps(t)
+	c, attempts := testClient(t, response{status: 503, retryAfter: "1"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.do(ctx, true, func() (*http.Request, error) {
+		return http.NewRequest("GET", c.cfg.BaseURL, nil)
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+	if attempts.Load() > 1 {
+		t.Errorf("%d attempts after cancel", attempts.Load())
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 20, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", 0},
+		{"30", 30 * time.Second},
+		{"-5", 0},
+		{"soon", 0},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"3600", maxRetryAfter},
+	}
+	for _, tt := range tests {
+		if got := retryAfter(tt.header, now); got != tt.want {
+			t.Errorf("retryAfter(%q) = %s, want %s", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package cron
+
+// standard 5 field cron expressions: minute hour day-of-month month day-of-week
+//
+//	*/15 * * * *                  every 15 minutes
+//	0 3 * * *                     3am every day
+//	30 2 * * mon-fri              2:30am on weekdays
+//	CRON_TZ=America/New_York 0 3 * * *   3am eastern, wherever the server runs
+//
+// fields take *, lists (1,15), ranges (1-5) and steps (*/2, 0-30/10), months and days also
+// take names (jan, mon). like vixie cron, when both day fields are restricted either one matching is enough
+// @hourly, @daily (@midnight), @weekly, @monthly and @yearly (@annually) are shorthands
+//
+// daylight saving works like vixie cron too: times the clocks skip in spring fire right after the change,
+// and in the hour that repeats in the fall only schedules that run every hour fire twice
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Schedule struct {
+	expr                          string
+	minute, hour, dom, month, dow uint64 // bit n set = n matches
+	anyDom, anyDow                bool   // the day field was *
+	loc                           *time.Location
+}
+
+type bounds struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minutes = bounds{0, 59, nil}
+	hours   = bounds{0, 23, nil}
+	doms    = bounds{1, 31, nil}
+	months  = bounds{1, 12, map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 is sunday too
+	dows = bounds{0, 7, map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}}
+)
+
+var shorthands = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// parses an expression, times are in the server's local time unless it starts with CRON_TZ=
+func Parse(expr string) (*Schedule, error) {
+	s := &Schedule{expr: strings.TrimSpace(expr), loc: time.Local}
+	spec := s.expr
+	if rest, ok := strings.CutPrefix(spec, "CRON_TZ="); ok {
+		name, fields, _ := strings.Cut(rest, " ")
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("cron %q: %w", expr, err)
+		}
+		s.loc, spec = loc, strings.TrimSpace(fields)
+	}
+	if full, ok := shorthands[spec]; ok {
+		spec = full
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron %q: want 5 fields (minute hour day month weekday), got %d", expr, len(fields))
+	}
+	var err error
+	for i, f := range []struct {
+		bits *uint64
+		b    bounds
+	}{{&s.minute, minutes}, {&s.hour, hours}, {&s.dom, doms}, {&s.month, months}, {&s.dow, dows}} {
+		if *f.bits, err = parseField(fields[i], f.b); err != nil {
+			return nil, fmt.Errorf("cron %q: %w", expr, err)
+		}
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.anyDom = fields[2] == "*"
+	s.anyDow = fields[4] == "*"
+	return s, nil
+}
+
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// the first time after t the schedule fires, zero if it never does (ex. 0 0 30 feb *)
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.In(s.loc).Truncate(time.Minute).Add(time.Minute)
+	// nothing fires less than once every 4 years (feb 29), anything past that never matches
+	end := t.AddDate(5, 0, 0)
+	for t.Before(end) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = forward(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.loc))
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = forward(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.loc))
+			continue
+		}
+		if s.skipped(t) {
+			return t
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = forward(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.loc))
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 || (s.hour != everyHour && repeated(t)) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// next, unless a DST change turned it into a time at or before t
+// (time.Date picks either side for a wall clock time that doesn't exist)
+func forward(t, next time.Time) time.Time {
+	if next.After(t) {
+		return next
+	}
+	return t.Add(time.Hour)
+}
+
+const everyHour = 1<<24 - 1
+
+// true if the clocks jumped forward right before t over a time the schedule would have fired at
+func (s *Schedule) skipped(t time.Time) bool {
+	for w := wall(t.Add(-time.Minute)).Add(time.Minute); w.Before(wall(t)); w = w.Add(time.Minute) {
+		if s.hour&(1<<uint(w.Hour())) != 0 && s.minute&(1<<uint(w.Minute())) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// true if t's wall clock time already happened an hour earlier, the clocks went back
+func repeated(t time.Time) bool {
+	return wall(t.Add(-time.Hour)).Equal(wall(t))
+}
+
+// t's wall clock time, as if it were UTC
+func wall(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if !s.anyDom && !s.anyDow {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// "1-5,10,*/15" -> bitset of matching values
+func parseField(field string, b bounds) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("bad step in %q", part)
+			}
+			step = n
+		}
+
+		lo, hi := b.min, b.max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			from, to, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = value(from, b); err != nil {
+				return 0, err
+			}
+			if hi, err = value(to, b); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("backwards range %q", rng)
+			}
+		default:
+			v, err := value(rng, b)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			// "5/10" means from 5 to the end every 10
+			if !hasStep {
+				hi = v
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func value(s string, b bounds) (int, error) {
+	if v, ok := b.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < b.min || v > b.max {
+		return 0, fmt.Errorf("%q is not between %d and %d", s, b.min, b.max)
+	}
+	return v, nil
+}
@@ -0,0 +1,177 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+// bits set at each of vs
+func set(vs ...int) uint64 {
+	var bits uint64
+	for _, v := range vs {
+		bits |= 1 << uint(v)
+	}
+	return bits
+}
+
+// bits set from lo to hi every step
+func span(lo, hi, step int) uint64 {
+	var bits uint64
+	for v := lo; v <= hi; v += step {
+		bits |= 1 << uint(v)
+	}
+	return bits
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr                          string
+		minute, hour, dom, month, dow uint64
+	}{
+		{"* * * * *", span(0, 59, 1), everyHour, span(1, 31, 1), span(1, 12, 1), span(0, 7, 1)},
+		{"*/15 * * * *", set(0, 15, 30, 45), everyHour, span(1, 31, 1), span(1, 12, 1), span(0, 7, 1)},
+		{"0 3 * * *", set(0), set(3), span(1, 31, 1), span(1, 12, 1), span(0, 7, 1)},
+		{"30 2 * * mon-fri", set(30), set(2), span(1, 31, 1), span(1, 12, 1), span(1, 5, 1)},
+		{"0-30/10 9-17/4 1,15 jan,JUL sun", set(0, 10, 20, 30), set(9, 13, 17), set(1, 15), set(1, 7), set(0)},
+		{"5/20 * * * *", set(5, 25, 45), everyHour, span(1, 31, 1), span(1, 12, 1), span(0, 7, 1)},
+		{"0 0 * * 7", set(0), set(0), span(1, 31, 1), span(1, 12, 1), set(0, 7)},
+		{"1,2-4,*/30 * * * *", set(0, 1, 2, 3, 4, 30), everyHour, span(1, 31, 1), span(1, 12, 1), span(0, 7, 1)},
+		{"@daily", set(0), set(0), span(1, 31, 1), span(1, 12, 1), span(0, 7, 1)},
+		{"@hourly", set(0), everyHour, span(1, 31, 1), span(1, 12, 1), span(0, 7, 1)},
+		{"  @weekly ", set(0), set(0), span(1, 31, 1), span(1, 12, 1), set(0)},
+		{"CRON_TZ=America/New_York 0 3 * * *", set(0), set(3), span(1, 31, 1), span(1, 12, 1), span(0, 7, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.minute != tt.minute || s.hour != tt.hour || s.dom != tt.dom || s.month != tt.month || s.dow != tt.dow {
+				t.Errorf("got %b %b %b %b %b", s.minute, s.hour, s.dom, s.month, s.dow)
+			}
+		})
+	}
+
+	s, _ := Parse("CRON_TZ=America/New_York 0 3 * * *")
+	if s.loc.String() != "America/New_York" {
+		t.Errorf("location %s", s.loc)
+	}
+	if s.String() != "CRON_TZ=America/New_York 0 3 * * *" {
+		t.Errorf("String() = %q", s)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"* * * foo *",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"1-x * * * *",
+		"1,,2 * * * *",
+		"@every 5m",
+		"CRON_TZ=Nowhere/Special 0 3 * * *",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("%q parsed", expr)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone data:", err)
+	}
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, ny)
+	}
+	// in 2026 the clocks go from 2:00 EST to 3:00 EDT on march 8, and from 2:00 EDT back to 1:00 EST on november 1
+	fallBack := at(time.November, 1, 0, 0)
+	est := func(hour, minute int) time.Time {
+		return fallBack.Add(time.Duration(hour+1)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	edt := func(hour, minute int) time.Time {
+		return fallBack.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want []time.Time
+	}{
+		{"every 15 minutes", "*/15 * * * *", at(time.January, 20, 10, 7),
+			[]time.Time{at(time.January, 20, 10, 15), at(time.January, 20, 10, 30)}},
+		{"on the minute is not again", "0 3 * * *", at(time.January, 20, 3, 0),
+			[]time.Time{at(time.January, 21, 3, 0)}},
+		{"weekdays", "30 2 * * mon-fri", at(time.January, 23, 12, 0), // a friday
+			[]time.Time{at(time.January, 26, 2, 30), at(time.January, 27, 2, 30)}},
+		{"either day field", "0 0 1 * mon", at(time.January, 27, 0, 0), // a tuesday
+			[]time.Time{at(time.February, 1, 0, 0), at(time.February, 2, 0, 0), at(time.February, 9, 0, 0)}},
+		{"leap day", "0 0 29 feb *", at(time.January, 1, 0, 0),
+			[]time.Time{time.Date(2028, time.February, 29, 0, 0, 0, 0, ny)}},
+
+		// spring forward: 2:xx doesn't exist, those fire at 3:00 EDT once, the next day is back to normal
+		{"daily in the skipped hour", "CRON_TZ=America/New_York 30 2 * * *", at(time.March, 7, 12, 0),
+			[]time.Time{at(time.March, 8, 3, 0), at(time.March, 9, 2, 30)}},
+		{"daily outside the skipped hour", "CRON_TZ=America/New_York 30 1 * * *", at(time.March, 7, 12, 0),
+			[]time.Time{at(time.March, 8, 1, 30), at(time.March, 9, 1, 30)}},
+		{"hourly across spring forward", "CRON_TZ=America/New_York 0 * * * *", at(time.March, 8, 0, 30),
+			[]time.Time{at(time.March, 8, 1, 0), at(time.March, 8, 3, 0), at(time.March, 8, 4, 0)}},
+		{"steps across spring forward", "CRON_TZ=America/New_York */20 1-3 * * *", at(time.March, 8, 1, 30),
+			[]time.Time{at(time.March, 8, 1, 40), at(time.March, 8, 3, 0), at(time.March, 8, 3, 20)}},
+
+		// fall back: 1:xx happens twice, fixed times fire in the first one only, hourly fires in both
+		{"daily in the repeated hour", "CRON_TZ=America/New_York 30 1 * * *", at(time.October, 31, 12, 0),
+			[]time.Time{edt(1, 30), at(time.November, 2, 1, 30)}},
+		{"daily after the repeated hour", "CRON_TZ=America/New_York 30 2 * * *", at(time.October, 31, 12, 0),
+			[]time.Time{at(time.November, 1, 2, 30), at(time.November, 2, 2, 30)}},
+		{"hourly across fall back", "CRON_TZ=America/New_York 0 * * * *", at(time.October, 31, 23, 30),
+			[]time.Time{edt(0, 0), edt(1, 0), est(1, 0), est(2, 0)}},
+		{"every 30 minutes across fall back", "CRON_TZ=America/New_York */30 * * * *", edt(1, 0),
+			[]time.Time{edt(1, 30), est(1, 0), est(1, 30), est(2, 0)}},
+		{"steps across fall back", "CRON_TZ=America/New_York */20 1-3 * * *", edt(1, 30),
+			[]time.Time{edt(1, 40), est(2, 0), est(2, 20)}},
+		{"restarted in the repeated hour", "CRON_TZ=America/New_York 30 1 * * *", est(1, 10),
+			[]time.Time{at(time.November, 2, 1, 30)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// a plain expression runs in the server's time zone, pin it to the campus one
+			s.loc = ny
+			from := tt.from
+			for i, want := range tt.want {
+				got := s.Next(from)
+				if !got.Equal(want) {
+					t.Fatalf("run %d: got %s, want %s", i+1, got.In(ny), want.In(ny))
+				}
+				from = got
+			}
+		})
+	}
+}
+
+func TestNextNever(t *testing.T) {
+	s, err := Parse("0 0 30 feb *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := s.Next(time.Now()); !next.IsZero() {
+		t.Errorf("got %s, want never", next)
+	}
+}
@@ -0,0 +1,126 @@
+package firestore
+
+// the audit log (audit/{id}), entries are only ever created
+// nothing in the code updates or deletes them
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/store"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// filters for ListAudit, empty fields match everything
+// every equality filter has a composite index with at in firestore.indexes.json, firestore merges them when several are set
+type AuditQuery struct {
+	RoomID   string
+	Building string
+	Actor    string
+	Action   string
+	Ref      string
+	Since    time.Time // inclusive
+	Until    time.Time // exclusive
+	Limit    int
+	Cursor   string // ID of the last entry of the previous page
+}
+
+// writes the entries, filling in their IDs
+func AppendAudit(ctx context.Context, entries []types.AuditEntry) error {
+	if Client == nil {
+		return errors.New("database not initialized")
+	}
+	col := Client.Collection("audit")
+	bw := Client.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, 0, len(entries))
+	for i := range entries {
+		ref := col.NewDoc()
+		entries[i].ID = ref.ID
+		job, err := bw.Create(ref, entries[i])
+		if err != nil {
+			bw.End()
+			return err
+		}
+		jobs = append(jobs, job)
+	}
+	bw.End()
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newest entries first, and the cursor for the next page ("" on the last one)
+// store.ErrInvalidCursor if the cursor doesn't name an entry
+func ListAudit(ctx context.Context, q AuditQuery) ([]types.AuditEntry, string, error) {
+	if Client == nil {
+		return nil, "", errors.New("database not initialized")
+	}
+	col := Client.Collection("audit")
+	query := col.Query
+	for _, f := range []struct{ path, value string }{
+		{"room_id", q.RoomID}, {"building", q.Building}, {"actor", q.Actor}, {"action", q.Action}, {"ref", q.Ref},
+	} {
+		if f.value != "" {
+			query = query.Where(f.path, "==", f.value)
+		}
+	}
+	if !q.Since.IsZero() {
+		query = query.Where("at", ">=", q.Since)
+	}
+	if !q.Until.IsZero() {
+		query = query.Where("at", "<", q.Until)
+	}
+	query = query.OrderBy("at", firestore.Desc)
+	if q.Cursor != "" {
+		if strings.Contains(q.Cursor, "/") {
+			return nil, "", store.ErrInvalidCursor
+		}
+		doc, err := col.Doc(q.Cursor).Get(ctx)
+		metrics.Reads("audit", 1)
+		if status.Code(err) == codes.NotFound {
+			return nil, "", store.ErrInvalidCursor
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.StartAfter(doc)
+	}
+
+	// one extra to know if there is another page
+	iter := query.Limit(q.Limit + 1).Documents(ctx)
+	defer iter.Stop()
+	list := []types.AuditEntry{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		metrics.Reads("audit", 1)
+		var e types.AuditEntry
+		if err := doc.DataTo(&e); err != nil {
+			continue
+		}
+		list = append(list, e)
+	}
+
+	next := ""
+	if len(list) > q.Limit {
+		list = list[:q.Limit]
+		next = list[len(list)-1].ID
+	}
+	return list, next, nil
+}
@@ -0,0 +1,146 @@
+package firestore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// meta/dataset is rewritten whenever a snapshot is published so the API knows to refresh
+// anything it derives from the scraped data (like the search index), see snapshot.go
+
+// zero time if the scraper never marked the dataset
+func GetDatasetUpdatedAt(ctx context.Context) (time.Time, error) {
+	if Client == nil {
+		return time.Time{}, errors.New("database not initialized")
+	}
+	// also refreshes the active version, so whatever is rebuilt next reads the new snapshot
+	meta, err := readMeta(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return meta.UpdatedAt, nil
+}
+
+func GetAllRooms(ctx context.Context) ([]types.Room, error) {
+	return readAll[types.Room](ctx, "rooms")
+}
+
+func GetAllSections(ctx context.Context) ([]types.Section, error) {
+	return readAll[types.Section](ctx, "sections")
+}
+
+func GetAllInstructors(ctx context.Context) ([]types.Instructor, error) {
+	return readAll[types.Instructor](ctx, "instructors")
+}
+
+// reads every doc of a scraped collection
+func readAll[T any](ctx context.Context, collection string) ([]T, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	col, err := dataset(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+	return readCollection[T](ctx, col, collection)
+}
+
+func readCollection[T any](ctx context.Context, col *firestore.CollectionRef, collection string) ([]T, error) {
+	iter := col.Documents(ctx)
+	defer iter.Stop()
+
+	var list []T
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		metrics.Reads(collection, 1)
+		var v T
+		if err := doc.DataTo(&v); err != nil {
+			continue
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
+
+// reads the given docs of a scraped collection in one round trip, missing docs are skipped
+func readByID[T any](ctx context.Context, collection string, ids []string) ([]T, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	col, err := dataset(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]*firestore.DocumentRef, len(ids))
+	for i, id := range ids {
+		refs[i] = col.Doc(id)
+	}
+	docs, err := Client.GetAll(ctx, refs)
+	metrics.Reads(collection, len(docs))
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]T, 0, len(docs))
+	for _, doc := range docs {
+		if !doc.Exists() {
+			continue
+		}
+		var v T
+		if err := doc.DataTo(&v); err != nil {
+			continue
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
+
+// runs one query per chunk of values, "in" and "array-contains-any" take at most 30
+func readIn[T any](ctx context.Context, collection, field, op string, values []string) ([]T, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	col, err := dataset(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+	var list []T
+	for start := 0; start < len(values); start += 30 {
+		chunk := values[start:min(start+30, len(values))]
+		iter := col.Where(field, op, chunk).Documents(ctx)
+		for {
+			doc, err := iter.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				iter.Stop()
+				return nil, err
+			}
+			metrics.Reads(collection, 1)
+			var v T
+			if err := doc.DataTo(&v); err != nil {
+				continue
+			}
+			list = append(list, v)
+		}
+		iter.Stop()
+	}
+	return list, nil
+}
@@ -0,0 +1,162 @@
+package firestore
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/store"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+var _ store.Datasets = Store{}
+
+// how many firestore writes run at once
+const saveConcurrency = 20
+
+// how many snapshots an import keeps, enough to roll back a couple of bad ones
+const KeepSnapshots = 3
+
+// writes the dataset into a new snapshot and checks every doc made it
+// the API doesn't see any of it until the snapshot is published
+func WriteSnapshot(ctx context.Context, ds store.Dataset) (string, error) {
+	version := NewVersion(ds.Term, time.Now())
+	if err := CreateSnapshot(ctx, version, ds.Term); err != nil {
+		return "", fmt.Errorf("creating snapshot: %w", err)
+	}
+
+	var failed atomic.Int64
+	// semaphore to limit concurrency
+	sem := make(chan struct{}, saveConcurrency)
+	var wg sync.WaitGroup
+	save := func(kind, id string, fn func() error) {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(); err != nil {
+				slog.Error("saving "+kind, "id", id, "err", err)
+				metrics.ScrapeErrors.WithLabelValues("save").Inc()
+				failed.Add(1)
+			}
+		}()
+	}
+
+	slog.Info("saving room schedules", "snapshot", version, "rooms", len(ds.Rooms))
+	for _, room := range ds.Rooms {
+		save("room", room.ID, func() error { return saveRoom(ctx, version, room) })
+	}
+	slog.Info("saving sections", "snapshot", version, "sections", len(ds.Sections))
+	for _, section := range ds.Sections {
+		save("section", section.CRN, func() error { return saveSection(ctx, version, section) })
+	}
+	slog.Info("saving instructors", "snapshot", version, "instructors", len(ds.Instructors))
+	for _, inst := range ds.Instructors {
+		save("instructor", inst.ID, func() error { return saveInstructor(ctx, version, inst) })
+	}
+	wg.Wait()
+
+	err := fmt.Errorf("%d writes failed", failed.Load())
+	if failed.Load() == 0 {
+		err = CompleteSnapshot(ctx, version, len(ds.Rooms), len(ds.Sections), len(ds.Instructors))
+	}
+	if err != nil {
+		// nothing points at it, don't leave the half written snapshot around
+		if derr := DeleteSnapshot(context.WithoutCancel(ctx), version); derr != nil {
+			slog.Warn("deleting unfinished snapshot", "snapshot", version, "err", derr)
+		}
+		return "", err
+	}
+	return version, nil
+}
+
+// everything in a snapshot, for diffs and exports
+func ReadSnapshot(ctx context.Context, version string) (store.Dataset, error) {
+	rooms, sections, instructors, err := readSnapshot(ctx, version)
+	if err != nil {
+		return store.Dataset{}, err
+	}
+	// versions start with the term, see NewVersion
+	term, _, _ := strings.Cut(version, "-")
+	return store.Dataset{Term: term, Rooms: rooms, Sections: sections, Instructors: instructors}, nil
+}
+
+// the dataset the API is serving, the active snapshot or the top level collections before the first publish
+func (Store) ExportDataset(ctx context.Context) (store.Dataset, error) {
+	version, err := ActiveVersion(ctx)
+	if err != nil {
+		return store.Dataset{}, err
+	}
+	if version != "" {
+		return ReadSnapshot(ctx, version)
+	}
+
+	var ds store.Dataset
+	if ds.Rooms, err = GetAllRooms(ctx); err != nil {
+		return store.Dataset{}, err
+	}
+	if ds.Sections, err = GetAllSections(ctx); err != nil {
+		return store.Dataset{}, err
+	}
+	if ds.Instructors, err = GetAllInstructors(ctx); err != nil {
+		return store.Dataset{}, err
+	}
+	if len(ds.Sections) > 0 {
+		ds.Term = ds.Sections[0].Term
+	}
+	return ds, nil
+}
+
+// saves the dataset as a new snapshot, publishes it and prunes down to KeepSnapshots
+func (Store) ImportDataset(ctx context.Context, ds store.Dataset) error {
+	version, err := WriteSnapshot(ctx, ds)
+	if err != nil {
+		return err
+	}
+	return PublishAndPrune(ctx, version, KeepSnapshots)
+}
+
+// flips the API over to the snapshot and deletes all but the newest keep snapshots
+func PublishAndPrune(ctx context.Context, version string, keep int) error {
+	if err := PublishSnapshot(ctx, version); err != nil {
+		return fmt.Errorf("publishing snapshot %s: %w", version, err)
+	}
+	slog.Info("published snapshot", "snapshot", version)
+
+	// an old snapshot left behind is only storage, the publish already happened
+	deleted, err := PruneSnapshots(ctx, keep)
+	if err != nil {
+		slog.Warn("pruning old snapshots", "err", err)
+	}
+	if len(deleted) > 0 {
+		slog.Info("deleted old snapshots", "snapshots", deleted)
+	}
+	return nil
+}
+
+func saveRoom(ctx context.Context, version string, room types.Room) error {
+	_, err := snapshotCollection(version, "rooms").Doc(room.ID).Set(ctx, room)
+	return err
+}
+
+func saveSection(ctx context.Context, version string, section types.Section) error {
+	// query fields aren't in the JSON form, derive them here so imported datasets get them too
+	section.TitleLower = strings.ToLower(section.Title)
+	section.InstructorIDs = make([]string, 0, len(section.Instructors))
+	for _, inst := range section.Instructors {
+		section.InstructorIDs = append(section.InstructorIDs, inst.ID)
+	}
+	_, err := snapshotCollection(version, "sections").Doc(section.CRN).Set(ctx, section)
+	return err
+}
+
+func saveInstructor(ctx context.Context, version string, instructor types.Instructor) error {
+	_, err := snapshotCollection(version, "instructors").Doc(instructor.ID).Set(ctx, instructor)
+	return err
+}
@@ -0,0 +1,116 @@
+package firestore
+
+// favorites and saved searches live in subcollections of the user doc
+// users/{id}/favorites/{room id} and users/{id}/searches/{search id}
+
+import (
+	"context"
+	"errors"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+func favorites(userID string) *firestore.CollectionRef {
+	return Client.Collection("users").Doc(userID).Collection("favorites")
+}
+
+func searches(userID string) *firestore.CollectionRef {
+	return Client.Collection("users").Doc(userID).Collection("searches")
+}
+
+func GetFavorites(ctx context.Context, userID string) ([]types.Favorite, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	iter := favorites(userID).OrderBy("created_at", firestore.Asc).Documents(ctx)
+	defer iter.Stop()
+
+	list := []types.Favorite{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		metrics.Reads("favorites", 1)
+		var fav types.Favorite
+		if err := doc.DataTo(&fav); err != nil {
+			continue
+		}
+		list = append(list, fav)
+	}
+	return list, nil
+}
+
+// adding the same room twice just overwrites the doc
+func SaveFavorite(ctx context.Context, userID string, fav types.Favorite) error {
+	if Client == nil {
+		return errors.New("database not initialized")
+	}
+	_, err := favorites(userID).Doc(fav.RoomID).Set(ctx, fav)
+	return err
+}
+
+func DeleteFavorite(ctx context.Context, userID, roomID string) error {
+	if Client == nil {
+		return errors.New("database not initialized")
+	}
+	_, err := favorites(userID).Doc(roomID).Delete(ctx)
+	return err
+}
+
+func GetSavedSearches(ctx context.Context, userID string) ([]types.SavedSearch, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	iter := searches(userID).OrderBy("created_at", firestore.Asc).Documents(ctx)
+	defer iter.Stop()
+
+	list := []types.SavedSearch{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		metrics.Reads("searches", 1)
+		var search types.SavedSearch
+		if err := doc.DataTo(&search); err != nil {
+			continue
+		}
+		list = append(list, search)
+	}
+	return list, nil
+}
+
+func SaveSearch(ctx context.Context, userID string, search *types.SavedSearch) error {
+	if Client == nil {
+		return errors.New("database not initialized")
+	}
+	ref := searches(userID).NewDoc()
+	search.ID = ref.ID
+	_, err := ref.Set(ctx, search)
+	return err
+}
+
+// ErrNotFound if the search doesn't exist
+func DeleteSearch(ctx context.Context, userID, searchID string) error {
+	if Client == nil {
+		return errors.New("database not initialized")
+	}
+	_, err := searches(userID).Doc(searchID).Delete(ctx, firestore.Exists)
+	if status.Code(err) == codes.NotFound {
+		return ErrNotFound
+	}
+	return err
+}
@@ -7,7 +7,7 @@ package firestore
 import (
 	"context"
 	"errors"
-	"log"
+	"log/slog"
 	"os"
 
 	"cloud.google.com/go/firestore"
@@ -39,7 +39,7 @@ func Init() error {
 		return err
 	}
 
-	log.Println("firestore initialized successfully")
+	slog.Info("firestore initialized")
 	return nil
 }
 
@@ -0,0 +1,146 @@
+package firestore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// returned when a hold overlaps another hold on the same room
+var ErrConflict = errors.New("conflict")
+
+// returned when the user already has types.MaxActiveHolds holds that haven't ended
+var ErrTooManyHolds = errors.New("too many active holds")
+
+// saves a hold unless another active hold on the room overlaps it or the user has too many
+// the checks and the write happen in one transaction so two groups can't grab the same slot
+// and one user can't get past the limit with requests in parallel
+func CreateHold(ctx context.Context, hold *types.Hold, now time.Time) error {
+	if Client == nil {
+		return errors.New("database not initialized")
+	}
+	col := Client.Collection("holds")
+	ref := col.NewDoc()
+	hold.ID = ref.ID
+
+	return Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		mine, err := tx.Documents(col.Where("user_id", "==", hold.UserID)).GetAll()
+		if err != nil {
+			return err
+		}
+		active := 0
+		for _, doc := range mine {
+			var other types.Hold
+			if err := doc.DataTo(&other); err == nil && other.End.After(now) {
+				active++
+			}
+		}
+		if active >= types.MaxActiveHolds {
+			return ErrTooManyHolds
+		}
+
+		docs, err := tx.Documents(col.Where("room_id", "==", hold.RoomID)).GetAll()
+		if err != nil {
+			return err
+		}
+		for _, doc := range docs {
+			var other types.Hold
+			if err := doc.DataTo(&other); err != nil {
+				continue
+			}
+			if other.Start.Before(hold.End) && hold.Start.Before(other.End) {
+				return ErrConflict
+			}
+		}
+		return tx.Create(ref, hold)
+	})
+}
+
+// returns holds that haven't ended yet, grouped by room ID
+func GetActiveHolds(ctx context.Context, now time.Time) (map[string][]types.Hold, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	holds := make(map[string][]types.Hold)
+	list, err := readHolds(Client.Collection("holds").Where("end", ">", now).Documents(ctx))
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range list {
+		holds[h.RoomID] = append(holds[h.RoomID], h)
+	}
+	return holds, nil
+}
+
+// returns the user's holds that haven't ended yet
+func GetUserHolds(ctx context.Context, userID string, now time.Time) ([]types.Hold, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	all, err := readHolds(Client.Collection("holds").Where("user_id", "==", userID).Documents(ctx))
+	if err != nil {
+		return nil, err
+	}
+	list := []types.Hold{}
+	for _, h := range all {
+		if h.End.After(now) {
+			list = append(list, h)
+		}
+	}
+	return list, nil
+}
+
+// deletes a hold owned by userID, ErrNotFound if it doesn't exist or belongs to someone else
+func DeleteHold(ctx context.Context, userID, id string) error {
+	if Client == nil {
+		return errors.New("database not initialized")
+	}
+	ref := Client.Collection("holds").Doc(id)
+	return Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		var hold types.Hold
+		if err := doc.DataTo(&hold); err != nil {
+			return err
+		}
+		if hold.UserID != userID {
+			return ErrNotFound
+		}
+		return tx.Delete(ref)
+	})
+}
+
+func readHolds(iter *firestore.DocumentIterator) ([]types.Hold, error) {
+	defer iter.Stop()
+
+	var list []types.Hold
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		metrics.Reads("holds", 1)
+		var hold types.Hold
+		if err := doc.DataTo(&hold); err != nil {
+			continue
+		}
+		list = append(list, hold)
+	}
+	return list, nil
+}
@@ -0,0 +1,58 @@
+package firestore
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// reads a single instructor, ErrNotFound if it doesn't exist
+func GetInstructor(ctx context.Context, id string) (*types.Instructor, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	col, err := dataset(ctx, "instructors")
+	if err != nil {
+		return nil, err
+	}
+	doc, err := col.Doc(id).Get(ctx)
+	metrics.Reads("instructors", 1)
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var instructor types.Instructor
+	if err := doc.DataTo(&instructor); err != nil {
+		return nil, err
+	}
+	return &instructor, nil
+}
+
+// all sections an instructor teaches
+func GetSectionsByInstructor(ctx context.Context, id string) ([]types.Section, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	col, err := dataset(ctx, "sections")
+	if err != nil {
+		return nil, err
+	}
+	return querySections(ctx, col.Where("instructor_ids", "array-contains", id), 0)
+}
+
+// reads the given instructors in one round trip, missing instructors are skipped
+func GetInstructorsByID(ctx context.Context, ids []string) ([]types.Instructor, error) {
+	return readByID[types.Instructor](ctx, "instructors", ids)
+}
+
+// all sections taught by any of the instructors
+func GetSectionsByInstructors(ctx context.Context, ids []string) ([]types.Section, error) {
+	return readIn[types.Section](ctx, "sections", "instructor_ids", "array-contains-any", ids)
+}
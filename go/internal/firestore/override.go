@@ -0,0 +1,150 @@
+package firestore
+
+// manual corrections (overrides/{id}), kept apart from the snapshot collections so publishing a scrape never touches them
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// how long reads trust the cached active overrides, creating or deleting one on this instance
+// drops the cache right away, other instances (and the scraper) see it within this long
+const overridesTTL = 30 * time.Second
+
+// cached GetActiveOverrides, every room listing, search and stream reads them
+var activeOverrides struct {
+	sync.Mutex
+	list []types.Override // not ended when read
+	read time.Time
+}
+
+func invalidateOverrides() {
+	activeOverrides.Lock()
+	activeOverrides.read = time.Time{}
+	activeOverrides.Unlock()
+}
+
+// saves a new override and fills in its ID
+func CreateOverride(ctx context.Context, o *types.Override) error {
+	if Client == nil {
+		return errors.New("database not initialized")
+	}
+	ref := Client.Collection("overrides").NewDoc()
+	o.ID = ref.ID
+	_, err := ref.Create(ctx, o)
+	invalidateOverrides()
+	return err
+}
+
+// every override, newest first, ended ones included
+// only the admin listing reads the whole collection, everything else uses GetActiveOverrides
+func GetOverrides(ctx context.Context) ([]types.Override, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	return readOverrides(Client.Collection("overrides").Documents(ctx))
+}
+
+// overrides that haven't ended by now, including ones that start later, newest first
+// served from a cache of the ones that hadn't ended when it was read, so a now before
+// that (v2's ?at= in the past) doesn't see overrides that ended in between
+func GetActiveOverrides(ctx context.Context, now time.Time) ([]types.Override, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	activeOverrides.Lock()
+	cached, fresh := activeOverrides.list, time.Since(activeOverrides.read) < overridesTTL
+	activeOverrides.Unlock()
+
+	if fresh {
+		metrics.CacheHit("overrides")
+	} else {
+		metrics.CacheMiss("overrides")
+		read := time.Now()
+		// end is null for closures and hidden buildings that last until they're deleted
+		list, err := readOverrides(Client.Collection("overrides").WhereEntity(firestore.OrFilter{
+			Filters: []firestore.EntityFilter{
+				firestore.PropertyFilter{Path: "end", Operator: ">", Value: read},
+				firestore.PropertyFilter{Path: "end", Operator: "==", Value: nil},
+			},
+		}).Documents(ctx))
+		if err != nil {
+			return nil, err
+		}
+		activeOverrides.Lock()
+		activeOverrides.list, activeOverrides.read = list, read
+		activeOverrides.Unlock()
+		cached = list
+	}
+
+	// callers get their own slice, the cached one is shared
+	list := []types.Override{}
+	for _, o := range cached {
+		if !o.EndedBy(now) {
+			list = append(list, o)
+		}
+	}
+	return list, nil
+}
+
+// newest first
+func readOverrides(iter *firestore.DocumentIterator) ([]types.Override, error) {
+	defer iter.Stop()
+
+	list := []types.Override{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		metrics.Reads("overrides", 1)
+		var o types.Override
+		if err := doc.DataTo(&o); err != nil {
+			continue
+		}
+		list = append(list, o)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list, nil
+}
+
+// deletes an override and returns what it was, ErrNotFound if it doesn't exist
+func DeleteOverride(ctx context.Context, id string) (*types.Override, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	ref := Client.Collection("overrides").Doc(id)
+	var o types.Override
+	err := Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := doc.DataTo(&o); err != nil {
+			return err
+		}
+		return tx.Delete(ref)
+	})
+	if err != nil {
+		return nil, err
+	}
+	invalidateOverrides()
+	return &o, nil
+}
@@ -0,0 +1,55 @@
+package firestore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/api/iterator"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// saves a user submitted room report
+// NOTE: set up a TTL policy on reports.expires_at in the GCP console
+// so expired reports get deleted automatically, we filter them out on read anyway
+func SaveReport(ctx context.Context, report *types.Report) error {
+	if Client == nil {
+		return errors.New("database not initialized")
+	}
+	ref := Client.Collection("reports").NewDoc()
+	report.ID = ref.ID
+	_, err := ref.Set(ctx, report)
+	return err
+}
+
+// returns all reports that have not expired yet, grouped by room ID
+// the set of live reports is small (they only last ReportTTL) so we read them all at once
+func GetActiveReports(ctx context.Context, now time.Time) (map[string][]types.Report, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+
+	iter := Client.Collection("reports").Where("expires_at", ">", now).Documents(ctx)
+	defer iter.Stop()
+
+	reports := make(map[string][]types.Report)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		metrics.Reads("reports", 1)
+
+		var report types.Report
+		if err := doc.DataTo(&report); err != nil {
+			continue
+		}
+		reports[report.RoomID] = append(reports[report.RoomID], report)
+	}
+	return reports, nil
+}
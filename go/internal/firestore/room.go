@@ -0,0 +1,81 @@
+package firestore
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// reads a single room, ErrNotFound if it doesn't exist
+func GetRoom(ctx context.Context, id string) (*types.Room, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	col, err := dataset(ctx, "rooms")
+	if err != nil {
+		return nil, err
+	}
+	doc, err := col.Doc(id).Get(ctx)
+	metrics.Reads("rooms", 1)
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var room types.Room
+	if err := doc.DataTo(&room); err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+// reads the given rooms in one round trip, missing rooms are skipped
+func GetRoomsByID(ctx context.Context, ids []string) ([]types.Room, error) {
+	return readByID[types.Room](ctx, "rooms", ids)
+}
+
+// reads every room of a building, or every room when building is empty
+func GetRoomsByBuilding(ctx context.Context, building string) ([]types.Room, error) {
+	if building == "" {
+		return GetAllRooms(ctx)
+	}
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	col, err := dataset(ctx, "rooms")
+	if err != nil {
+		return nil, err
+	}
+	iter := col.Where("building", "==", building).Documents(ctx)
+	defer iter.Stop()
+
+	var rooms []types.Room
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		metrics.Reads("rooms", 1)
+		var room types.Room
+		if err := doc.DataTo(&room); err != nil {
+			continue
+		}
+		rooms = append(rooms, room)
+	}
+	return rooms, nil
+}
+
+// reads every room of the given buildings
+func GetRoomsByBuildings(ctx context.Context, buildings []string) ([]types.Room, error) {
+	return readIn[types.Room](ctx, "rooms", "building", "in", buildings)
+}
@@ -0,0 +1,153 @@
+package firestore
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/store"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// firestore backed store.Rooms
+type Store struct{}
+
+var _ store.Rooms = Store{}
+
+// sorts on stored fields page with firestore cursors so only one page is read,
+// free_duration has to be computed from every schedule so it reads the building and pages in memory
+// NOTE: sorting by number or capacity with a building filter needs the composite indexes in firestore.indexes.json
+func (Store) ListRooms(ctx context.Context, q store.RoomQuery) (store.RoomPage, error) {
+	if Client == nil {
+		return store.RoomPage{}, errors.New("database not initialized")
+	}
+	if err := q.Validate(); err != nil {
+		return store.RoomPage{}, err
+	}
+
+	col, err := dataset(ctx, "rooms")
+	if err != nil {
+		return store.RoomPage{}, err
+	}
+	query := col.Query
+	if q.Building != "" {
+		query = query.Where("building", "==", q.Building)
+	}
+
+	if q.Sort == store.SortFreeDuration {
+		if len(q.Fields) > 0 {
+			query = query.Select(withFields(q.Fields, "id", "schedule")...)
+		}
+		rooms, err := readRooms(ctx, query)
+		if err != nil {
+			return store.RoomPage{}, err
+		}
+		return store.ApplyQuery(rooms, q)
+	}
+
+	dir := firestore.Asc
+	if q.Desc {
+		dir = firestore.Desc
+	}
+	field := ""
+	switch q.Sort {
+	case store.SortNumber:
+		field = "number"
+	case store.SortCapacity:
+		field = "capacity"
+	}
+
+	if len(q.Fields) > 0 {
+		// the cursor is built from the id and the sort field of the last room
+		extra := []string{"id"}
+		if field != "" {
+			extra = append(extra, field)
+		}
+		query = query.Select(withFields(q.Fields, extra...)...)
+	}
+	if field != "" {
+		query = query.OrderBy(field, dir)
+	}
+	query = query.OrderBy(firestore.DocumentID, dir)
+
+	if q.Cursor != "" {
+		cur, err := store.DecodeCursor(q.Cursor, q)
+		if err != nil {
+			return store.RoomPage{}, err
+		}
+		switch v := cur.Value.(type) {
+		case nil:
+			query = query.StartAfter(cur.ID)
+		case float64:
+			// json numbers come back as floats, capacity is stored as an int
+			query = query.StartAfter(int64(v), cur.ID)
+		default:
+			query = query.StartAfter(v, cur.ID)
+		}
+	}
+	if q.Limit > 0 {
+		// one extra to know if there is a next page
+		query = query.Limit(q.Limit + 1)
+	}
+
+	rooms, err := readRooms(ctx, query)
+	if err != nil {
+		return store.RoomPage{}, err
+	}
+
+	page := store.RoomPage{Rooms: rooms}
+	if q.Limit > 0 && len(rooms) > q.Limit {
+		page.Rooms = rooms[:q.Limit]
+		last := page.Rooms[q.Limit-1]
+		cur := store.Cursor{Sort: q.Sort, Desc: q.Desc, ID: last.ID}
+		if cur.Sort == "" {
+			cur.Sort = store.SortID
+		}
+		switch q.Sort {
+		case store.SortNumber:
+			cur.Value = last.Number
+		case store.SortCapacity:
+			cur.Value = last.Capacity
+		}
+		page.NextCursor = cur.Encode()
+	}
+	return page, nil
+}
+
+func readRooms(ctx context.Context, query firestore.Query) ([]types.Room, error) {
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	var rooms []types.Room
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		metrics.Reads("rooms", 1)
+		var room types.Room
+		if err := doc.DataTo(&room); err != nil {
+			continue
+		}
+		rooms = append(rooms, room)
+	}
+	return rooms, nil
+}
+
+// copy of fields plus the extra ones not already in it, so the caller's slice is never appended to
+func withFields(fields []string, extra ...string) []string {
+	list := append([]string{}, fields...)
+	for _, f := range extra {
+		if !slices.Contains(list, f) {
+			list = append(list, f)
+		}
+	}
+	return list
+}
@@ -0,0 +1,157 @@
+package firestore
+
+// scrape runs (scrapes/{id}) and the lock that keeps two of them from overlapping (meta/scrape_lock)
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// returned when another run holds the scrape lock
+var ErrLocked = errors.New("scrape lock is held by another run")
+
+func scrapeLockRef() *firestore.DocumentRef {
+	return Client.Collection("meta").Doc("scrape_lock")
+}
+
+// a new, unsaved run ID
+func NewScrapeRunID() string {
+	return Client.Collection("scrapes").NewDoc().ID
+}
+
+// takes the lock for the run, ErrLocked if another run holds it and it hasn't expired
+func AcquireScrapeLock(ctx context.Context, lock types.ScrapeLock) error {
+	if Client == nil {
+		return errors.New("database not initialized")
+	}
+	ref := scrapeLockRef()
+	return Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		if err == nil {
+			var held types.ScrapeLock
+			if err := doc.DataTo(&held); err == nil && held.RunID != lock.RunID && held.ExpiresAt.After(lock.AcquiredAt) {
+				return ErrLocked
+			}
+		}
+		return tx.Set(ref, lock)
+	})
+}
+
+// pushes the lock's expiry out, ErrLocked if the run lost it
+func RenewScrapeLock(ctx context.Context, runID string, expires time.Time) error {
+	if Client == nil {
+		return errors.New("database not initialized")
+	}
+	ref := scrapeLockRef()
+	return Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		held, err := readScrapeLock(tx, ref)
+		if err != nil {
+			return err
+		}
+		if held == nil || held.RunID != runID {
+			return ErrLocked
+		}
+		return tx.Update(ref, []firestore.Update{{Path: "expires_at", Value: expires}})
+	})
+}
+
+// gives the lock up, does nothing if the run doesn't hold it anymore
+func ReleaseScrapeLock(ctx context.Context, runID string) error {
+	if Client == nil {
+		return errors.New("database not initialized")
+	}
+	ref := scrapeLockRef()
+	return Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		held, err := readScrapeLock(tx, ref)
+		if err != nil || held == nil || held.RunID != runID {
+			return err
+		}
+		return tx.Delete(ref)
+	})
+}
+
+// the lock if a run holds it right now, nil otherwise
+func GetScrapeLock(ctx context.Context, now time.Time) (*types.ScrapeLock, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	doc, err := scrapeLockRef().Get(ctx)
+	metrics.Reads("meta", 1)
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var lock types.ScrapeLock
+	if err := doc.DataTo(&lock); err != nil {
+		return nil, err
+	}
+	if !lock.ExpiresAt.After(now) {
+		return nil, nil
+	}
+	return &lock, nil
+}
+
+func readScrapeLock(tx *firestore.Transaction, ref *firestore.DocumentRef) (*types.ScrapeLock, error) {
+	doc, err := tx.Get(ref)
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var lock types.ScrapeLock
+	if err := doc.DataTo(&lock); err != nil {
+		return nil, err
+	}
+	return &lock, nil
+}
+
+// creates or overwrites the run's record
+func SaveScrapeRun(ctx context.Context, run *types.ScrapeRun) error {
+	if Client == nil {
+		return errors.New("database not initialized")
+	}
+	_, err := Client.Collection("scrapes").Doc(run.ID).Set(ctx, run)
+	return err
+}
+
+// the newest runs first
+func ListScrapeRuns(ctx context.Context, limit int) ([]types.ScrapeRun, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	iter := Client.Collection("scrapes").OrderBy("started_at", firestore.Desc).Limit(limit).Documents(ctx)
+	defer iter.Stop()
+
+	list := []types.ScrapeRun{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		metrics.Reads("scrapes", 1)
+		var run types.ScrapeRun
+		if err := doc.DataTo(&run); err != nil {
+			continue
+		}
+		list = append(list, run)
+	}
+	return list, nil
+}
@@ -0,0 +1,110 @@
+package firestore
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// reads a single section, ErrNotFound if it doesn't exist
+func GetSection(ctx context.Context, crn string) (*types.Section, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	col, err := dataset(ctx, "sections")
+	if err != nil {
+		return nil, err
+	}
+	doc, err := col.Doc(crn).Get(ctx)
+	metrics.Reads("sections", 1)
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var section types.Section
+	if err := doc.DataTo(&section); err != nil {
+		return nil, err
+	}
+	return &section, nil
+}
+
+// all sections of a course, ex) "CS310"
+func GetSectionsByCourse(ctx context.Context, courseID string) ([]types.Section, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	col, err := dataset(ctx, "sections")
+	if err != nil {
+		return nil, err
+	}
+	return querySections(ctx, col.Where("course_id", "==", courseID), 0)
+}
+
+// all sections of a subject, ex) "CS"
+func GetSectionsBySubject(ctx context.Context, subject string, limit int) ([]types.Section, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	col, err := dataset(ctx, "sections")
+	if err != nil {
+		return nil, err
+	}
+	return querySections(ctx, col.Where("subject", "==", subject), limit)
+}
+
+// sections whose title starts with prefix (case insensitive)
+func GetSectionsByTitlePrefix(ctx context.Context, prefix string, limit int) ([]types.Section, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	col, err := dataset(ctx, "sections")
+	if err != nil {
+		return nil, err
+	}
+	prefix = strings.ToLower(prefix)
+	q := col.
+		Where("title_lower", ">=", prefix).
+		Where("title_lower", "<", prefix+"\uf8ff")
+	return querySections(ctx, q, limit)
+}
+
+func querySections(ctx context.Context, q firestore.Query, limit int) ([]types.Section, error) {
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	iter := q.Documents(ctx)
+	defer iter.Stop()
+
+	var list []types.Section
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		metrics.Reads("sections", 1)
+		var section types.Section
+		if err := doc.DataTo(&section); err != nil {
+			continue
+		}
+		list = append(list, section)
+	}
+	return list, nil
+}
+
+// reads the given sections in one round trip, missing sections are skipped
+func GetSectionsByCRN(ctx context.Context, crns []string) ([]types.Section, error) {
+	return readByID[types.Section](ctx, "sections", crns)
+}
@@ -0,0 +1,343 @@
+package firestore
+
+// scraped data is written into a new snapshot every run:
+//   datasets/{version}/rooms, datasets/{version}/sections, datasets/{version}/instructors
+// meta/dataset.active names the snapshot the API reads, so publishing (or rolling back)
+// is a single write and the API never sees half a scrape
+// before the first publish there is no active snapshot and the top level collections are read
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// the scraped collections every snapshot has
+var snapshotCollections = []string{"rooms", "sections", "instructors"}
+
+// how long a read trusts the cached active version before checking meta/dataset again
+const activeTTL = 30 * time.Second
+
+// cached meta/dataset.active
+var active struct {
+	sync.Mutex
+	version string
+	read    time.Time
+}
+
+// meta/dataset
+type datasetMeta struct {
+	UpdatedAt time.Time `firestore:"updated_at"`
+	Active    string    `firestore:"active"`
+	Previous  string    `firestore:"previous"`
+}
+
+// returned when publishing a snapshot that doesn't exist or wasn't finished
+var ErrSnapshotIncomplete = errors.New("snapshot is incomplete")
+
+// a new version name, sorts by creation time
+func NewVersion(term string, now time.Time) string {
+	return term + "-" + now.UTC().Format("20060102T150405Z")
+}
+
+func readMeta(ctx context.Context) (datasetMeta, error) {
+	var meta datasetMeta
+	doc, err := Client.Collection("meta").Doc("dataset").Get(ctx)
+	metrics.Reads("meta", 1)
+	if status.Code(err) == codes.NotFound {
+		return meta, nil
+	}
+	if err != nil {
+		return meta, err
+	}
+	err = doc.DataTo(&meta)
+	if err == nil {
+		setActive(meta.Active)
+	}
+	return meta, err
+}
+
+func setActive(version string) {
+	active.Lock()
+	active.version = version
+	active.read = time.Now()
+	active.Unlock()
+}
+
+// the version the API serves, "" before the first publish
+func ActiveVersion(ctx context.Context) (string, error) {
+	if Client == nil {
+		return "", errors.New("database not initialized")
+	}
+	active.Lock()
+	version, fresh := active.version, time.Since(active.read) < activeTTL
+	active.Unlock()
+	if fresh {
+		return version, nil
+	}
+	meta, err := readMeta(ctx)
+	if err != nil {
+		return "", err
+	}
+	return meta.Active, nil
+}
+
+// a scraped collection of the active snapshot
+func dataset(ctx context.Context, name string) (*firestore.CollectionRef, error) {
+	version, err := ActiveVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading active dataset: %w", err)
+	}
+	if version == "" {
+		return Client.Collection(name), nil
+	}
+	return snapshotCollection(version, name), nil
+}
+
+func snapshotCollection(version, name string) *firestore.CollectionRef {
+	return Client.Collection("datasets").Doc(version).Collection(name)
+}
+
+// starts an empty snapshot, nothing reads it until it is published
+func CreateSnapshot(ctx context.Context, version, term string) error {
+	if Client == nil {
+		return errors.New("database not initialized")
+	}
+	_, err := Client.Collection("datasets").Doc(version).Create(ctx, types.Snapshot{
+		Version:   version,
+		Term:      term,
+		CreatedAt: time.Now(),
+	})
+	return err
+}
+
+// counts what actually landed in the snapshot and marks it complete if it matches what was written
+func CompleteSnapshot(ctx context.Context, version string, rooms, sections, instructors int) error {
+	if Client == nil {
+		return errors.New("database not initialized")
+	}
+	want := map[string]int{"rooms": rooms, "sections": sections, "instructors": instructors}
+	for _, name := range snapshotCollections {
+		res, err := snapshotCollection(version, name).NewAggregationQuery().WithCount("n").Get(ctx)
+		if err != nil {
+			return fmt.Errorf("counting %s: %w", name, err)
+		}
+		n, _ := res["n"].(*firestorepb.Value)
+		if got := int(n.GetIntegerValue()); got != want[name] {
+			return fmt.Errorf("snapshot %s has %d %s, wrote %d", version, got, name, want[name])
+		}
+	}
+	_, err := Client.Collection("datasets").Doc(version).Update(ctx, []firestore.Update{
+		{Path: "complete", Value: true},
+		{Path: "rooms", Value: rooms},
+		{Path: "sections", Value: sections},
+		{Path: "instructors", Value: instructors},
+	})
+	return err
+}
+
+// makes a complete snapshot the one the API serves, in one transaction with the check
+func PublishSnapshot(ctx context.Context, version string) error {
+	if Client == nil {
+		return errors.New("database not initialized")
+	}
+	snapRef := Client.Collection("datasets").Doc(version)
+	metaRef := Client.Collection("meta").Doc("dataset")
+	now := time.Now()
+	err := Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(snapRef)
+		if status.Code(err) == codes.NotFound {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		var snap types.Snapshot
+		if err := doc.DataTo(&snap); err != nil {
+			return err
+		}
+		if !snap.Complete {
+			return ErrSnapshotIncomplete
+		}
+
+		var meta datasetMeta
+		doc, err = tx.Get(metaRef)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		if err == nil {
+			if err := doc.DataTo(&meta); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Update(snapRef, []firestore.Update{{Path: "published_at", Value: now}}); err != nil {
+			return err
+		}
+		// updated_at makes the API rebuild anything derived from the data (search index, stream cache)
+		return tx.Set(metaRef, map[string]interface{}{
+			"active":     version,
+			"previous":   meta.Active,
+			"updated_at": now,
+		}, firestore.MergeAll)
+	})
+	if err != nil {
+		return err
+	}
+	setActive(version)
+	return nil
+}
+
+func readSnapshot(ctx context.Context, version string) ([]types.Room, []types.Section, []types.Instructor, error) {
+	if Client == nil {
+		return nil, nil, nil, errors.New("database not initialized")
+	}
+	if _, err := Client.Collection("datasets").Doc(version).Get(ctx); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil, nil, ErrNotFound
+		}
+		return nil, nil, nil, err
+	}
+	rooms, err := readCollection[types.Room](ctx, snapshotCollection(version, "rooms"), "rooms")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	sections, err := readCollection[types.Section](ctx, snapshotCollection(version, "sections"), "sections")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	instructors, err := readCollection[types.Instructor](ctx, snapshotCollection(version, "instructors"), "instructors")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return rooms, sections, instructors, nil
+}
+
+// every snapshot, newest first
+func ListSnapshots(ctx context.Context) ([]types.Snapshot, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	meta, err := readMeta(ctx)
+	if err != nil {
+		return nil, err
+	}
+	iter := Client.Collection("datasets").OrderBy("created_at", firestore.Desc).Documents(ctx)
+	defer iter.Stop()
+
+	var list []types.Snapshot
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		metrics.Reads("datasets", 1)
+		var snap types.Snapshot
+		if err := doc.DataTo(&snap); err != nil {
+			continue
+		}
+		snap.Active = snap.Version == meta.Active
+		list = append(list, snap)
+	}
+	return list, nil
+}
+
+// publishes an older snapshot again
+// with an empty version it goes back to the newest complete snapshot created before the active one
+// returns the version that is now active
+func RollbackSnapshot(ctx context.Context, version string) (string, error) {
+	if version == "" {
+		list, err := ListSnapshots(ctx)
+		if err != nil {
+			return "", err
+		}
+		seenActive := false
+		for _, snap := range list {
+			if snap.Active {
+				seenActive = true
+				continue
+			}
+			if seenActive && snap.Complete {
+				version = snap.Version
+				break
+			}
+		}
+		if version == "" {
+			return "", errors.New("no older complete snapshot to roll back to")
+		}
+	}
+	if err := PublishSnapshot(ctx, version); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+// deletes all but the newest keep snapshots, the active one is always kept
+// returns the deleted versions
+func PruneSnapshots(ctx context.Context, keep int) ([]string, error) {
+	list, err := ListSnapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var deleted []string
+	for i, snap := range list {
+		if i < keep || snap.Active {
+			continue
+		}
+		if err := DeleteSnapshot(ctx, snap.Version); err != nil {
+			return deleted, fmt.Errorf("deleting snapshot %s: %w", snap.Version, err)
+		}
+		deleted = append(deleted, snap.Version)
+	}
+	return deleted, nil
+}
+
+// deletes a snapshot and every doc in it, firestore doesn't delete subcollections on its own
+func DeleteSnapshot(ctx context.Context, version string) error {
+	if Client == nil {
+		return errors.New("database not initialized")
+	}
+	bw := Client.BulkWriter(ctx)
+	var jobs []*firestore.BulkWriterJob
+	for _, name := range snapshotCollections {
+		iter := snapshotCollection(version, name).DocumentRefs(ctx)
+		for {
+			ref, err := iter.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				bw.End()
+				return err
+			}
+			job, err := bw.Delete(ref)
+			if err != nil {
+				bw.End()
+				return err
+			}
+			jobs = append(jobs, job)
+		}
+	}
+	bw.End()
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			return err
+		}
+	}
+	_, err := Client.Collection("datasets").Doc(version).Delete(ctx)
+	return err
+}
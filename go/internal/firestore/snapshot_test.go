@@ -0,0 +1,203 @@
+package firestore
+
+// runs against the firestore emulator, skipped without one:
+//
+//	gcloud emulators firestore start --host-port=localhost:8081
+//	FIRESTORE_EMULATOR_HOST=localhost:8081 go test ./internal/firestore/
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// points Client at the emulator, in a project of its own so tests don't see each other's data
+func emulator(t *testing.T) context.Context {
+	t.Helper()
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+		t.Skip("FIRESTORE_EMULATOR_HOST is not set")
+	}
+	ctx := context.Background()
+	client, err := firestore.NewClient(ctx, fmt.Sprintf("ghost-test-%d", time.Now().UnixNano()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := Client
+	Client = client
+	forgetActive()
+	t.Cleanup(func() {
+		client.Close()
+		Client = prev
+		forgetActive()
+	})
+	return ctx
+}
+
+// drops the cached active version, each test starts with a new database
+func forgetActive() {
+	active.Lock()
+	active.version, active.read = "", time.Time{}
+	active.Unlock()
+}
+
+// a snapshot with one room in it, completed or left the way a crashed scrape leaves it
+func makeSnapshot(t *testing.T, ctx context.Context, version string, complete bool) {
+	t.Helper()
+	if err := CreateSnapshot(ctx, version, "202610"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := snapshotCollection(version, "rooms").Doc("HORIZN_2014").Set(ctx, types.Room{ID: "HORIZN_2014", Building: "HORIZN", Number: "2014"}); err != nil {
+		t.Fatal(err)
+	}
+	if complete {
+		if err := CompleteSnapshot(ctx, version, 1, 0, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// created_at orders snapshots, keep them apart
+	time.Sleep(5 * time.Millisecond)
+}
+
+func activeVersion(t *testing.T, ctx context.Context) string {
+	t.Helper()
+	meta, err := readMeta(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return meta.Active
+}
+
+func TestPublishSnapshot(t *testing.T) {
+	ctx := emulator(t)
+
+	makeSnapshot(t, ctx, "v1", false)
+	if err := PublishSnapshot(ctx, "v1"); !errors.Is(err, ErrSnapshotIncomplete) {
+		t.Errorf("publishing an incomplete snapshot: got %v, want ErrSnapshotIncomplete", err)
+	}
+	if err := PublishSnapshot(ctx, "nope"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("publishing a missing snapshot: got %v, want ErrNotFound", err)
+	}
+	if v := activeVersion(t, ctx); v != "" {
+		t.Errorf("active is %q after failed publishes", v)
+	}
+
+	// counts that don't match what's in the snapshot don't complete it
+	if err := CompleteSnapshot(ctx, "v1", 2, 0, 0); err == nil {
+		t.Error("completed a snapshot with a room missing")
+	}
+
+	makeSnapshot(t, ctx, "v2", true)
+	if err := PublishSnapshot(ctx, "v2"); err != nil {
+		t.Fatal(err)
+	}
+	if v := activeVersion(t, ctx); v != "v2" {
+		t.Errorf("active is %q, want v2", v)
+	}
+	rooms, _, _, err := readSnapshot(ctx, "v2")
+	if err != nil || len(rooms) != 1 {
+		t.Errorf("read %d rooms, err %v", len(rooms), err)
+	}
+}
+
+func TestRollbackSnapshot(t *testing.T) {
+	ctx := emulator(t)
+
+	// oldest first: a, an unfinished scrape, b, c
+	makeSnapshot(t, ctx, "a", true)
+	makeSnapshot(t, ctx, "crashed", false)
+	makeSnapshot(t, ctx, "b", true)
+	makeSnapshot(t, ctx, "c", true)
+
+	if _, err := RollbackSnapshot(ctx, ""); err == nil {
+		t.Error("rolled back with nothing published")
+	}
+	for _, v := range []string{"a", "b", "c"} {
+		if err := PublishSnapshot(ctx, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// an empty version steps back one complete snapshot at a time, skipping the crashed one
+	for _, want := range []string{"b", "a"} {
+		got, err := RollbackSnapshot(ctx, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want || activeVersion(t, ctx) != want {
+			t.Errorf("rolled back to %q (active %q), want %q", got, activeVersion(t, ctx), want)
+		}
+	}
+	if _, err := RollbackSnapshot(ctx, ""); err == nil {
+		t.Error("rolled back past the oldest snapshot")
+	}
+
+	// a named version goes straight there, forward too, but never to an incomplete one
+	if got, err := RollbackSnapshot(ctx, "c"); err != nil || got != "c" {
+		t.Errorf("rollback to c: %q, %v", got, err)
+	}
+	if _, err := RollbackSnapshot(ctx, "crashed"); !errors.Is(err, ErrSnapshotIncomplete) {
+		t.Errorf("rollback to an incomplete snapshot: got %v", err)
+	}
+	if v := activeVersion(t, ctx); v != "c" {
+		t.Errorf("active is %q, want c", v)
+	}
+}
+
+func TestPruneSnapshots(t *testing.T) {
+	ctx := emulator(t)
+
+	// oldest first, the oldest one is active
+	for _, v := range []string{"a", "b", "c", "d"} {
+		makeSnapshot(t, ctx, v, true)
+	}
+	if err := PublishSnapshot(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := PruneSnapshots(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(deleted, []string{"b"}) {
+		t.Errorf("deleted %v, want only b (d and c are the newest two, a is active)", deleted)
+	}
+
+	list, err := ListSnapshots(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var left []string
+	for _, snap := range list {
+		left = append(left, snap.Version)
+	}
+	if !slices.Equal(left, []string{"d", "c", "a"}) {
+		t.Errorf("left %v, want d c a", left)
+	}
+
+	// the rooms inside b went with it, the active one still has its
+	if _, _, _, err := readSnapshot(ctx, "b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("reading b: got %v, want ErrNotFound", err)
+	}
+	if docs, err := snapshotCollection("b", "rooms").Documents(ctx).GetAll(); err != nil || len(docs) != 0 {
+		t.Errorf("%d rooms left in b, err %v", len(docs), err)
+	}
+	if rooms, _, _, err := readSnapshot(ctx, "a"); err != nil || len(rooms) != 1 {
+		t.Errorf("active snapshot has %d rooms, err %v", len(rooms), err)
+	}
+
+	// keeping none still keeps the active one
+	if deleted, err = PruneSnapshots(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(deleted, []string{"d", "c"}) {
+		t.Errorf("deleted %v, want d c", deleted)
+	}
+}
@@ -0,0 +1,84 @@
+package firestore
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+var ErrNotFound = errors.New("not found")
+
+// creates or updates a user, keeping the original created_at
+func SaveUser(ctx context.Context, user *types.User) error {
+	if Client == nil {
+		return errors.New("database not initialized")
+	}
+	existing, err := GetUser(ctx, user.ID)
+	if err == nil {
+		user.CreatedAt = existing.CreatedAt
+	} else if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	_, err = Client.Collection("users").Doc(user.ID).Set(ctx, user)
+	return err
+}
+
+func GetUser(ctx context.Context, id string) (*types.User, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	doc, err := Client.Collection("users").Doc(id).Get(ctx)
+	metrics.Reads("users", 1)
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var user types.User
+	if err := doc.DataTo(&user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// NOTE: add a TTL policy on sessions.expires_at, expired sessions are rejected on read either way
+func SaveSession(ctx context.Context, id string, session types.Session) error {
+	if Client == nil {
+		return errors.New("database not initialized")
+	}
+	_, err := Client.Collection("sessions").Doc(id).Set(ctx, session)
+	return err
+}
+
+func GetSession(ctx context.Context, id string) (*types.Session, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	doc, err := Client.Collection("sessions").Doc(id).Get(ctx)
+	metrics.Reads("sessions", 1)
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var session types.Session
+	if err := doc.DataTo(&session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func DeleteSession(ctx context.Context, id string) error {
+	if Client == nil {
+		return errors.New("database not initialized")
+	}
+	_, err := Client.Collection("sessions").Doc(id).Delete(ctx)
+	return err
+}
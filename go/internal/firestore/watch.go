@@ -0,0 +1,94 @@
+package firestore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// watches are a top-level collection so the notification scheduler can read them all in one query
+
+func SaveWatch(ctx context.Context, watch *types.Watch) error {
+	if Client == nil {
+		return errors.New("database not initialized")
+	}
+	ref := Client.Collection("watches").NewDoc()
+	watch.ID = ref.ID
+	_, err := ref.Set(ctx, watch)
+	return err
+}
+
+// returns every watch, or only the user's when userID is set
+func GetWatches(ctx context.Context, userID string) ([]types.Watch, error) {
+	if Client == nil {
+		return nil, errors.New("database not initialized")
+	}
+	q := Client.Collection("watches").Query
+	if userID != "" {
+		q = q.Where("user_id", "==", userID)
+	}
+	iter := q.Documents(ctx)
+	defer iter.Stop()
+
+	list := []types.Watch{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		metrics.Reads("watches", 1)
+		var watch types.Watch
+		if err := doc.DataTo(&watch); err != nil {
+			continue
+		}
+		list = append(list, watch)
+	}
+	return list, nil
+}
+
+// deletes a watch owned by userID, ErrNotFound if it doesn't exist or belongs to someone else
+func DeleteWatch(ctx context.Context, userID, id string) error {
+	if Client == nil {
+		return errors.New("database not initialized")
+	}
+	ref := Client.Collection("watches").Doc(id)
+	return Client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if status.Code(err) == codes.NotFound {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		var watch types.Watch
+		if err := doc.DataTo(&watch); err != nil {
+			return err
+		}
+		if watch.UserID != userID {
+			return ErrNotFound
+		}
+		return tx.Delete(ref)
+	})
+}
+
+// records that the free window starting at freeAt has been notified
+func MarkWatchNotified(ctx context.Context, id string, freeAt time.Time) error {
+	if Client == nil {
+		return errors.New("database not initialized")
+	}
+	_, err := Client.Collection("watches").Doc(id).Update(ctx, []firestore.Update{
+		{Path: "notified_for", Value: freeAt},
+	})
+	return err
+}
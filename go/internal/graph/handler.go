@@ -0,0 +1,56 @@
+package graph
+
+// POST /api/graphql
+// the schema is in schema.graphql, resolvers in resolver.go
+
+import (
+	"context"
+	_ "embed"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+//go:embed schema.graphql
+var Schema string
+
+// deep queries (room -> meetings -> sections -> meetings -> room ...) fan out fast
+const maxDepth = 8
+
+var schema = graphql.MustParseSchema(Schema, &Resolver{},
+	graphql.MaxDepth(maxDepth),
+)
+
+type Request struct {
+	Query         string         `json:"query" binding:"required"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+type Response struct {
+	Data   any                     `json:"data,omitempty"`
+	Errors []*gqlerrors.QueryError `json:"errors,omitempty"`
+}
+
+// runs a query, errors from resolvers come back in the errors list with a 200 like any graphql server
+func Handler(c *gin.Context) {
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	ctx = context.WithValue(ctx, loadersKey{}, newLoaders())
+
+	resp := schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	out := Response{Errors: resp.Errors}
+	if resp.Data != nil {
+		out.Data = resp.Data
+	}
+	c.JSON(http.StatusOK, out)
+}
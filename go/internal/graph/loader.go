@@ -0,0 +1,119 @@
+package graph
+
+// dataloader-style batching
+// resolvers run concurrently for every item of a list, each Load call waits a couple of
+// milliseconds so the keys asked for by sibling resolvers go to the store in one read
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+)
+
+// a var so tests can widen the window on a busy machine
+var batchWait = 2 * time.Millisecond
+
+type loader[V any] struct {
+	name  string // cache label in metrics
+	fetch func(ctx context.Context, keys []string) (map[string]V, error)
+
+	mu      sync.Mutex
+	cache   map[string]*result[V] // per request, so every key is read at most once
+	pending map[string]*result[V]
+}
+
+type result[V any] struct {
+	done chan struct{}
+	v    V
+	err  error
+}
+
+func newLoader[V any](name string, fetch func(ctx context.Context, keys []string) (map[string]V, error)) *loader[V] {
+	return &loader[V]{name: name, fetch: fetch, cache: make(map[string]*result[V])}
+}
+
+// returns the value for key, the zero value if the store doesn't have it
+func (l *loader[V]) Load(ctx context.Context, key string) (V, error) {
+	l.mu.Lock()
+	r := l.enqueue(ctx, key)
+	l.mu.Unlock()
+	return r.wait(ctx)
+}
+
+// Load for every key in the same batch, values line up with keys
+func (l *loader[V]) LoadMany(ctx context.Context, keys []string) ([]V, error) {
+	l.mu.Lock()
+	results := make([]*result[V], len(keys))
+	for i, key := range keys {
+		results[i] = l.enqueue(ctx, key)
+	}
+	l.mu.Unlock()
+
+	values := make([]V, len(keys))
+	for i, r := range results {
+		v, err := r.wait(ctx)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// the cached or pending result for key, starting a batch if none is waiting, must hold mu
+func (l *loader[V]) enqueue(ctx context.Context, key string) *result[V] {
+	if r, ok := l.cache[key]; ok {
+		metrics.CacheHit(l.name)
+		return r
+	}
+	metrics.CacheMiss(l.name)
+	r := &result[V]{done: make(chan struct{})}
+	l.cache[key] = r
+	if l.pending == nil {
+		l.pending = make(map[string]*result[V])
+		time.AfterFunc(batchWait, func() { l.dispatch(ctx) })
+	}
+	l.pending[key] = r
+	return r
+}
+
+func (r *result[V]) wait(ctx context.Context) (V, error) {
+	select {
+	case <-r.done:
+		return r.v, r.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+func (l *loader[V]) dispatch(ctx context.Context) {
+	l.mu.Lock()
+	batch := l.pending
+	l.pending = nil
+	l.mu.Unlock()
+
+	keys := make([]string, 0, len(batch))
+	for k := range batch {
+		keys = append(keys, k)
+	}
+	values, err := l.fetch(ctx, keys)
+	for k, r := range batch {
+		r.v, r.err = values[k], err
+		close(r.done)
+	}
+}
+
+// lazily computed value shared by every resolver of a request
+type once[V any] struct {
+	once sync.Once
+	v    V
+	err  error
+}
+
+func (o *once[V]) Get(fn func() (V, error)) (V, error) {
+	o.once.Do(func() { o.v, o.err = fn() })
+	return o.v, o.err
+}
@@ -0,0 +1,166 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fetch that records every batch and answers "v:" + key, leaving out "missing"
+type fakeFetch struct {
+	mu      sync.Mutex
+	batches [][]string
+	err     error
+	block   chan struct{} // when set, fetch waits for it to close
+}
+
+func (f *fakeFetch) fetch(ctx context.Context, keys []string) (map[string]string, error) {
+	f.mu.Lock()
+	f.batches = append(f.batches, slices.Sorted(slices.Values(keys)))
+	f.mu.Unlock()
+	if f.block != nil {
+		<-f.block
+	}
+	m := make(map[string]string)
+	for _, k := range keys {
+		if k != "missing" {
+			m[k] = "v:" + k
+		}
+	}
+	return m, f.err
+}
+
+func (f *fakeFetch) rounds() [][]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.batches)
+}
+
+// a batch window wide enough that goroutines started together always share it
+func wideBatch(t *testing.T) {
+	t.Helper()
+	prev := batchWait
+	batchWait = 50 * time.Millisecond
+	t.Cleanup(func() { batchWait = prev })
+}
+
+func TestLoaderBatches(t *testing.T) {
+	wideBatch(t)
+	f := &fakeFetch{}
+	l := newLoader("test", f.fetch)
+	ctx := context.Background()
+
+	keys := []string{"a", "b", "c", "a", "missing"}
+	got := make([]string, len(keys))
+	var wg sync.WaitGroup
+	for i, k := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := l.Load(ctx, k)
+			if err != nil {
+				t.Error(err)
+			}
+			got[i] = v
+		}()
+	}
+	wg.Wait()
+
+	if want := []string{"v:a", "v:b", "v:c", "v:a", ""}; !slices.Equal(got, want) {
+		t.Errorf("loaded %v, want %v", got, want)
+	}
+	// every key once, in one round
+	if rounds := f.rounds(); len(rounds) != 1 || !slices.Equal(rounds[0], []string{"a", "b", "c", "missing"}) {
+		t.Errorf("fetched %v, want one round of a b c missing", rounds)
+	}
+
+	// cached for the rest of the request, missing keys too
+	for _, k := range []string{"a", "missing"} {
+		l.Load(ctx, k)
+	}
+	if rounds := f.rounds(); len(rounds) != 1 {
+		t.Errorf("fetched %v after the cache should have answered", rounds)
+	}
+
+	// a key asked for after the round went out starts a new one
+	if v, _ := l.Load(ctx, "d"); v != "v:d" {
+		t.Errorf("loaded %q", v)
+	}
+	if rounds := f.rounds(); len(rounds) != 2 || !slices.Equal(rounds[1], []string{"d"}) {
+		t.Errorf("fetched %v, want a second round of d", rounds)
+	}
+}
+
+func TestLoaderLoadMany(t *testing.T) {
+	f := &fakeFetch{}
+	l := newLoader("test", f.fetch)
+	ctx := context.Background()
+
+	l.Load(ctx, "a")
+	got, err := l.LoadMany(ctx, []string{"c", "a", "b", "missing", "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"v:c", "v:a", "v:b", "", "v:c"}; !slices.Equal(got, want) {
+		t.Errorf("loaded %v, want %v", got, want)
+	}
+	// a was cached, the rest went out together
+	if rounds := f.rounds(); len(rounds) != 2 || !slices.Equal(rounds[1], []string{"b", "c", "missing"}) {
+		t.Errorf("fetched %v, want a then b c missing", rounds)
+	}
+}
+
+func TestLoaderError(t *testing.T) {
+	wideBatch(t)
+	f := &fakeFetch{err: errors.New("firestore is down")}
+	l := newLoader("test", f.fetch)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for _, k := range []string{"a", "b"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := l.Load(ctx, k); !errors.Is(err, f.err) {
+				t.Errorf("%s: got %v, want the fetch error", k, err)
+			}
+		}()
+	}
+	wg.Wait()
+	// the failure is remembered for the request, it isn't retried per resolver
+	if _, err := l.LoadMany(ctx, []string{"a", "b"}); !errors.Is(err, f.err) {
+		t.Errorf("got %v, want the fetch error", err)
+	}
+	if rounds := f.rounds(); len(rounds) != 1 {
+		t.Errorf("fetched %d rounds, want 1", len(rounds))
+	}
+}
+
+func TestLoaderCanceled(t *testing.T) {
+	f := &fakeFetch{block: make(chan struct{})}
+	l := newLoader("test", f.fetch)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() {
+		_, err := l.Load(ctx, "a")
+		errs <- err
+	}()
+	// wait until the fetch is stuck, then give up on it
+	for len(f.rounds()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Load kept waiting after its context was canceled")
+	}
+	close(f.block)
+}
@@ -0,0 +1,381 @@
+package graph
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/availability"
+	db "github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// store reads for one request, see loader.go
+type loaders struct {
+	roomsByBuilding      *loader[[]types.Room]
+	rooms                *loader[*types.Room]
+	sections             *loader[*types.Section]
+	instructors          *loader[*types.Instructor]
+	sectionsByInstructor *loader[[]types.Section]
+	reports              once[map[string][]types.Report]
+	overrides            once[*availability.Overrides]
+	now                  time.Time
+}
+
+type loadersKey struct{}
+
+func newLoaders() *loaders {
+	return &loaders{
+		now: time.Now(),
+		roomsByBuilding: newLoader("graphql_rooms_by_building", func(ctx context.Context, codes []string) (map[string][]types.Room, error) {
+			rooms, err := db.GetRoomsByBuildings(ctx, codes)
+			m := make(map[string][]types.Room)
+			for _, r := range rooms {
+				m[r.Building] = append(m[r.Building], r)
+			}
+			for _, list := range m {
+				sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+			}
+			return m, err
+		}),
+		rooms: newLoader("graphql_rooms", func(ctx context.Context, ids []string) (map[string]*types.Room, error) {
+			rooms, err := db.GetRoomsByID(ctx, ids)
+			m := make(map[string]*types.Room, len(rooms))
+			for i := range rooms {
+				m[rooms[i].ID] = &rooms[i]
+			}
+			return m, err
+		}),
+		sections: newLoader("graphql_sections", func(ctx context.Context, crns []string) (map[string]*types.Section, error) {
+			sections, err := db.GetSectionsByCRN(ctx, crns)
+			m := make(map[string]*types.Section, len(sections))
+			for i := range sections {
+				m[sections[i].CRN] = &sections[i]
+			}
+			return m, err
+		}),
+		instructors: newLoader("graphql_instructors", func(ctx context.Context, ids []string) (map[string]*types.Instructor, error) {
+			list, err := db.GetInstructorsByID(ctx, ids)
+			m := make(map[string]*types.Instructor, len(list))
+			for i := range list {
+				m[list[i].ID] = &list[i]
+			}
+			return m, err
+		}),
+		sectionsByInstructor: newLoader("graphql_sections_by_instructor", func(ctx context.Context, ids []string) (map[string][]types.Section, error) {
+			sections, err := db.GetSectionsByInstructors(ctx, ids)
+			want := make(map[string]bool, len(ids))
+			for _, id := range ids {
+				want[id] = true
+			}
+			m := make(map[string][]types.Section)
+			for _, s := range sections {
+				for _, id := range s.InstructorIDs {
+					if want[id] {
+						m[id] = append(m[id], s)
+					}
+				}
+			}
+			return m, err
+		}),
+	}
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// admin overrides, read once per request
+// best effort like in the REST handlers, nil (none) if they can't be read
+func overridesFrom(ctx context.Context) *availability.Overrides {
+	l := loadersFrom(ctx)
+	o, _ := l.overrides.Get(func() (*availability.Overrides, error) {
+		list, err := db.GetActiveOverrides(ctx, l.now)
+		if err != nil {
+			slog.WarnContext(ctx, "graphql: reading overrides", "err", err)
+			return nil, nil
+		}
+		return availability.NewOverrides(list, l.now), nil
+	})
+	return o
+}
+
+// time argument or the request time
+func atOr(ctx context.Context, at *graphql.Time) time.Time {
+	if at != nil {
+		return at.Time
+	}
+	return loadersFrom(ctx).now
+}
+
+type Resolver struct{}
+
+func (*Resolver) Buildings(ctx context.Context) []*buildingResolver {
+	overrides := overridesFrom(ctx)
+	codes := make([]string, 0, len(types.Buildings))
+	for code := range types.Buildings {
+		if !overrides.Hidden(code) {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+
+	list := make([]*buildingResolver, len(codes))
+	for i, code := range codes {
+		list[i] = &buildingResolver{code: code, info: types.Buildings[code]}
+	}
+	return list
+}
+
+func (*Resolver) Building(ctx context.Context, args struct{ Code string }) *buildingResolver {
+	code := strings.ToUpper(args.Code)
+	if overridesFrom(ctx).Hidden(code) {
+		return nil
+	}
+	return newBuildingResolver(code)
+}
+
+func (*Resolver) Room(ctx context.Context, args struct{ ID graphql.ID }) (*roomResolver, error) {
+	room, err := loadersFrom(ctx).rooms.Load(ctx, string(args.ID))
+	if err != nil || room == nil || overridesFrom(ctx).Hidden(room.Building) {
+		return nil, err
+	}
+	return &roomResolver{room: *room}, nil
+}
+
+func (*Resolver) Section(ctx context.Context, args struct{ CRN string }) (*sectionResolver, error) {
+	section, err := loadersFrom(ctx).sections.Load(ctx, args.CRN)
+	if err != nil || section == nil {
+		return nil, err
+	}
+	return &sectionResolver{section: *section}, nil
+}
+
+func (*Resolver) Instructor(ctx context.Context, args struct{ ID graphql.ID }) (*instructorResolver, error) {
+	inst, err := loadersFrom(ctx).instructors.Load(ctx, string(args.ID))
+	if err != nil || inst == nil {
+		return nil, err
+	}
+	return &instructorResolver{instructor: *inst}, nil
+}
+
+type buildingResolver struct {
+	code string
+	info types.BuildingInfo
+}
+
+// nil for buildings we have no map data for
+func newBuildingResolver(code string) *buildingResolver {
+	info, ok := types.Buildings[code]
+	if !ok {
+		return nil
+	}
+	return &buildingResolver{code: code, info: info}
+}
+
+func (b *buildingResolver) Code() string { return b.code }
+func (b *buildingResolver) Name() string { return b.info.Name }
+func (b *buildingResolver) Lat() float64 { return b.info.Lat }
+func (b *buildingResolver) Lng() float64 { return b.info.Lng }
+
+func (b *buildingResolver) Rooms(ctx context.Context, args struct {
+	Free *bool
+	At   *graphql.Time
+}) ([]*roomResolver, error) {
+	rooms, err := loadersFrom(ctx).roomsByBuilding.Load(ctx, b.code)
+	if err != nil {
+		return nil, err
+	}
+	at := atOr(ctx, args.At)
+	overrides := overridesFrom(ctx)
+	if overrides.Hidden(b.code) {
+		return []*roomResolver{}, nil
+	}
+	list := make([]*roomResolver, 0, len(rooms))
+	for _, r := range rooms {
+		if args.Free != nil && overrides.State(r, at).Free != *args.Free {
+			continue
+		}
+		list = append(list, &roomResolver{room: r})
+	}
+	return list, nil
+}
+
+type roomResolver struct {
+	room types.Room
+}
+
+func (r *roomResolver) ID() graphql.ID              { return graphql.ID(r.room.ID) }
+func (r *roomResolver) Number() string              { return r.room.Number }
+func (r *roomResolver) Capacity() int32             { return int32(r.room.Capacity) }
+func (r *roomResolver) Building() *buildingResolver { return newBuildingResolver(r.room.Building) }
+
+func (r *roomResolver) Availability(ctx context.Context, args struct{ At *graphql.Time }) *availabilityResolver {
+	at := atOr(ctx, args.At)
+	return &availabilityResolver{at: at, state: overridesFrom(ctx).State(r.room, at)}
+}
+
+func (r *roomResolver) Meetings(args struct{ Day *int32 }) []*meetingResolver {
+	list := make([]*meetingResolver, 0, len(r.room.Schedule))
+	for _, m := range r.room.Schedule {
+		if args.Day != nil && int32(m.Day) != *args.Day {
+			continue
+		}
+		list = append(list, &meetingResolver{meeting: m})
+	}
+	return list
+}
+
+// crowd-sourced reports are read once per request for every room
+func (r *roomResolver) Reports(ctx context.Context) (*reportResolver, error) {
+	l := loadersFrom(ctx)
+	reports, err := l.reports.Get(func() (map[string][]types.Report, error) {
+		return db.GetActiveReports(ctx, l.now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	summary := availability.SummarizeReports(reports[r.room.ID], l.now)
+	if summary == nil {
+		return nil, nil
+	}
+	return &reportResolver{summary: *summary}, nil
+}
+
+type availabilityResolver struct {
+	at    time.Time
+	state availability.State
+}
+
+func (a *availabilityResolver) At() graphql.Time { return graphql.Time{Time: a.at} }
+func (a *availabilityResolver) Free() bool       { return a.state.Free }
+
+func (a *availabilityResolver) FreeUntil() *graphql.Time { return optionalTime(a.state.FreeUntil) }
+func (a *availabilityResolver) BusyUntil() *graphql.Time { return optionalTime(a.state.BusyUntil) }
+
+func optionalTime(t time.Time) *graphql.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &graphql.Time{Time: t}
+}
+
+type meetingResolver struct {
+	meeting types.Meeting
+}
+
+func (m *meetingResolver) Day() int32       { return int32(m.meeting.Day) }
+func (m *meetingResolver) StartTime() int32 { return int32(m.meeting.StartTime) }
+func (m *meetingResolver) EndTime() int32   { return int32(m.meeting.EndTime) }
+
+func (m *meetingResolver) Sections(ctx context.Context) ([]*sectionResolver, error) {
+	crns := make([]string, len(m.meeting.Label))
+	for i, label := range m.meeting.Label {
+		crns[i] = label.ID
+	}
+	// one batch for all of them, not a round per section
+	sections, err := loadersFrom(ctx).sections.LoadMany(ctx, crns)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*sectionResolver, 0, len(sections))
+	for _, section := range sections {
+		if section != nil {
+			list = append(list, &sectionResolver{section: *section})
+		}
+	}
+	return list, nil
+}
+
+type reportResolver struct {
+	summary types.ReportSummary
+}
+
+func (r *reportResolver) Status() string      { return r.summary.Status }
+func (r *reportResolver) Confidence() float64 { return r.summary.Confidence }
+func (r *reportResolver) Count() int32        { return int32(r.summary.Count) }
+func (r *reportResolver) LastReportedAt() graphql.Time {
+	return graphql.Time{Time: r.summary.LastReportedAt}
+}
+
+type sectionResolver struct {
+	section types.Section
+}
+
+func (s *sectionResolver) CRN() string      { return s.section.CRN }
+func (s *sectionResolver) CourseID() string { return s.section.CourseID }
+func (s *sectionResolver) Section() string  { return s.section.SequenceNumber }
+func (s *sectionResolver) Title() string    { return s.section.Title }
+
+func (s *sectionResolver) Instructors() []*instructorResolver {
+	list := make([]*instructorResolver, len(s.section.Instructors))
+	for i, inst := range s.section.Instructors {
+		list[i] = &instructorResolver{instructor: inst}
+	}
+	return list
+}
+
+func (s *sectionResolver) Meetings() []*sectionMeetingResolver {
+	list := make([]*sectionMeetingResolver, len(s.section.Meetings))
+	for i, m := range s.section.Meetings {
+		list[i] = &sectionMeetingResolver{meeting: m}
+	}
+	return list
+}
+
+type sectionMeetingResolver struct {
+	meeting types.SectionMeeting
+}
+
+func (m *sectionMeetingResolver) Days() []int32 {
+	days := make([]int32, len(m.meeting.Days))
+	for i, d := range m.meeting.Days {
+		days[i] = int32(d)
+	}
+	return days
+}
+func (m *sectionMeetingResolver) StartTime() int32 { return int32(m.meeting.StartTime) }
+func (m *sectionMeetingResolver) EndTime() int32   { return int32(m.meeting.EndTime) }
+func (m *sectionMeetingResolver) Location() string { return m.meeting.Location }
+
+// nil for TBA and online meetings
+func (m *sectionMeetingResolver) Room(ctx context.Context) (*roomResolver, error) {
+	if m.meeting.RoomID == "" {
+		return nil, nil
+	}
+	room, err := loadersFrom(ctx).rooms.Load(ctx, m.meeting.RoomID)
+	if err != nil || room == nil {
+		return nil, err
+	}
+	return &roomResolver{room: *room}, nil
+}
+
+type instructorResolver struct {
+	instructor types.Instructor
+}
+
+func (i *instructorResolver) ID() graphql.ID { return graphql.ID(i.instructor.ID) }
+func (i *instructorResolver) Name() string   { return i.instructor.Name }
+
+func (i *instructorResolver) Email() *string {
+	if i.instructor.Email == "" {
+		return nil
+	}
+	return &i.instructor.Email
+}
+
+func (i *instructorResolver) Sections(ctx context.Context) ([]*sectionResolver, error) {
+	sections, err := loadersFrom(ctx).sectionsByInstructor.Load(ctx, i.instructor.ID)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*sectionResolver, len(sections))
+	for j, s := range sections {
+		list[j] = &sectionResolver{section: s}
+	}
+	return list, nil
+}
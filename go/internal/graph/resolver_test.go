@@ -0,0 +1,117 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/availability"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// one query through the real schema, firestore swapped out for counting fakes
+func TestQuery(t *testing.T) {
+	wideBatch(t)
+	now := time.Date(2026, 1, 20, 10, 0, 0, 0, availability.Campus) // a tuesday
+
+	rooms := &fakeFetch{}
+	sections := &fakeFetch{}
+	l := &loaders{
+		now: now,
+		roomsByBuilding: newLoader("test_rooms_by_building", func(ctx context.Context, codes []string) (map[string][]types.Room, error) {
+			rooms.fetch(ctx, codes)
+			return map[string][]types.Room{"HORIZN": {
+				{ID: "HORIZN_2014", Building: "HORIZN", Number: "2014", Schedule: []types.Meeting{
+					{Day: 2, StartTime: 9 * 60, EndTime: 10*60 + 15, Label: []types.MeetingInfo{{ID: "10492"}, {ID: "10493"}}},
+					{Day: 4, StartTime: 9 * 60, EndTime: 10*60 + 15, Label: []types.MeetingInfo{{ID: "10492"}, {ID: "99999"}}},
+				}},
+				{ID: "HORIZN_2016", Building: "HORIZN", Number: "2016", Schedule: []types.Meeting{
+					{Day: 2, StartTime: 13 * 60, EndTime: 14 * 60, Label: []types.MeetingInfo{{ID: "20001"}}},
+				}},
+			}}, nil
+		}),
+		sections: newLoader("test_sections", func(ctx context.Context, crns []string) (map[string]*types.Section, error) {
+			sections.fetch(ctx, crns)
+			m := map[string]*types.Section{}
+			for _, crn := range crns {
+				if crn != "99999" { // a label whose section is gone
+					m[crn] = &types.Section{CRN: crn, Title: "Title " + crn}
+				}
+			}
+			return m, nil
+		}),
+	}
+	// reports and overrides are already read, so the query never reaches firestore
+	l.reports.Get(func() (map[string][]types.Report, error) {
+		return map[string][]types.Report{"HORIZN_2016": {{RoomID: "HORIZN_2016", Status: types.ReportAvailable, ReportedAt: now.Add(-5 * time.Minute)}}}, nil
+	})
+	l.overrides.Get(func() (*availability.Overrides, error) { return availability.NewOverrides(nil, now), nil })
+
+	ctx := context.WithValue(context.Background(), loadersKey{}, l)
+	resp := schema.Exec(ctx, `{
+		building(code: "horizn") {
+			rooms {
+				id
+				availability { free }
+				reports { status count }
+				meetings { day sections { crn title } }
+			}
+		}
+	}`, "", nil)
+	if len(resp.Errors) > 0 {
+		t.Fatal(resp.Errors)
+	}
+
+	var got struct {
+		Building struct {
+			Rooms []struct {
+				ID           string
+				Availability struct{ Free bool }
+				Reports      *struct {
+					Status string
+					Count  int
+				}
+				Meetings []struct {
+					Day      int
+					Sections []struct{ CRN, Title string }
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(resp.Data, &got); err != nil {
+		t.Fatal(err)
+	}
+	rs := got.Building.Rooms
+	if len(rs) != 2 || rs[0].ID != "HORIZN_2014" || rs[1].ID != "HORIZN_2016" {
+		t.Fatalf("rooms %+v", rs)
+	}
+	if rs[0].Availability.Free || !rs[1].Availability.Free {
+		t.Errorf("availability %+v %+v, want 2014 in class and 2016 free", rs[0].Availability, rs[1].Availability)
+	}
+	if rs[0].Reports != nil || rs[1].Reports == nil || rs[1].Reports.Status != types.ReportAvailable || rs[1].Reports.Count != 1 {
+		t.Errorf("reports %+v %+v", rs[0].Reports, rs[1].Reports)
+	}
+	var crns []string
+	for _, m := range rs[0].Meetings {
+		for _, s := range m.Sections {
+			if s.Title != "Title "+s.CRN {
+				t.Errorf("section %+v", s)
+			}
+			crns = append(crns, s.CRN)
+		}
+	}
+	// the missing section is dropped, the rest keep their label order
+	if want := []string{"10492", "10493", "10492"}; !slices.Equal(crns, want) {
+		t.Errorf("2014 sections %v, want %v", crns, want)
+	}
+
+	// one read for the building, one for every section on every meeting
+	if got := rooms.rounds(); len(got) != 1 {
+		t.Errorf("rooms fetched %v, want one round", got)
+	}
+	if got := sections.rounds(); len(got) != 1 || !slices.Equal(got[0], []string{"10492", "10493", "20001", "99999"}) {
+		t.Errorf("sections fetched %v, want one round of every crn", got)
+	}
+}
@@ -0,0 +1,68 @@
+package logging
+
+// structured logging on top of log/slog
+// Init swaps the default logger, so plain log.Printf calls from libraries end up in the same
+// output. anything logged with a *Context function picks up the request ID set by middleware.RequestID
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+type requestIDKey struct{}
+
+// sets up the default logger from LOG_LEVEL (debug, info, warn, error) and LOG_FORMAT (json, text)
+// the format defaults to json in prod (DEV=false) for the log collector and text locally
+func Init() {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(os.Getenv("LOG_LEVEL"))); err != nil {
+		level = slog.LevelInfo
+	}
+
+	format := strings.ToLower(os.Getenv("LOG_FORMAT"))
+	if format == "" {
+		format = "text"
+		if os.Getenv("DEV") == "false" {
+			format = "json"
+		}
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var h slog.Handler = slog.NewTextHandler(os.Stderr, opts)
+	if format == "json" {
+		h = slog.NewJSONHandler(os.Stderr, opts)
+	}
+	slog.SetDefault(slog.New(contextHandler{h}))
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// "" outside of a request
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// adds the request ID from the context to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
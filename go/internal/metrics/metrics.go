@@ -0,0 +1,109 @@
+package metrics
+
+// prometheus metrics, served at GET /metrics
+// the scraper records into the same collectors, run standalone it pushes them to a
+// pushgateway at the end of a run (see Push)
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+var (
+	RequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ghost_http_request_duration_seconds",
+		Help:    "HTTP request latency by route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	StoreReads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ghost_store_reads_total",
+		Help: "Documents read from the store by collection.",
+	}, []string{"collection"})
+
+	CacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ghost_cache_requests_total",
+		Help: "Cache lookups by cache and result (hit or miss).",
+	}, []string{"cache", "result"})
+
+	ScrapePages = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "ghost_scrape_pages_total",
+		Help: "Banner result pages fetched.",
+	})
+	ScrapeSections = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "ghost_scrape_sections_total",
+		Help: "Sections parsed from banner.",
+	})
+	ScrapeRooms = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "ghost_scrape_rooms",
+		Help: "Rooms found by the last scrape.",
+	})
+	ScrapeErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ghost_scrape_errors_total",
+		Help: "Scrape errors by stage.",
+	}, []string{"stage"})
+	ScrapeDuration = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "ghost_scrape_duration_seconds",
+		Help: "How long the last scrape took.",
+	})
+	ScrapeLastSuccess = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "ghost_scrape_last_success_timestamp_seconds",
+		Help: "Unix time of the last scrape that finished.",
+	})
+)
+
+// counts n documents read from collection
+func Reads(collection string, n int) {
+	StoreReads.WithLabelValues(collection).Add(float64(n))
+}
+
+func CacheHit(cache string)  { CacheRequests.WithLabelValues(cache, "hit").Inc() }
+func CacheMiss(cache string) { CacheRequests.WithLabelValues(cache, "miss").Inc() }
+
+// records the latency of every request
+// routes are labeled with their pattern (/api/rooms/:id) so IDs don't blow up cardinality
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		RequestDuration.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
+
+// GET /metrics
+// if METRICS_TOKEN is set the scraper has to send it as a bearer token
+func Handler() gin.HandlerFunc {
+	h := promhttp.Handler()
+	token := os.Getenv("METRICS_TOKEN")
+	return func(c *gin.Context) {
+		if token != "" && c.GetHeader("Authorization") != "Bearer "+token {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// pushes everything to PUSHGATEWAY_URL, for the standalone scraper which exits before prometheus could scrape it
+// does nothing if PUSHGATEWAY_URL is not set
+func Push(job string) error {
+	url := os.Getenv("PUSHGATEWAY_URL")
+	if url == "" {
+		return nil
+	}
+	return push.New(url, job).Gatherer(prometheus.DefaultGatherer).Push()
+}
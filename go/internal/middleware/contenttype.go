@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rejects request bodies that aren't application/json with 415
+// ShouldBindJSON doesn't look at the Content-Type, and a text/plain form POST is one
+// another site can send without a CORS preflight. requests without a body pass
+func RequireJSON() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength == 0 {
+			c.Next()
+			return
+		}
+		if t, _, err := mime.ParseMediaType(c.GetHeader("Content-Type")); err != nil || t != "application/json" {
+			c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{"error": "request body must be application/json"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequireJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/x", RequireJSON(), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		want        int
+	}{
+		{"json", `{}`, "application/json", http.StatusNoContent},
+		{"json with charset", `{}`, "application/json; charset=utf-8", http.StatusNoContent},
+		{"no body", "", "", http.StatusNoContent},
+		{"form", "a=b", "application/x-www-form-urlencoded", http.StatusUnsupportedMediaType},
+		{"text", `{}`, "text/plain", http.StatusUnsupportedMediaType},
+		{"no content type", `{}`, "", http.StatusUnsupportedMediaType},
+		{"garbage", `{}`, "application/json;;", http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/x", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("got %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+// writes an error response and stops the chain
+// middleware that rejects requests takes one so it answers in the error format of the API it guards
+type ErrorWriter func(c *gin.Context, status int, message string)
+
+// the v1 error body, {"error": "too many requests"}
+func JSONError(c *gin.Context, status int, message string) {
+	c.AbortWithStatusJSON(status, gin.H{"error": message})
+}
@@ -0,0 +1,133 @@
+package middleware
+
+// token bucket rate limiting for the API
+// buckets live in a Store so the in-memory one can be swapped for a shared one
+// (redis, memorystore...) once we run more than one instance
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// a bucket refills at Rate tokens per second up to Burst tokens
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// per minute helper so route definitions read nicely
+func PerMinute(n int, burst int) Limit {
+	return Limit{Rate: float64(n) / 60, Burst: burst}
+}
+
+// backend that keeps track of buckets
+// Take consumes one token from the bucket of every key, or from none of them if any is empty,
+// and returns whether it was allowed and if not, how long until every bucket has a token
+type Store interface {
+	Take(keys []string, limit Limit, now time.Time) (bool, time.Duration)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// in-memory Store, good enough for a single instance
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	idle    time.Duration
+	sweep   time.Time
+}
+
+// buckets that have not been touched for idle are dropped so the map doesn't grow forever
+func NewMemoryStore(idle time.Duration) *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		idle:    idle,
+	}
+}
+
+func (s *MemoryStore) Take(keys []string, limit Limit, now time.Time) (bool, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cleanup(now)
+
+	// refill every bucket first, a rejection must not cost the buckets that had a token
+	list := make([]*bucket, len(keys))
+	var wait time.Duration
+	for i, key := range keys {
+		b, ok := s.buckets[key]
+		if !ok {
+			b = &bucket{tokens: float64(limit.Burst), last: now}
+			s.buckets[key] = b
+		}
+		// refill based on the time since the last request
+		elapsed := now.Sub(b.last).Seconds()
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*limit.Rate)
+		b.last = now
+		if b.tokens < 1 {
+			wait = max(wait, time.Duration((1-b.tokens)/limit.Rate*float64(time.Second)))
+		}
+		list[i] = b
+	}
+	if wait > 0 {
+		return false, wait
+	}
+	for _, b := range list {
+		b.tokens--
+	}
+	return true, 0
+}
+
+// drops idle buckets, at most once per idle period
+func (s *MemoryStore) cleanup(now time.Time) {
+	if now.Sub(s.sweep) < s.idle {
+		return
+	}
+	s.sweep = now
+	for k, b := range s.buckets {
+		if now.Sub(b.last) > s.idle {
+			delete(s.buckets, k)
+		}
+	}
+}
+
+// extracts the identity a bucket belongs to
+// returning "" skips that key for the request
+type KeyFunc func(c *gin.Context) string
+
+// buckets by client IP
+// NOTE: set TRUSTED_PROXIES so gin doesn't trust a spoofed X-Forwarded-For
+func ByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// rejects the request with 429 through reject if any of the buckets for the request is empty
+// every key gets its own bucket with the same limit, so a client can't dodge
+// the limit by rotating device tokens or IPs alone, and a rejected request takes from none of them,
+// so a device emptying its own bucket doesn't drain the IP bucket it shares with a whole NAT
+func RateLimit(store Store, scope string, limit Limit, reject ErrorWriter, keys ...KeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var list []string
+		for _, key := range keys {
+			if k := key(c); k != "" {
+				list = append(list, scope+":"+k)
+			}
+		}
+		if len(list) > 0 {
+			if ok, wait := store.Take(list, limit, time.Now()); !ok {
+				c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				reject(c, http.StatusTooManyRequests, "too many requests")
+				return
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	s := NewMemoryStore(time.Hour)
+	limit := PerMinute(60, 3) // a token a second, 3 at once
+	now := time.Date(2026, 1, 20, 12, 0, 0, 0, time.UTC)
+	key := []string{"ip:1"}
+
+	// the burst goes through right away, then one more a second
+	for i := range 3 {
+		if ok, _ := s.Take(key, limit, now); !ok {
+			t.Fatalf("request %d of the burst rejected", i+1)
+		}
+	}
+	ok, wait := s.Take(key, limit, now)
+	if ok || wait != time.Second {
+		t.Errorf("past the burst: ok %v, wait %s, want a rejection for 1s", ok, wait)
+	}
+	if ok, wait := s.Take(key, limit, now.Add(400*time.Millisecond)); ok || wait != 600*time.Millisecond {
+		t.Errorf("0.4s later: ok %v, wait %s, want a rejection for 0.6s", ok, wait)
+	}
+	if ok, _ := s.Take(key, limit, now.Add(time.Second)); !ok {
+		t.Error("rejected after a token refilled")
+	}
+
+	// a long pause only refills up to the burst
+	later := now.Add(time.Hour - time.Second)
+	for range 3 {
+		s.Take(key, limit, later)
+	}
+	if ok, _ := s.Take(key, limit, later); ok {
+		t.Error("refilled past the burst")
+	}
+
+	// other keys have buckets of their own
+	if ok, _ := s.Take([]string{"ip:2"}, limit, later); !ok {
+		t.Error("another key was rejected")
+	}
+}
+
+func TestMemoryStoreTakeAll(t *testing.T) {
+	s := NewMemoryStore(time.Hour)
+	limit := PerMinute(60, 2)
+	now := time.Date(2026, 1, 20, 12, 0, 0, 0, time.UTC)
+
+	// device a empties its own bucket at home, then hammers on from campus
+	for range 2 {
+		s.Take([]string{"ip:home", "device:a"}, limit, now)
+	}
+	for range 10 {
+		if ok, _ := s.Take([]string{"ip:nat", "device:a"}, limit, now); ok {
+			t.Fatal("device a went past its bucket")
+		}
+	}
+	// which didn't cost the campus ip anything, the others behind it still get its whole burst
+	for _, device := range []string{"device:b", "device:c"} {
+		if ok, _ := s.Take([]string{"ip:nat", device}, limit, now); !ok {
+			t.Errorf("%s behind the same ip was rejected", device)
+		}
+	}
+
+	// the wait is for the emptiest bucket
+	if ok, wait := s.Take([]string{"ip:nat", "device:d"}, limit, now.Add(500*time.Millisecond)); ok || wait != 500*time.Millisecond {
+		t.Errorf("ok %v, wait %s, want a rejection for 0.5s", ok, wait)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := NewMemoryStore(time.Hour)
+	byHeader := func(c *gin.Context) string { return c.GetHeader("X-Key") }
+
+	r := gin.New()
+	r.GET("/x", RateLimit(store, "test", PerMinute(1, 1), JSONError, ByIP, byHeader), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	get := func(ip, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/x", nil)
+		req.RemoteAddr = ip + ":1234"
+		if key != "" {
+			req.Header.Set("X-Key", key)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get("192.0.2.1", "a"); w.Code != http.StatusNoContent {
+		t.Fatalf("first request: %d", w.Code)
+	}
+	// a token a minute, so the retry is a minute out
+	w := get("192.0.2.1", "a")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "60" {
+		t.Errorf("second request: %d, Retry-After %q, want 429 after 60", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w.Body.String() != `{"error":"too many requests"}` {
+		t.Errorf("body %s", w.Body)
+	}
+	// a new key on an empty ip is still rejected, and so is the old key on a new ip
+	if w := get("192.0.2.1", "b"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("new key, same ip: %d", w.Code)
+	}
+	if w := get("192.0.2.2", "a"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("same key, new ip: %d", w.Code)
+	}
+	// a key func returning "" is skipped
+	if w := get("192.0.2.3", ""); w.Code != http.StatusNoContent {
+		t.Errorf("without a key: %d", w.Code)
+	}
+}
@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+)
+
+// sends plain text emails through an SMTP relay
+type SMTP struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// configured with SMTP_HOST, SMTP_PORT, SMTP_USER, SMTP_PASS, SMTP_FROM
+// returns false if SMTP_HOST is not set
+func NewSMTPFromEnv() (*SMTP, bool) {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return nil, false
+	}
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "ghost@localhost"
+	}
+
+	var auth smtp.Auth
+	if user := os.Getenv("SMTP_USER"); user != "" {
+		auth = smtp.PlainAuth("", user, os.Getenv("SMTP_PASS"), host)
+	}
+	return &SMTP{addr: net.JoinHostPort(host, port), auth: auth, from: from}, true
+}
+
+// NOTE: net/smtp doesn't take a context, the relay's own timeouts apply
+func (s *SMTP) Notify(ctx context.Context, target string, msg Message) error {
+	if strings.ContainsAny(target, "\r\n") {
+		return fmt.Errorf("invalid email address")
+	}
+	body := strings.Join([]string{
+		"From: " + s.from,
+		"To: " + target,
+		"Subject: " + msg.Title,
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		msg.Body,
+	}, "\r\n")
+	return smtp.SendMail(s.addr, s.auth, s.from, []string{target}, []byte(body))
+}
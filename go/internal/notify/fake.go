@@ -0,0 +1,34 @@
+package notify
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// a sent message, as recorded by Fake
+type Sent struct {
+	Target  string
+	Message Message
+}
+
+// notifier that only logs and remembers what it would have sent
+type Fake struct {
+	mu   sync.Mutex
+	sent []Sent
+}
+
+func (f *Fake) Notify(ctx context.Context, target string, msg Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, Sent{Target: target, Message: msg})
+	slog.Info("notify (fake)", "title", msg.Title, "target", target)
+	return nil
+}
+
+// returns everything sent so far
+func (f *Fake) Sent() []Sent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Sent(nil), f.sent...)
+}
@@ -0,0 +1,92 @@
+package notify
+
+// notifications for watched rooms
+// a Notifier delivers a message over one channel (email, webhook, web push),
+// the Scheduler in scheduler.go decides when to send them
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/availability"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// what gets sent when a watched room is about to free up
+type Message struct {
+	RoomID string    `json:"room_id"`
+	FreeAt time.Time `json:"free_at"`
+	Title  string    `json:"title"`
+	Body   string    `json:"body"`
+}
+
+// delivers a message to target (email address, webhook URL, push subscription...)
+type Notifier interface {
+	Notify(ctx context.Context, target string, msg Message) error
+}
+
+// configured notifiers by channel
+var notifiers = map[string]Notifier{}
+
+// sets up the channels we have config for
+// NOTIFY_FAKE=true routes every channel to the log, handy for local dev
+func Init() {
+	if os.Getenv("NOTIFY_FAKE") == "true" {
+		fake := &Fake{}
+		for _, ch := range []string{types.ChannelEmail, types.ChannelWebhook, types.ChannelPush} {
+			notifiers[ch] = fake
+		}
+		slog.Info("notifications: using fake notifier")
+		return
+	}
+
+	notifiers[types.ChannelWebhook] = NewWebhook()
+
+	if n, ok := NewSMTPFromEnv(); ok {
+		notifiers[types.ChannelEmail] = n
+	}
+	if n, ok := NewWebPushFromEnv(); ok {
+		notifiers[types.ChannelPush] = n
+	}
+
+	var enabled []string
+	for ch := range notifiers {
+		enabled = append(enabled, ch)
+	}
+	slog.Info("notifications: channels enabled", "channels", enabled)
+}
+
+// swaps the notifier for a channel, mostly for tests
+func Register(channel string, n Notifier) {
+	notifiers[channel] = n
+}
+
+// returns true if the channel can deliver messages
+func Enabled(channel string) bool {
+	_, ok := notifiers[channel]
+	return ok
+}
+
+// sends msg over the watch's channel
+func Send(ctx context.Context, watch types.Watch, msg Message) error {
+	n, ok := notifiers[watch.Channel]
+	if !ok {
+		return fmt.Errorf("notification channel %q is not configured", watch.Channel)
+	}
+	return n.Notify(ctx, watch.Target, msg)
+}
+
+// builds the message for a room that frees up at freeAt
+// the time in the body is campus time, whatever zone freeAt is in (overrides are stored in UTC)
+func NewMessage(room types.Room, freeAt time.Time) Message {
+	name := room.Building + " " + room.Number
+	return Message{
+		RoomID: room.ID,
+		FreeAt: freeAt,
+		Title:  name + " is about to free up",
+		Body:   fmt.Sprintf("%s is free from %s.", name, freeAt.In(availability.Campus).Format("3:04 PM")),
+	}
+}
@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/availability"
+	db "github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// classes start and end on minute boundaries, so checking once a minute is enough
+const tickInterval = time.Minute
+
+// checks watched rooms every minute until ctx is cancelled
+func Run(ctx context.Context) {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := Tick(ctx, now); err != nil {
+				slog.Error("notify: tick", "err", err)
+			}
+		}
+	}
+}
+
+// sends a notification for every watch whose room frees up within its lead time
+// a watch is notified once per free window (tracked with NotifiedFor)
+func Tick(ctx context.Context, now time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	watches, err := db.GetWatches(ctx, "")
+	if err != nil {
+		return err
+	}
+	if len(watches) == 0 {
+		return nil
+	}
+
+	// read every watched room once
+	seen := make(map[string]bool)
+	var ids []string
+	for _, w := range watches {
+		if !seen[w.RoomID] {
+			seen[w.RoomID] = true
+			ids = append(ids, w.RoomID)
+		}
+	}
+	rooms, err := db.GetRoomsByID(ctx, ids)
+	if err != nil {
+		return err
+	}
+	// without them a closed room would still be announced as freeing up
+	list, err := db.GetActiveOverrides(ctx, now)
+	if err != nil {
+		return err
+	}
+	notifyDue(ctx, now, watches, rooms, availability.NewOverrides(list, now), db.MarkWatchNotified)
+	return nil
+}
+
+// sends what is due to each watch and marks it, split from Tick so it runs without firestore
+func notifyDue(ctx context.Context, now time.Time, watches []types.Watch, rooms []types.Room, overrides *availability.Overrides,
+	mark func(ctx context.Context, id string, freeAt time.Time) error) {
+	for _, room := range overrides.Apply(rooms) {
+		state := overrides.State(room, now)
+		// only busy rooms have an upcoming free window, closures without an end have none
+		if state.Free || state.BusyUntil.IsZero() {
+			continue
+		}
+		freeAt := state.BusyUntil
+
+		for _, w := range watches {
+			if w.RoomID != room.ID || w.NotifiedFor.Equal(freeAt) {
+				continue
+			}
+			// the room is free by the tick at freeAt, so a lead under a tick would never fire
+			// (watches made before lead_minutes had a minimum of 1 can have 0)
+			lead := max(time.Duration(w.LeadMinutes)*time.Minute, tickInterval)
+			if freeAt.Sub(now) > lead {
+				continue
+			}
+
+			if err := Send(ctx, w, NewMessage(room, freeAt)); err != nil {
+				slog.Error("notify: sending", "watch", w.ID, "channel", w.Channel, "err", err)
+				continue
+			}
+			if err := mark(ctx, w.ID, freeAt); err != nil {
+				slog.Error("notify: marking watch", "watch", w.ID, "err", err)
+			}
+		}
+	}
+}
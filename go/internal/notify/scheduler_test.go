@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/availability"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+func TestNotifyDue(t *testing.T) {
+	fake := &Fake{}
+	Register(types.ChannelWebhook, fake)
+	t.Cleanup(func() { delete(notifiers, types.ChannelWebhook) })
+
+	// tuesday 9:00-10:15 on campus
+	room := types.Room{ID: "HORIZN_2014", Building: "HORIZN", Number: "2014",
+		Schedule: []types.Meeting{{Day: 2, StartTime: 9 * 60, EndTime: 10*60 + 15}}}
+	freeAt := time.Date(2026, 1, 20, 10, 15, 0, 0, availability.Campus)
+	closed := types.Override{ID: "o1", Kind: types.OverrideClosed, RoomID: room.ID, Building: room.Building,
+		Start: freeAt.Add(-2 * time.Hour)}
+
+	tests := []struct {
+		name      string
+		lead      int
+		before    time.Duration // how long before freeAt the tick is
+		notified  time.Time
+		overrides []types.Override
+		sent      bool
+	}{
+		{"lead 0 in the last tick", 0, 30 * time.Second, time.Time{}, nil, true},
+		{"lead 0 earlier", 0, 90 * time.Second, time.Time{}, nil, false},
+		{"lead 1 on time", 1, time.Minute, time.Time{}, nil, true},
+		{"lead 1 early", 1, 90 * time.Second, time.Time{}, nil, false},
+		{"lead 5 on time", 5, 5 * time.Minute, time.Time{}, nil, true},
+		{"lead 5 inside the window", 5, 2 * time.Minute, time.Time{}, nil, true},
+		{"lead 5 early", 5, 6 * time.Minute, time.Time{}, nil, false},
+		{"already notified", 5, 2 * time.Minute, freeAt, nil, false},
+		{"notified for an earlier window", 5, 2 * time.Minute, freeAt.Add(-24 * time.Hour), nil, true},
+		{"free now", 5, -15 * time.Minute, time.Time{}, nil, false},
+		{"closure with no end", 5, 2 * time.Minute, time.Time{}, []types.Override{closed}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := len(fake.Sent())
+			now := freeAt.Add(-tt.before)
+			watch := types.Watch{ID: "w1", RoomID: room.ID, LeadMinutes: tt.lead, Channel: types.ChannelWebhook,
+				Target: "https://example.com/hook", NotifiedFor: tt.notified}
+
+			var marked []time.Time
+			mark := func(_ context.Context, id string, at time.Time) error {
+				marked = append(marked, at)
+				return nil
+			}
+			notifyDue(context.Background(), now, []types.Watch{watch}, []types.Room{room},
+				availability.NewOverrides(tt.overrides, now), mark)
+
+			sent := fake.Sent()[before:]
+			if (len(sent) == 1) != tt.sent || len(sent) > 1 {
+				t.Fatalf("sent %d messages, want sent %v", len(sent), tt.sent)
+			}
+			if !tt.sent {
+				if len(marked) > 0 {
+					t.Errorf("marked %v without sending", marked)
+				}
+				return
+			}
+			if sent[0].Message.RoomID != room.ID || sent[0].Target != watch.Target {
+				t.Errorf("sent %+v", sent[0])
+			}
+			if len(marked) != 1 || !marked[0].Equal(freeAt) {
+				t.Errorf("marked %v, want %s", marked, freeAt)
+			}
+		})
+	}
+}
+
+// other rooms' watches are left alone
+func TestNotifyDueOtherRooms(t *testing.T) {
+	fake := &Fake{}
+	Register(types.ChannelWebhook, fake)
+	t.Cleanup(func() { delete(notifiers, types.ChannelWebhook) })
+
+	room := types.Room{ID: "HORIZN_2014", Schedule: []types.Meeting{{Day: 2, StartTime: 9 * 60, EndTime: 10*60 + 15}}}
+	now := time.Date(2026, 1, 20, 10, 14, 0, 0, availability.Campus)
+	watches := []types.Watch{
+		{ID: "w1", RoomID: "ENGR_1103", LeadMinutes: 5, Channel: types.ChannelWebhook},
+		{ID: "w2", RoomID: room.ID, LeadMinutes: 5, Channel: types.ChannelWebhook},
+	}
+	var marked []string
+	notifyDue(context.Background(), now, watches, []types.Room{room}, availability.NewOverrides(nil, now),
+		func(_ context.Context, id string, _ time.Time) error {
+			marked = append(marked, id)
+			return nil
+		})
+	if len(marked) != 1 || marked[0] != "w2" {
+		t.Errorf("marked %v, want only w2", marked)
+	}
+}
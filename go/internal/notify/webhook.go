@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// POSTs the message as JSON to the target URL (discord/slack bridges, student bots...)
+// only public addresses are dialed and redirects aren't followed, see outbound.go
+type Webhook struct {
+	client *http.Client
+}
+
+func NewWebhook() *Webhook {
+	return &Webhook{client: newOutboundClient(10*time.Second, publicAddr)}
+}
+
+func (w *Webhook) Notify(ctx context.Context, target string, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ghost-notifier")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %d", resp.StatusCode)
+	}
+	return nil
+}
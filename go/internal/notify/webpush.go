@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/SherClockHolmes/webpush-go"
+)
+
+// sends browser push notifications, target is the PushSubscription JSON from the frontend
+type WebPush struct {
+	publicKey  string
+	privateKey string
+	subscriber string
+	// the endpoint comes from the subscription, so it gets the same checks as webhooks
+	client *http.Client
+}
+
+// configured with VAPID_PUBLIC_KEY, VAPID_PRIVATE_KEY and VAPID_SUBJECT
+// returns false if the keys are not set
+func NewWebPushFromEnv() (*WebPush, bool) {
+	pub, priv := os.Getenv("VAPID_PUBLIC_KEY"), os.Getenv("VAPID_PRIVATE_KEY")
+	if pub == "" || priv == "" {
+		return nil, false
+	}
+	return &WebPush{
+		publicKey:  pub,
+		privateKey: priv,
+		subscriber: os.Getenv("VAPID_SUBJECT"),
+		client:     newOutboundClient(10*time.Second, publicAddr),
+	}, true
+}
+
+func (w *WebPush) Notify(ctx context.Context, target string, msg Message) error {
+	var sub webpush.Subscription
+	if err := json.Unmarshal([]byte(target), &sub); err != nil {
+		return fmt.Errorf("invalid push subscription: %w", err)
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	resp, err := webpush.SendNotificationWithContext(ctx, payload, &sub, &webpush.Options{
+		Subscriber:      w.subscriber,
+		VAPIDPublicKey:  w.publicKey,
+		VAPIDPrivateKey: w.privateKey,
+		TTL:             300,
+		Urgency:         webpush.UrgencyHigh,
+		HTTPClient:      w.client,
+	})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("push service returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// checks that target is a usable push subscription
+func ValidSubscription(target string) bool {
+	var sub webpush.Subscription
+	if err := json.Unmarshal([]byte(target), &sub); err != nil {
+		return false
+	}
+	return sub.Endpoint != "" && sub.Keys.Auth != "" && sub.Keys.P256dh != ""
+}
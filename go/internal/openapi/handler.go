@@ -0,0 +1,24 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	docOnce sync.Once
+	docJSON []byte
+)
+
+// serves the spec
+// GET /api/openapi.json
+func Handler(c *gin.Context) {
+	docOnce.Do(func() {
+		docJSON, _ = json.Marshal(Document())
+	})
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Data(http.StatusOK, "application/json", docJSON)
+}
@@ -0,0 +1,174 @@
+package openapi
+
+// json schemas derived from the go types the handlers actually return
+// so changing a struct changes the spec, no hand written yaml to forget about
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// collects named struct schemas into components while walking types
+type registry struct {
+	schemas map[string]*Schema
+	types   map[string]reflect.Type // what each schema name was taken by
+}
+
+func newRegistry() *registry {
+	return &registry{schemas: map[string]*Schema{}, types: map[string]reflect.Type{}}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// returns the schema for t, registering named structs as components and referencing them
+func (r *registry) schemaFor(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := r.schemaFor(t.Elem())
+		if s.Ref != "" {
+			// $ref can't have siblings in openapi 3.0
+			return s
+		}
+		cp := *s
+		cp.Nullable = true
+		return &cp
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: r.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schemaFor(t.Elem())}
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Struct:
+		if t == timeType {
+			return &Schema{Type: "string", Format: "date-time"}
+		}
+		if t.Name() == "" {
+			return r.structSchema(t, false)
+		}
+		name := r.nameFor(t)
+		if _, ok := r.schemas[name]; !ok {
+			// placeholder first so recursive types terminate
+			r.schemas[name] = &Schema{}
+			*r.schemas[name] = *r.structSchema(t, false)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	return &Schema{}
+}
+
+// request bodies use binding:"required" for required fields,
+// responses treat everything without omitempty as always present
+func (r *registry) structSchema(t reflect.Type, request bool) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	r.addFields(s, t, request)
+	return s
+}
+
+func (r *registry) addFields(s *Schema, t reflect.Type, request bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, omitempty, skip := jsonField(f)
+		if skip {
+			continue
+		}
+
+		// embedded structs are flattened by encoding/json
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				r.addFields(s, ft, request)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		s.Properties[name] = r.schemaFor(f.Type)
+
+		required := !omitempty
+		if request {
+			required = strings.Contains(f.Tag.Get("binding"), "required")
+		}
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// name, omitempty, skip
+func jsonField(f reflect.StructField) (string, bool, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	omitempty := false
+	for _, p := range parts[1:] {
+		if p == "omitempty" {
+			omitempty = true
+		}
+	}
+	return parts[0], omitempty, false
+}
+
+var nonIdentRe = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// the component name for t, types with the same name in two packages (scraper.Report, types.Report)
+// get the package in front of the name once the first one has it, ex) "ScraperReport"
+func (r *registry) nameFor(t reflect.Type) string {
+	name := schemaName(t)
+	if other, ok := r.types[name]; ok && other != t {
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	r.types[name] = t
+	return name
+}
+
+// "PageResponse[github.com/.../api.RoomResponse]" -> "PageResponseRoomResponse"
+func schemaName(t reflect.Type) string {
+	name := t.Name()
+	if i := strings.Index(name, "["); i >= 0 {
+		base, args := name[:i], name[i+1:len(name)-1]
+		var out []string
+		for _, a := range strings.Split(args, ",") {
+			if j := strings.LastIndex(a, "."); j >= 0 {
+				a = a[j+1:]
+			}
+			out = append(out, a)
+		}
+		name = base + strings.Join(out, "")
+	}
+	return nonIdentRe.ReplaceAllString(name, "")
+}
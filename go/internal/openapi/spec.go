@@ -0,0 +1,337 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/api"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/graph"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/scraper"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// where an operation's auth comes from
+const (
+	authNone    = ""
+	authDevice  = "device"  // X-Device-Token header
+	authSession = "session" // ghost_session cookie
+	authMetrics = "metrics" // METRICS_TOKEN bearer token, only when set
+	authAdmin   = "admin"   // ADMIN_TOKEN bearer token or an ADMIN_EMAILS session
+)
+
+type Param struct {
+	Name        string
+	In          string // "query" | "path"
+	Type        string
+	Required    bool
+	Description string
+}
+
+// one documented route
+// Path uses gin syntax (:id), Response and Body are zero values of the go types
+type Operation struct {
+	Method   string
+	Path     string
+	Summary  string
+	Tag      string
+	Params   []Param
+	Body     any
+	Status   int
+	Response any
+	Auth     string
+	// Response is sent as server-sent events instead of one JSON body
+	Stream bool
+	// statuses other than Status and errors, and what they respond with
+	Other map[int]any
+	// media types when not application/json,
+	// ex) application/x-ndjson with one Body/Response value per line
+	BodyType     string
+	ResponseType string
+}
+
+func query(name, typ, desc string) Param {
+	return Param{Name: name, In: "query", Type: typ, Description: desc}
+}
+
+func path(name, desc string) Param {
+	return Param{Name: name, In: "path", Type: "string", Required: true, Description: desc}
+}
+
+type statusResponse struct {
+	Status string `json:"status"`
+}
+
+type infoResponse struct {
+	Message string `json:"message"`
+	Version string `json:"version"`
+}
+
+// one line of a dataset export, data depends on type, see store.Record
+type datasetRecord struct {
+	Type string         `json:"type"`
+	Data map[string]any `json:"data"`
+}
+
+// v1 error body, v2 uses api.ErrorResponse
+type errorV1 struct {
+	Error string `json:"error"`
+}
+
+var v2Page = []Param{
+	query("limit", "integer", "page size, 1 to 200 (default 50)"),
+	query("offset", "integer", "items to skip"),
+}
+
+var dayTime = []Param{
+	query("day", "integer", "day of week, 0 = sunday"),
+	query("time", "integer", "minutes since midnight, with day only keeps classes ongoing at that time"),
+}
+
+// every route the server registers, the test in cmd/main_test.go fails if this drifts from the router or the handlers
+var Operations = []Operation{
+	{Method: "GET", Path: "/health", Summary: "Health check", Tag: "meta", Response: statusResponse{}},
+	{Method: "GET", Path: "/", Summary: "API info", Tag: "meta", Response: infoResponse{}},
+	{Method: "GET", Path: "/metrics", Summary: "Prometheus metrics (text exposition format)", Tag: "meta", Auth: authMetrics},
+	{Method: "GET", Path: "/api/openapi.json", Summary: "This document", Tag: "meta", Response: map[string]any{}},
+
+	{Method: "GET", Path: "/auth/google", Summary: "Start Google sign-in (redirects)", Tag: "auth", Status: http.StatusTemporaryRedirect},
+	{Method: "GET", Path: "/auth/google/callback", Summary: "OAuth callback (redirects to the frontend)", Tag: "auth", Status: http.StatusFound},
+	{Method: "POST", Path: "/auth/logout", Summary: "End the current session", Tag: "auth", Status: http.StatusNoContent},
+
+	{Method: "GET", Path: "/api/buildings", Summary: "Building coordinates keyed by code", Tag: "v1", Response: map[string]types.BuildingInfo{}},
+	{Method: "GET", Path: "/api/room", Summary: "A room and its schedule", Tag: "v1", Response: types.Room{},
+		Params: []Param{{Name: "room", In: "query", Type: "string", Required: true, Description: "room ID, ex) HORIZN_2014"}}},
+	{Method: "GET", Path: "/api/rooms", Summary: "Rooms and their schedules", Tag: "v1", Response: []types.Room{},
+		Params: append([]Param{
+			query("building", "string", "building code, ex) HORIZN"),
+			query("sort", "string", "id, number, capacity or free_duration, prefix with - for descending"),
+			query("fields", "string", "comma separated subset of "+strings.Join(api.RoomFieldNames(), ", ")),
+			query("limit", "integer", "page size (max 200), every room when not set"),
+			query("cursor", "string", "X-Next-Cursor header of the previous page"),
+		}, dayTime...)},
+	{Method: "POST", Path: "/api/rooms/:id/reports", Summary: "Report a room's status", Tag: "v1", Auth: authDevice,
+		Params: []Param{path("id", "room ID")}, Body: api.ReportRequest{}, Status: http.StatusCreated, Response: types.Report{}},
+	{Method: "POST", Path: "/api/rooms/:id/holds", Summary: "Place a study group hold on a room", Tag: "v1", Auth: authSession,
+		Params: []Param{path("id", "room ID")}, Body: api.HoldRequest{}, Status: http.StatusCreated, Response: types.Hold{}},
+	{Method: "POST", Path: "/api/device", Summary: "Issue an anonymous device token", Tag: "v1", Status: http.StatusCreated, Response: api.DeviceResponse{}},
+	{Method: "GET", Path: "/api/courses", Summary: "Search courses by code, subject or title", Tag: "v1", Response: []types.Course{},
+		Params: []Param{{Name: "q", In: "query", Type: "string", Required: true, Description: "ex) CS 310"}}},
+	{Method: "GET", Path: "/api/sections/:crn", Summary: "A section with instructors and meetings", Tag: "v1", Response: types.Section{},
+		Params: []Param{path("crn", "course reference number")}},
+	{Method: "GET", Path: "/api/instructors/:id/schedule", Summary: "Where and when an instructor teaches", Tag: "v1", Response: api.InstructorScheduleResponse{},
+		Params: []Param{path("id", "instructor ID (email username)")}},
+	{Method: "GET", Path: "/api/search", Summary: "Search rooms, buildings, courses and instructors", Tag: "v1", Response: api.SearchResponse{},
+		Params: []Param{
+			{Name: "q", In: "query", Type: "string", Required: true},
+			query("type", "string", "building, room, course or instructor"),
+			query("limit", "integer", "1 to 100 (default 20)"),
+		}},
+	{Method: "GET", Path: "/api/stream", Summary: "Room state changes of a building as server-sent \"room\" events", Tag: "v1", Stream: true,
+		Params: []Param{{Name: "building", In: "query", Type: "string", Required: true, Description: "ex) HORIZN"}}, Response: types.RoomStatus{}},
+	{Method: "POST", Path: "/api/graphql", Summary: "GraphQL query, schema at internal/graph/schema.graphql", Tag: "v1",
+		Body: graph.Request{}, Response: graph.Response{}},
+
+	{Method: "GET", Path: "/api/me", Summary: "The signed in user", Tag: "me", Auth: authSession, Response: types.User{}},
+	{Method: "GET", Path: "/api/me/favorites", Summary: "Favorite rooms", Tag: "me", Auth: authSession, Response: []types.Favorite{}},
+	{Method: "PUT", Path: "/api/me/favorites/:room", Summary: "Add a favorite room", Tag: "me", Auth: authSession,
+		Params: []Param{path("room", "room ID")}, Status: http.StatusCreated, Response: types.Favorite{}},
+	{Method: "DELETE", Path: "/api/me/favorites/:room", Summary: "Remove a favorite room", Tag: "me", Auth: authSession,
+		Params: []Param{path("room", "room ID")}, Status: http.StatusNoContent},
+	{Method: "GET", Path: "/api/me/favorites/status", Summary: "Free/busy state of each favorite room", Tag: "me", Auth: authSession, Response: []types.RoomStatus{}},
+	{Method: "GET", Path: "/api/me/searches", Summary: "Saved searches", Tag: "me", Auth: authSession, Response: []types.SavedSearch{}},
+	{Method: "POST", Path: "/api/me/searches", Summary: "Save a search", Tag: "me", Auth: authSession,
+		Body: types.SavedSearch{}, Status: http.StatusCreated, Response: types.SavedSearch{}},
+	{Method: "DELETE", Path: "/api/me/searches/:id", Summary: "Delete a saved search", Tag: "me", Auth: authSession,
+		Params: []Param{path("id", "search ID")}, Status: http.StatusNoContent},
+	{Method: "GET", Path: "/api/me/watches", Summary: "Room watches", Tag: "me", Auth: authSession, Response: []types.Watch{}},
+	{Method: "POST", Path: "/api/me/watches", Summary: "Get notified before a room frees up", Tag: "me", Auth: authSession,
+		Body: api.WatchRequest{}, Status: http.StatusCreated, Response: types.Watch{}},
+	{Method: "DELETE", Path: "/api/me/watches/:id", Summary: "Delete a watch", Tag: "me", Auth: authSession,
+		Params: []Param{path("id", "watch ID")}, Status: http.StatusNoContent},
+	{Method: "GET", Path: "/api/me/holds", Summary: "Your active holds", Tag: "me", Auth: authSession, Response: []types.Hold{}},
+	{Method: "DELETE", Path: "/api/me/holds/:id", Summary: "Release a hold", Tag: "me", Auth: authSession,
+		Params: []Param{path("id", "hold ID")}, Status: http.StatusNoContent},
+
+	{Method: "GET", Path: "/api/v2/buildings", Summary: "Buildings", Tag: "v2", Params: v2Page, Response: api.PageResponse[api.BuildingResponse]{}},
+	{Method: "GET", Path: "/api/v2/buildings/:code", Summary: "A building", Tag: "v2",
+		Params: []Param{path("code", "building code")}, Response: api.BuildingResponse{}},
+	{Method: "GET", Path: "/api/v2/rooms", Summary: "Rooms with availability", Tag: "v2", Response: api.CursorPageResponse[api.RoomResponse]{},
+		Params: append([]Param{
+			query("building", "string", "building code"),
+			query("at", "string", "RFC 3339 time availability is computed for (default now)"),
+			query("limit", "integer", "page size, 1 to 200 (default 50)"),
+			query("cursor", "string", "next_cursor of the previous page"),
+		}, dayTime...)},
+	{Method: "GET", Path: "/api/v2/rooms/:id", Summary: "A room with availability", Tag: "v2", Response: api.RoomResponse{},
+		Params: []Param{path("id", "room ID"), query("at", "string", "RFC 3339 time availability is computed for (default now)")}},
+
+	{Method: "GET", Path: "/api/admin/dataset", Summary: "Export every room, section and instructor as NDJSON", Tag: "admin", Auth: authAdmin,
+		ResponseType: "application/x-ndjson", Response: datasetRecord{}},
+	{Method: "POST", Path: "/api/admin/dataset", Summary: "Replace the dataset with an export, 422 with the report if validation fails", Tag: "admin", Auth: authAdmin,
+		Params: []Param{query("force", "boolean", "import even if validation fails")}, BodyType: "application/x-ndjson",
+		Body: datasetRecord{}, Response: api.DatasetImportResponse{},
+		Other: map[int]any{http.StatusUnprocessableEntity: api.DatasetImportResponse{}}},
+	{Method: "GET", Path: "/api/admin/scrapes", Summary: "Scrape schedule, the running scrape and recent runs", Tag: "admin", Auth: authAdmin,
+		Params: []Param{query("limit", "integer", "runs to return, 1 to 100 (default 20)")}, Response: scraper.Status{}},
+	{Method: "GET", Path: "/api/admin/overrides", Summary: "Manual overrides, newest first", Tag: "admin", Auth: authAdmin,
+		Params: []Param{query("active", "boolean", "leave out overrides that have ended")}, Response: []types.Override{}},
+	{Method: "POST", Path: "/api/admin/overrides", Summary: "Block or close a room, add an event or hide a building", Tag: "admin", Auth: authAdmin,
+		Body: api.OverrideRequest{}, Status: http.StatusCreated, Response: types.Override{}},
+	{Method: "DELETE", Path: "/api/admin/overrides/:id", Summary: "Remove an override", Tag: "admin", Auth: authAdmin,
+		Params: []Param{path("id", "override ID")}, Status: http.StatusNoContent},
+	{Method: "GET", Path: "/api/admin/audit", Summary: "Audit log of data changes and admin actions, newest first", Tag: "admin", Auth: authAdmin,
+		Params: []Param{
+			query("room", "string", "room ID"),
+			query("building", "string", "building code"),
+			query("actor", "string", "admin email, token, scraper:TRIGGER, cli:USER@HOST or device:ID"),
+			query("action", "string", "ex) room.removed, override.created"),
+			query("ref", "string", "snapshot version, override or report ID"),
+			query("since", "string", "RFC 3339, inclusive"),
+			query("until", "string", "RFC 3339, exclusive"),
+			query("limit", "integer", "1 to 200 (default 50)"),
+			query("cursor", "string", "next_cursor of the previous page"),
+		}, Response: api.AuditResponse{}},
+}
+
+// the OpenAPI 3 document, built once
+func Document() map[string]any {
+	reg := newRegistry()
+	errV1 := reg.schemaFor(reflect.TypeOf(errorV1{}))
+	errV2 := reg.schemaFor(reflect.TypeOf(api.ErrorResponse{}))
+
+	paths := map[string]map[string]any{}
+	for _, op := range Operations {
+		p := specPath(op.Path)
+		if paths[p] == nil {
+			paths[p] = map[string]any{}
+		}
+
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		ok := map[string]any{"description": http.StatusText(status)}
+		if op.Response != nil {
+			ok["content"] = jsonContent(reg.schemaFor(reflect.TypeOf(op.Response)))
+		}
+		if op.Stream {
+			ok["content"] = map[string]any{"text/event-stream": map[string]any{"schema": reg.schemaFor(reflect.TypeOf(op.Response))}}
+		}
+		if op.ResponseType != "" {
+			ok["content"] = map[string]any{op.ResponseType: map[string]any{"schema": reg.schemaFor(reflect.TypeOf(op.Response))}}
+		}
+
+		responses := map[string]any{strconv.Itoa(status): ok}
+		for code, body := range op.Other {
+			responses[strconv.Itoa(code)] = map[string]any{
+				"description": http.StatusText(code),
+				"content":     jsonContent(reg.schemaFor(reflect.TypeOf(body))),
+			}
+		}
+
+		errSchema := errV1
+		if strings.HasPrefix(op.Path, "/api/v2/") {
+			errSchema = errV2
+		}
+		operation := map[string]any{
+			"summary":     op.Summary,
+			"tags":        []string{op.Tag},
+			"operationId": operationID(op),
+			"responses":   responses,
+		}
+
+		responses["default"] = map[string]any{"description": "error", "content": jsonContent(errSchema)}
+
+		if len(op.Params) > 0 {
+			var params []map[string]any
+			for _, prm := range op.Params {
+				params = append(params, map[string]any{
+					"name":        prm.Name,
+					"in":          prm.In,
+					"required":    prm.Required,
+					"description": prm.Description,
+					"schema":      &Schema{Type: prm.Type},
+				})
+			}
+			operation["parameters"] = params
+		}
+		if op.Body != nil {
+			content := jsonContent(reg.structSchema(reflect.TypeOf(op.Body), true))
+			if op.BodyType != "" {
+				content = map[string]any{op.BodyType: map[string]any{"schema": reg.structSchema(reflect.TypeOf(op.Body), true)}}
+			}
+			operation["requestBody"] = map[string]any{"required": true, "content": content}
+		}
+		switch op.Auth {
+		case authDevice:
+			operation["security"] = []map[string][]string{{"deviceToken": {}}}
+		case authSession:
+			operation["security"] = []map[string][]string{{"session": {}}}
+		case authMetrics:
+			operation["security"] = []map[string][]string{{"metricsToken": {}}}
+		case authAdmin:
+			operation["security"] = []map[string][]string{{"adminToken": {}}, {"session": {}}}
+		}
+
+		paths[p][strings.ToLower(op.Method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "GDG ghost map API",
+			"version": "2.0",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": reg.schemas,
+			"securitySchemes": map[string]any{
+				"deviceToken":  map[string]any{"type": "apiKey", "in": "header", "name": "X-Device-Token"},
+				"session":      map[string]any{"type": "apiKey", "in": "cookie", "name": "ghost_session"},
+				"metricsToken": map[string]any{"type": "http", "scheme": "bearer"},
+				"adminToken":   map[string]any{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+}
+
+func jsonContent(s *Schema) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": s}}
+}
+
+// "/api/rooms/:id/reports" -> "/api/rooms/{id}/reports"
+func specPath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, ":") {
+			parts[i] = "{" + part[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// "GET /api/v2/rooms/:id" -> "getApiV2RoomsById"
+func operationID(op Operation) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(op.Method))
+	if op.Path == "/" {
+		b.WriteString("Root")
+	}
+	for _, part := range strings.Split(op.Path, "/") {
+		if part == "" {
+			continue
+		}
+		if strings.HasPrefix(part, ":") {
+			b.WriteString("By")
+			part = part[1:]
+		}
+		for _, w := range nonIdentRe.Split(part, -1) {
+			if w != "" {
+				b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,182 @@
+package openapi
+
+// typescript client generated from the same Operations and go types as the spec
+// go run ./cmd/openapi -ts ../frontend/lib/api.ts
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// returns a typescript module with an interface per schema and a fetch function per operation
+func TypeScript() string {
+	reg := newRegistry()
+
+	type fn struct {
+		op       Operation
+		response string
+		body     string
+	}
+	var fns []fn
+	for _, op := range Operations {
+		if op.Tag == "auth" || op.Tag == "meta" || op.Tag == "admin" {
+			// browser redirects, meta and admin routes, not something the frontend fetches
+			continue
+		}
+		if op.Stream {
+			// read with an EventSource, fetch can't
+			continue
+		}
+		f := fn{op: op, response: "void"}
+		if op.Response != nil {
+			f.response = tsType(reg.schemaFor(reflect.TypeOf(op.Response)))
+		}
+		if op.Body != nil {
+			f.body = tsType(reg.structSchema(reflect.TypeOf(op.Body), true))
+		}
+		fns = append(fns, f)
+	}
+
+	var b strings.Builder
+	b.WriteString("// Code generated by go run ./cmd/openapi -ts. DO NOT EDIT.\n\n")
+
+	names := make([]string, 0, len(reg.schemas))
+	for name := range reg.schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "export interface %s %s\n\n", name, tsObject(reg.schemas[name]))
+	}
+
+	b.WriteString(`export class ApiError extends Error {
+    constructor(public status: number, public body: unknown) {
+        super(` + "`request failed with ${status}`" + `);
+    }
+}
+
+async function request<T>(baseUrl: string, method: string, path: string, query?: Record<string, unknown>, body?: unknown, init?: RequestInit): Promise<T> {
+    const url = new URL(path, baseUrl);
+    for (const [k, v] of Object.entries(query ?? {})) {
+        if (v !== undefined && v !== null) url.searchParams.set(k, String(v));
+    }
+    const res = await fetch(url, {
+        ...init,
+        method,
+        credentials: "include",
+        headers: { ...(body !== undefined ? { "Content-Type": "application/json" } : {}), ...init?.headers },
+        body: body !== undefined ? JSON.stringify(body) : undefined,
+    });
+    if (!res.ok) throw new ApiError(res.status, await res.json().catch(() => null));
+    return (res.status === 204 ? undefined : await res.json()) as T;
+}
+
+`)
+
+	for _, f := range fns {
+		var args, pathParams, queryParams []string
+		queryRequired := false
+		for _, p := range f.op.Params {
+			t := "string"
+			if p.Type == "integer" || p.Type == "number" {
+				t = "number"
+			}
+			opt := "?"
+			if p.Required {
+				opt = ""
+				if p.In == "query" {
+					queryRequired = true
+				}
+			}
+			if p.In == "path" {
+				pathParams = append(pathParams, p.Name)
+				args = append(args, fmt.Sprintf("%s: %s", p.Name, t))
+			} else {
+				queryParams = append(queryParams, fmt.Sprintf("%s%s: %s", p.Name, opt, t))
+			}
+		}
+		if len(queryParams) > 0 {
+			q := fmt.Sprintf("query: { %s }", strings.Join(queryParams, "; "))
+			if !queryRequired {
+				q += " = {}"
+			}
+			args = append(args, q)
+		}
+		if f.body != "" {
+			args = append(args, "body: "+f.body)
+		}
+		args = append(args, "init?: RequestInit")
+
+		path := f.op.Path
+		for _, p := range pathParams {
+			path = strings.Replace(path, ":"+p, "${encodeURIComponent("+p+")}", 1)
+		}
+		queryArg, bodyArg := "undefined", "undefined"
+		if len(queryParams) > 0 {
+			queryArg = "query"
+		}
+		if f.body != "" {
+			bodyArg = "body"
+		}
+
+		fmt.Fprintf(&b, "/** %s */\n", f.op.Summary)
+		fmt.Fprintf(&b, "export const %s = (baseUrl: string, %s) =>\n", operationID(f.op), strings.Join(args, ", "))
+		fmt.Fprintf(&b, "    request<%s>(baseUrl, %q, `%s`, %s, %s, init);\n\n", f.response, f.op.Method, path, queryArg, bodyArg)
+	}
+	return b.String()
+}
+
+func tsType(s *Schema) string {
+	var t string
+	switch {
+	case s.Ref != "":
+		t = strings.TrimPrefix(s.Ref, "#/components/schemas/")
+	case s.Type == "string":
+		t = "string"
+	case s.Type == "integer" || s.Type == "number":
+		t = "number"
+	case s.Type == "boolean":
+		t = "boolean"
+	case s.Type == "array":
+		t = tsType(s.Items) + "[]"
+		if strings.Contains(t, "|") {
+			t = "(" + tsType(s.Items) + ")[]"
+		}
+	case s.Type == "object" && s.AdditionalProperties != nil:
+		t = "Record<string, " + tsType(s.AdditionalProperties) + ">"
+	case s.Type == "object" && s.Properties != nil:
+		t = tsObject(s)
+	default:
+		t = "unknown"
+	}
+	if s.Nullable {
+		t += " | null"
+	}
+	return t
+}
+
+func tsObject(s *Schema) string {
+	required := make(map[string]bool, len(s.Required))
+	for _, r := range s.Required {
+		required[r] = true
+	}
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, name := range names {
+		opt := "?"
+		if required[name] {
+			opt = ""
+		}
+		fmt.Fprintf(&b, "    %s%s: %s;\n", name, opt, tsType(s.Properties[name]))
+	}
+	b.WriteString("}")
+	return b.String()
+}
@@ -0,0 +1,203 @@
+package scraper
+
+// scrape progress on disk so a crash halfway through doesn't cost the whole run
+// the file is newline delimited JSON: a header line, then one line per finished subject
+// every line is synced before the next subject starts
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// first line of the checkpoint
+type checkpointHeader struct {
+	Term      string    `json:"term"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// one finished subject
+type checkpointEntry struct {
+	Subject  string                `json:"subject"`
+	Sections []types.BannerSection `json:"sections"`
+}
+
+// safe for concurrent use by the fetch workers
+type Checkpoint struct {
+	Term      string
+	StartedAt time.Time
+
+	mu       sync.Mutex
+	path     string
+	f        *os.File
+	done     map[string]bool
+	empty    []string
+	sections []types.BannerSection
+}
+
+// opens the checkpoint at path
+// with resume the subjects already in the file are kept, otherwise it starts over
+// resuming a checkpoint from another term is an error
+func OpenCheckpoint(path, term string, resume bool) (*Checkpoint, error) {
+	cp := &Checkpoint{Term: term, path: path, done: make(map[string]bool)}
+	if resume {
+		ok, err := cp.load()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				return nil, err
+			}
+			cp.f = f
+			return cp, nil
+		}
+		slog.Info("no checkpoint to resume, starting over", "path", path)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	cp.f = f
+	cp.StartedAt = time.Now()
+	if err := cp.append(checkpointHeader{Term: term, StartedAt: cp.StartedAt}); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return cp, nil
+}
+
+// reads an existing checkpoint, false if there is none
+// a half written last line (crash mid write) is cut off so new lines append cleanly
+func (cp *Checkpoint) load() (bool, error) {
+	f, err := os.OpenFile(cp.path, os.O_RDWR, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var good int64
+	var header checkpointHeader
+	for first := true; ; first = false {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			// no trailing newline means the line never finished writing
+			break
+		}
+		if err != nil {
+			return false, err
+		}
+		if first {
+			if err := json.Unmarshal(line, &header); err != nil || header.Term == "" {
+				return false, fmt.Errorf("checkpoint %s: bad header", cp.path)
+			}
+			if header.Term != cp.Term {
+				return false, fmt.Errorf("checkpoint %s is for term %s, not %s", cp.path, header.Term, cp.Term)
+			}
+		} else {
+			var entry checkpointEntry
+			if err := json.Unmarshal(line, &entry); err != nil || entry.Subject == "" {
+				break
+			}
+			if !cp.done[entry.Subject] {
+				cp.add(entry.Subject, entry.Sections)
+			}
+		}
+		good += int64(len(line))
+	}
+	if good == 0 {
+		// crashed before the header made it, same as no checkpoint
+		return false, nil
+	}
+	if err := f.Truncate(good); err != nil {
+		return false, err
+	}
+	cp.StartedAt = header.StartedAt
+	return true, nil
+}
+
+// whether the subject was already fetched
+func (cp *Checkpoint) Done(subject string) bool {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return cp.done[subject]
+}
+
+// number of finished subjects
+func (cp *Checkpoint) Len() int {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return len(cp.done)
+}
+
+// finished subjects that had no sections
+func (cp *Checkpoint) Empty() []string {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return cp.empty
+}
+
+// raw sections of every finished subject, in the order they were fetched
+func (cp *Checkpoint) Sections() []types.BannerSection {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return cp.sections
+}
+
+// marks a subject as finished with its sections
+func (cp *Checkpoint) Record(subject string, sections []types.BannerSection) error {
+	if sections == nil {
+		sections = []types.BannerSection{}
+	}
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	if err := cp.append(checkpointEntry{Subject: subject, Sections: sections}); err != nil {
+		return err
+	}
+	cp.add(subject, sections)
+	return nil
+}
+
+func (cp *Checkpoint) add(subject string, sections []types.BannerSection) {
+	cp.done[subject] = true
+	if len(sections) == 0 {
+		cp.empty = append(cp.empty, subject)
+	}
+	cp.sections = append(cp.sections, sections...)
+}
+
+func (cp *Checkpoint) append(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := cp.f.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	return cp.f.Sync()
+}
+
+// closes the file and keeps it for the next --resume
+func (cp *Checkpoint) Close() error {
+	return cp.f.Close()
+}
+
+// closes and deletes the checkpoint once its data has been saved
+func (cp *Checkpoint) Remove() error {
+	cp.f.Close()
+	return os.Remove(cp.path)
+}
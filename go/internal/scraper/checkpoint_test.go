@@ -0,0 +1,194 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/banner"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+func section(subject, crn string) types.BannerSection {
+	return types.BannerSection{CRN: crn, Subject: subject, Term: "202610"}
+}
+
+// a checkpoint with CS and MATH finished and the PHYS line cut off halfway, like a crash mid write
+func tornCheckpoint(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "scrape.checkpoint.ndjson")
+	cp, err := OpenCheckpoint(path, "202610", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cp.Record("CS", []types.BannerSection{section("CS", "10492"), section("CS", "10493")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cp.Record("MATH", nil); err != nil {
+		t.Fatal(err)
+	}
+	cp.Close()
+
+	torn, _ := json.Marshal(checkpointEntry{Subject: "PHYS", Sections: []types.BannerSection{section("PHYS", "20001")}})
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(torn[:len(torn)/2])
+	f.Close()
+	return path
+}
+
+func TestCheckpointResume(t *testing.T) {
+	path := tornCheckpoint(t)
+
+	cp, err := OpenCheckpoint(path, "202610", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cp.Done("CS") || !cp.Done("MATH") || cp.Done("PHYS") {
+		t.Errorf("done CS %v, MATH %v, PHYS %v, want only the first two", cp.Done("CS"), cp.Done("MATH"), cp.Done("PHYS"))
+	}
+	if cp.Len() != 2 || len(cp.Sections()) != 2 || !slices.Equal(cp.Empty(), []string{"MATH"}) {
+		t.Errorf("%d subjects, %d sections, empty %v", cp.Len(), len(cp.Sections()), cp.Empty())
+	}
+
+	// the torn line is cut off, so a new record starts on a line of its own
+	if err := cp.Record("PHYS", []types.BannerSection{section("PHYS", "20001")}); err != nil {
+		t.Fatal(err)
+	}
+	cp.Close()
+
+	cp, err = OpenCheckpoint(path, "202610", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cp.Close()
+	if cp.Len() != 3 || len(cp.Sections()) != 3 {
+		t.Errorf("after the second resume: %d subjects, %d sections, want 3 and 3", cp.Len(), len(cp.Sections()))
+	}
+	b, _ := os.ReadFile(path)
+	if lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n"); len(lines) != 4 {
+		t.Errorf("%d lines, want a header and 3 subjects:\n%s", len(lines), b)
+	}
+}
+
+func TestCheckpointOpen(t *testing.T) {
+	dir := t.TempDir()
+
+	// nothing to resume starts over
+	path := filepath.Join(dir, "missing.ndjson")
+	cp, err := OpenCheckpoint(path, "202610", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp.Close()
+	if cp.Len() != 0 || cp.StartedAt.IsZero() {
+		t.Errorf("%d subjects, started %s", cp.Len(), cp.StartedAt)
+	}
+
+	// a crash before the header finished is the same as no checkpoint
+	path = filepath.Join(dir, "headless.ndjson")
+	os.WriteFile(path, []byte(`{"term":"2026`), 0o644)
+	if cp, err = OpenCheckpoint(path, "202610", true); err != nil {
+		t.Fatal(err)
+	}
+	cp.Close()
+
+	// another term's checkpoint is refused, not mixed in
+	path = tornCheckpoint(t)
+	if _, err := OpenCheckpoint(path, "202670", true); err == nil {
+		t.Error("resumed a checkpoint from another term")
+	}
+
+	// without resume the file starts over
+	if cp, err = OpenCheckpoint(path, "202610", false); err != nil {
+		t.Fatal(err)
+	}
+	cp.Close()
+	if cp.Len() != 0 {
+		t.Errorf("%d subjects after starting over", cp.Len())
+	}
+	if cp, err = OpenCheckpoint(path, "202610", true); err != nil {
+		t.Fatal(err)
+	}
+	cp.Close()
+	if cp.Len() != 0 {
+		t.Errorf("%d subjects resumed after starting over", cp.Len())
+	}
+}
+
+// a banner that answers every subject with one section, and the subjects in fail with a 503,
+// and records which subjects were asked for
+func fakeBanner(t *testing.T, fail ...string) (*httptest.Server, func() []string) {
+	t.Helper()
+	var (
+		mu    sync.Mutex
+		asked []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ssb/classSearch/classSearch":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<meta name="synchronizerToken" content="token">`)
+		case "/ssb/searchResults/searchResults":
+			subject := r.URL.Query().Get("txt_subject")
+			mu.Lock()
+			asked = append(asked, subject)
+			mu.Unlock()
+			if slices.Contains(fail, subject) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(types.BannerResponse{Success: true, TotalCount: 1,
+				Data: []types.BannerSection{section(subject, "3"+subject)}})
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, "{}")
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Sorted(slices.Values(asked))
+	}
+}
+
+// resuming only fetches the subjects the checkpoint doesn't have, the torn one included
+func TestFetchSkipsFinishedSubjects(t *testing.T) {
+	delay := SubjectDelay
+	SubjectDelay = 0
+	t.Cleanup(func() { SubjectDelay = delay })
+
+	srv, asked := fakeBanner(t)
+	cp, err := OpenCheckpoint(tornCheckpoint(t), "202610", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cp.Close()
+
+	clients := []*banner.Client{
+		banner.New(banner.Config{BaseURL: srv.URL, Term: "202610"}),
+		banner.New(banner.Config{BaseURL: srv.URL, Term: "202610"}),
+	}
+	failed, err := Fetch(context.Background(), clients, []string{"CS", "MATH", "PHYS", "ECON"}, cp)
+	if err != nil || len(failed) > 0 {
+		t.Fatalf("failed %v, err %v", failed, err)
+	}
+	if got := asked(); !slices.Equal(got, []string{"ECON", "PHYS"}) {
+		t.Errorf("fetched %v, want only ECON and PHYS", got)
+	}
+	if cp.Len() != 4 || len(cp.Sections()) != 4 {
+		t.Errorf("%d subjects, %d sections, want 4 and 4", cp.Len(), len(cp.Sections()))
+	}
+}
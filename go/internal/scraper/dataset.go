@@ -0,0 +1,163 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/store"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// builds rooms, sections and instructors from raw banner sections
+// a CRN listed under more than one subject only counts once
+func Aggregate(term string, raw []types.BannerSection) store.Dataset {
+	rooms := make(map[string]*types.Room)
+	sections := make(map[string]types.Section)
+	instructors := make(map[string]types.Instructor)
+
+	for _, rawSec := range raw {
+		if _, dup := sections[rawSec.CRN]; dup {
+			continue
+		}
+		section := parseBannerSection(rawSec)
+		sections[rawSec.CRN] = section
+		for _, inst := range section.Instructors {
+			// keep the entry that has an email if we've seen them both ways
+			if prev, ok := instructors[inst.ID]; !ok || prev.Email == "" {
+				instructors[inst.ID] = inst
+			}
+		}
+
+		// get all meetings for this section
+		for _, meeting := range parseBannerMeetings(rawSec) {
+			// filter unknown locations
+			if !isRoom(meeting.Location) {
+				continue
+			}
+
+			roomID := strings.ReplaceAll(meeting.Location, " ", "_")
+
+			if _, exists := rooms[roomID]; !exists {
+				parts := strings.Split(meeting.Location, " ")
+				number := ""
+				building := meeting.Location
+				if len(parts) > 1 {
+					number = parts[len(parts)-1]
+					building = strings.Join(parts[:len(parts)-1], " ")
+				}
+
+				rooms[roomID] = &types.Room{
+					ID:       roomID,
+					Building: building,
+					Number:   number,
+					Schedule: []types.Meeting{},
+				}
+			}
+
+			// add to schedule
+			rooms[roomID].Schedule = append(rooms[roomID].Schedule, meeting)
+
+			// banner has no room capacities, the biggest section that meets here is the next best thing
+			rooms[roomID].Capacity = max(rooms[roomID].Capacity, rawSec.MaxEnrollment)
+		}
+	}
+
+	ds := store.Dataset{Term: term}
+	for _, r := range rooms {
+		sortSchedule(r.Schedule)
+		ds.Rooms = append(ds.Rooms, *r)
+	}
+	for _, s := range sections {
+		ds.Sections = append(ds.Sections, s)
+	}
+	for _, i := range instructors {
+		ds.Instructors = append(ds.Instructors, i)
+	}
+	// stable output so two scrapes of the same data diff cleanly
+	sort.Slice(ds.Rooms, func(i, j int) bool { return ds.Rooms[i].ID < ds.Rooms[j].ID })
+	sort.Slice(ds.Sections, func(i, j int) bool { return ds.Sections[i].CRN < ds.Sections[j].CRN })
+	sort.Slice(ds.Instructors, func(i, j int) bool { return ds.Instructors[i].ID < ds.Instructors[j].ID })
+	return ds
+}
+
+// by day, then time, then CRN
+func sortSchedule(schedule []types.Meeting) {
+	sort.SliceStable(schedule, func(i, j int) bool {
+		a, b := schedule[i], schedule[j]
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		if a.StartTime != b.StartTime {
+			return a.StartTime < b.StartTime
+		}
+		if a.EndTime != b.EndTime {
+			return a.EndTime < b.EndTime
+		}
+		return meetingCRN(a) < meetingCRN(b)
+	})
+}
+
+func meetingCRN(m types.Meeting) string {
+	if len(m.Label) == 0 {
+		return ""
+	}
+	return m.Label[0].ID
+}
+
+// dataset file formats, see store.ReadDataset
+const (
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+)
+
+// the format a file name implies, NDJSON for .ndjson and .jsonl
+func FormatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	}
+	return FormatJSON
+}
+
+// reads a JSON or NDJSON dataset file, "-" reads stdin
+func ReadDatasetFile(path string) (store.Dataset, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return store.Dataset{}, err
+		}
+		defer f.Close()
+		r = f
+	}
+	ds, err := store.ReadDataset(r)
+	if err != nil {
+		return store.Dataset{}, fmt.Errorf("reading dataset %s: %w", path, err)
+	}
+	return ds, nil
+}
+
+// writes the dataset as indented JSON or as NDJSON, "-" writes to stdout
+func WriteDatasetFile(path string, ds store.Dataset, format string) error {
+	var w io.Writer = os.Stdout
+	if path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if format == FormatNDJSON {
+		return store.WriteNDJSON(w, ds, time.Now())
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(ds)
+}
@@ -0,0 +1,177 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/store"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// what changed from one dataset to another
+type DatasetDiff struct {
+	RoomsAdded         []string     `json:"rooms_added"`
+	RoomsRemoved       []string     `json:"rooms_removed"`
+	RoomsChanged       []RoomChange `json:"rooms_changed"`
+	SectionsAdded      []string     `json:"sections_added"`
+	SectionsRemoved    []string     `json:"sections_removed"`
+	SectionsChanged    []string     `json:"sections_changed"`
+	InstructorsAdded   []string     `json:"instructors_added"`
+	InstructorsRemoved []string     `json:"instructors_removed"`
+}
+
+// a room in both datasets with a different schedule or capacity
+type RoomChange struct {
+	ID              string `json:"id"`
+	MeetingsAdded   int    `json:"meetings_added"`
+	MeetingsRemoved int    `json:"meetings_removed"`
+	CapacityBefore  int    `json:"capacity_before"`
+	CapacityAfter   int    `json:"capacity_after"`
+}
+
+func (d DatasetDiff) Empty() bool {
+	return len(d.RoomsAdded)+len(d.RoomsRemoved)+len(d.RoomsChanged)+
+		len(d.SectionsAdded)+len(d.SectionsRemoved)+len(d.SectionsChanged)+
+		len(d.InstructorsAdded)+len(d.InstructorsRemoved) == 0
+}
+
+// compares two datasets, schedule order doesn't matter
+func Diff(from, to store.Dataset) DatasetDiff {
+	var d DatasetDiff
+
+	before := make(map[string]types.Room, len(from.Rooms))
+	for _, r := range from.Rooms {
+		before[r.ID] = r
+	}
+	for _, r := range to.Rooms {
+		old, ok := before[r.ID]
+		if !ok {
+			d.RoomsAdded = append(d.RoomsAdded, r.ID)
+			continue
+		}
+		delete(before, r.ID)
+		added, removed := meetingDelta(old.Schedule, r.Schedule)
+		if added > 0 || removed > 0 || old.Capacity != r.Capacity {
+			d.RoomsChanged = append(d.RoomsChanged, RoomChange{
+				ID:              r.ID,
+				MeetingsAdded:   added,
+				MeetingsRemoved: removed,
+				CapacityBefore:  old.Capacity,
+				CapacityAfter:   r.Capacity,
+			})
+		}
+	}
+	for id := range before {
+		d.RoomsRemoved = append(d.RoomsRemoved, id)
+	}
+
+	d.SectionsAdded, d.SectionsRemoved, d.SectionsChanged = diffByKey(from.Sections, to.Sections, func(s types.Section) string { return s.CRN })
+	d.InstructorsAdded, d.InstructorsRemoved, _ = diffByKey(from.Instructors, to.Instructors, func(i types.Instructor) string { return i.ID })
+
+	sort.Strings(d.RoomsAdded)
+	sort.Strings(d.RoomsRemoved)
+	sort.Slice(d.RoomsChanged, func(i, j int) bool { return d.RoomsChanged[i].ID < d.RoomsChanged[j].ID })
+
+	// [] rather than null in the JSON form
+	for _, list := range []*[]string{&d.RoomsAdded, &d.RoomsRemoved, &d.SectionsAdded, &d.SectionsRemoved,
+		&d.SectionsChanged, &d.InstructorsAdded, &d.InstructorsRemoved} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+	if d.RoomsChanged == nil {
+		d.RoomsChanged = []RoomChange{}
+	}
+	return d
+}
+
+// meetings only in b and only in a, counting duplicates
+func meetingDelta(a, b []types.Meeting) (added, removed int) {
+	key := func(m types.Meeting) string {
+		return fmt.Sprintf("%d/%d/%d/%s", m.Day, m.StartTime, m.EndTime, meetingCRN(m))
+	}
+	count := make(map[string]int)
+	for _, m := range a {
+		count[key(m)]++
+	}
+	for _, m := range b {
+		count[key(m)]--
+	}
+	for _, n := range count {
+		if n > 0 {
+			removed += n
+		} else {
+			added -= n
+		}
+	}
+	return added, removed
+}
+
+// keys only in b, only in a, and in both with a different JSON form
+func diffByKey[T any](a, b []T, key func(T) string) (added, removed, changed []string) {
+	before := make(map[string][]byte, len(a))
+	for _, v := range a {
+		before[key(v)], _ = json.Marshal(v)
+	}
+	for _, v := range b {
+		k := key(v)
+		old, ok := before[k]
+		if !ok {
+			added = append(added, k)
+			continue
+		}
+		delete(before, k)
+		if now, _ := json.Marshal(v); string(now) != string(old) {
+			changed = append(changed, k)
+		}
+	}
+	for k := range before {
+		removed = append(removed, k)
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(changed)
+	return added, removed, changed
+}
+
+// human readable summary, one line per change
+func (d DatasetDiff) WriteText(w io.Writer) {
+	if d.Empty() {
+		fmt.Fprintln(w, "no changes")
+		return
+	}
+	for _, id := range d.RoomsAdded {
+		fmt.Fprintf(w, "+ room %s\n", id)
+	}
+	for _, id := range d.RoomsRemoved {
+		fmt.Fprintf(w, "- room %s\n", id)
+	}
+	for _, c := range d.RoomsChanged {
+		fmt.Fprintf(w, "~ room %s: +%d -%d meetings", c.ID, c.MeetingsAdded, c.MeetingsRemoved)
+		if c.CapacityBefore != c.CapacityAfter {
+			fmt.Fprintf(w, ", capacity %d -> %d", c.CapacityBefore, c.CapacityAfter)
+		}
+		fmt.Fprintln(w)
+	}
+	for _, crn := range d.SectionsAdded {
+		fmt.Fprintf(w, "+ section %s\n", crn)
+	}
+	for _, crn := range d.SectionsRemoved {
+		fmt.Fprintf(w, "- section %s\n", crn)
+	}
+	for _, crn := range d.SectionsChanged {
+		fmt.Fprintf(w, "~ section %s\n", crn)
+	}
+	for _, id := range d.InstructorsAdded {
+		fmt.Fprintf(w, "+ instructor %s\n", id)
+	}
+	for _, id := range d.InstructorsRemoved {
+		fmt.Fprintf(w, "- instructor %s\n", id)
+	}
+	fmt.Fprintf(w, "rooms +%d -%d ~%d, sections +%d -%d ~%d, instructors +%d -%d\n",
+		len(d.RoomsAdded), len(d.RoomsRemoved), len(d.RoomsChanged),
+		len(d.SectionsAdded), len(d.SectionsRemoved), len(d.SectionsChanged),
+		len(d.InstructorsAdded), len(d.InstructorsRemoved))
+}
@@ -0,0 +1,115 @@
+package scraper
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/banner"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+)
+
+// pause between subjects of one session so each looks like a person clicking through banner
+var SubjectDelay = 5 * time.Second
+
+// fetches every subject that isn't in the checkpoint yet and records it there
+// each client is an independent banner session working through the subjects in parallel,
+// the global request rate is up to the clients' shared banner.Limiter
+// a subject that keeps failing is returned in failed so the rest of the run isn't lost
+// the error is only set when the checkpoint can't be written or ctx is cancelled
+func Fetch(ctx context.Context, clients []*banner.Client, subjects []string, cp *Checkpoint) (failed []string, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+	todo := make(chan string)
+
+	var wg sync.WaitGroup
+	for i, client := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			first := true
+			for subj := range todo {
+				if !first {
+					slog.Debug("sleeping before next subject", "session", i, "delay", SubjectDelay)
+					if sleep(ctx, SubjectDelay) != nil {
+						return
+					}
+				}
+				first = false
+
+				// NOTE: banner api is stateful, Sections resets the search before every subject
+				// otherwise it will keep appending to the previous search
+				rawSections, err := client.Sections(ctx, subj)
+				if ctx.Err() != nil {
+					return
+				}
+				if err != nil {
+					slog.Error("skipping subject", "subject", subj, "session", i, "err", err)
+					metrics.ScrapeErrors.WithLabelValues("subject").Inc()
+					mu.Lock()
+					failed = append(failed, subj)
+					mu.Unlock()
+					continue
+				}
+				if len(rawSections) == 0 {
+					slog.Info("no classes found", "subject", subj)
+				}
+				metrics.ScrapeSections.Add(float64(len(rawSections)))
+
+				if err := cp.Record(subj, rawSections); err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					cancel()
+					return
+				}
+				slog.Info("fetched subject", "subject", subj, "session", i, "sections", len(rawSections), "done", cp.Len(), "total", len(subjects))
+			}
+		}()
+	}
+
+	// NOTE: each session still makes one request at a time with the same delays as a single
+	// sequential scrape, more sessions only means more of them at once
+	// - sleep (500ms + random) between pagination requests, retries back off exponentially
+	// - sleep 5s between subject changes
+	// - every request across all sessions waits its turn on the shared limiter
+	// - we also mimic a real user with cookiejar and headers so
+	// the traffic look like a legit SPA user looking at classes
+feed:
+	for _, subj := range subjects {
+		if cp.Done(subj) {
+			continue
+		}
+		select {
+		case todo <- subj:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(todo)
+	wg.Wait()
+
+	if firstErr != nil {
+		return failed, firstErr
+	}
+	return failed, ctx.Err()
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
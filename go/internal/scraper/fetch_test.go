@@ -0,0 +1,46 @@
+package scraper
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/banner"
+)
+
+// a subject banner keeps failing is reported and skipped, the other session carries on with the rest
+func TestFetchFailedSubject(t *testing.T) {
+	delay := SubjectDelay
+	SubjectDelay = 0
+	t.Cleanup(func() { SubjectDelay = delay })
+
+	srv, asked := fakeBanner(t, "MATH")
+	cp, err := OpenCheckpoint(filepath.Join(t.TempDir(), "checkpoint.ndjson"), "202610", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cp.Close()
+
+	clients := make([]*banner.Client, 2)
+	for i := range clients {
+		clients[i] = banner.New(banner.Config{BaseURL: srv.URL, Term: "202610",
+			MaxRetries: 1, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+	}
+	subjects := []string{"CS", "MATH", "PHYS", "ECON", "HIST"}
+	failed, err := Fetch(context.Background(), clients, subjects, cp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(failed, []string{"MATH"}) {
+		t.Errorf("failed %v, want MATH", failed)
+	}
+	// MATH is retried once before it's given up on
+	if got := asked(); !slices.Equal(got, []string{"CS", "ECON", "HIST", "MATH", "MATH", "PHYS"}) {
+		t.Errorf("asked for %v", got)
+	}
+	if cp.Len() != 4 || cp.Done("MATH") {
+		t.Errorf("checkpoint has %d subjects, want every one but MATH", cp.Len())
+	}
+}
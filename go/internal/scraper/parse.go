@@ -0,0 +1,209 @@
+package scraper
+
+// banner section -> ghost types
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// parsing time: 1330 -> 13 + 30
+// return 0 if nil or invalid
+func parseTimeStr(t *string) int {
+	if t == nil {
+		return 0
+	}
+	val := *t
+	if len(val) < 4 {
+		return 0
+	}
+	// "1330" -> 13, 30
+	hh, _ := strconv.Atoi(val[:2])
+	mm, _ := strconv.Atoi(val[2:])
+	return (hh * 60) + mm
+}
+
+// safely dereference string pointer
+func getStr(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// returns a list of meetings with the course info
+func parseBannerMeetings(raw types.BannerSection) []types.Meeting {
+	var meetings []types.Meeting
+
+	// extract info
+	profName := "Unknown"
+	if len(raw.Faculty) > 0 {
+		profName = raw.Faculty[0].DisplayName
+	}
+
+	for i, mf := range raw.MeetingsFaculty {
+		mt := mf.MeetingTime
+
+		info := types.MeetingInfo{
+			ID:          raw.CRN,
+			CourseID:    raw.Subject + raw.CourseNumber,
+			Section:     raw.SequenceNumber,
+			Professor:   profName,
+			Instructors: meetingInstructors(raw, i),
+		}
+
+		startMin := parseTimeStr(mt.BeginTime)
+		endMin := parseTimeStr(mt.EndTime)
+
+		if startMin == 0 || endMin == 0 {
+			continue
+		}
+
+		bldg := getStr(mt.Building)
+		room := getStr(mt.Room)
+		location := fmt.Sprintf("%s %s", bldg, room)
+		if bldg == "" || room == "" {
+			location = "TBA"
+		}
+
+		daysMap := map[int]bool{
+			0: mt.Sunday, 1: mt.Monday, 2: mt.Tuesday,
+			3: mt.Wednesday, 4: mt.Thursday, 5: mt.Friday, 6: mt.Saturday,
+		}
+
+		for dayCode, isActive := range daysMap {
+			if isActive {
+				meetings = append(meetings, types.Meeting{
+					Day:       dayCode,
+					StartTime: startMin,
+					EndTime:   endMin,
+					Location:  location,
+					Label:     []types.MeetingInfo{info},
+				})
+			}
+		}
+	}
+	return meetings
+}
+
+// returns the full section with every meeting, including online/TBA ones
+// (parseBannerMeetings splits a section into per-day room meetings, this keeps it whole)
+func parseBannerSection(raw types.BannerSection) types.Section {
+	section := types.Section{
+		CRN:            raw.CRN,
+		Term:           raw.Term,
+		Subject:        raw.Subject,
+		CourseNumber:   raw.CourseNumber,
+		CourseID:       raw.Subject + raw.CourseNumber,
+		SequenceNumber: raw.SequenceNumber,
+		Title:          raw.Title,
+		Instructors:    sectionInstructors(raw),
+		InstructorIDs:  []string{},
+		Meetings:       []types.SectionMeeting{},
+	}
+	for _, inst := range section.Instructors {
+		section.InstructorIDs = append(section.InstructorIDs, inst.ID)
+	}
+
+	for i, mf := range raw.MeetingsFaculty {
+		mt := mf.MeetingTime
+
+		bldg := getStr(mt.Building)
+		room := getStr(mt.Room)
+		location := fmt.Sprintf("%s %s", bldg, room)
+		if bldg == "" || room == "" {
+			location = "TBA"
+		}
+
+		roomID := ""
+		if isRoom(location) {
+			roomID = strings.ReplaceAll(location, " ", "_")
+		}
+
+		days := []int{}
+		for dayCode, isActive := range []bool{mt.Sunday, mt.Monday, mt.Tuesday, mt.Wednesday, mt.Thursday, mt.Friday, mt.Saturday} {
+			if isActive {
+				days = append(days, dayCode)
+			}
+		}
+
+		section.Meetings = append(section.Meetings, types.SectionMeeting{
+			Days:      days,
+			StartTime: parseTimeStr(mt.BeginTime),
+			EndTime:   parseTimeStr(mt.EndTime),
+			Location:  location,
+			RoomID:    roomID,
+
+			Instructors: meetingInstructors(raw, i),
+		})
+	}
+	return section
+}
+
+// everyone teaching meeting i of the section
+// banner often leaves the per-meeting faculty list empty, then the section's faculty teach it
+func meetingInstructors(raw types.BannerSection, i int) []types.Instructor {
+	list := []types.Instructor{}
+	for _, f := range raw.MeetingsFaculty[i].Faculty {
+		if inst := newInstructor(f.DisplayName, f.Email); inst.ID != "" {
+			list = append(list, inst)
+		}
+	}
+	if len(list) == 0 {
+		for _, f := range raw.Faculty {
+			if inst := newInstructor(f.DisplayName, f.Email); inst.ID != "" {
+				list = append(list, inst)
+			}
+		}
+	}
+	return list
+}
+
+// everyone teaching any meeting of the section, without duplicates
+func sectionInstructors(raw types.BannerSection) []types.Instructor {
+	list := []types.Instructor{}
+	seen := make(map[string]bool)
+	add := func(inst types.Instructor) {
+		if inst.ID == "" || seen[inst.ID] {
+			return
+		}
+		seen[inst.ID] = true
+		list = append(list, inst)
+	}
+
+	for _, f := range raw.Faculty {
+		add(newInstructor(f.DisplayName, f.Email))
+	}
+	for _, mf := range raw.MeetingsFaculty {
+		for _, f := range mf.Faculty {
+			add(newInstructor(f.DisplayName, f.Email))
+		}
+	}
+	return list
+}
+
+var nonSlugRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// the ID is the email username ("jdoe@gmu.edu" -> "jdoe") since names aren't unique,
+// and a slug of the name when banner has no email ("Doe, John" -> "doe-john")
+func newInstructor(name, email string) types.Instructor {
+	email = strings.ToLower(strings.TrimSpace(email))
+	id := ""
+	if at := strings.Index(email, "@"); at > 0 {
+		id = email[:at]
+	} else {
+		id = strings.Trim(nonSlugRe.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	}
+	return types.Instructor{ID: id, Name: name, Email: email}
+}
+
+// false for online, off campus and TBA locations
+func isRoom(location string) bool {
+	return !(strings.Contains(location, "ON LINE") || strings.Contains(location, "Online") ||
+		strings.Contains(location, "OFF CAMPUS") ||
+		strings.Contains(location, "TBA"))
+}
@@ -0,0 +1,210 @@
+package scraper
+
+// one full scrape: checkpoint -> banner sessions -> fetch -> aggregate -> validate -> snapshot -> publish
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/audit"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/banner"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/store"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+type Options struct {
+	BaseURL  string
+	Term     string
+	Subjects []string // only these subjects, every subject of the term when empty
+
+	Checkpoint   string // progress file
+	Resume       bool   // keep the subjects already in the checkpoint
+	AllowPartial bool   // publish even if some subjects failed
+
+	Sessions int     // parallel banner sessions, 4 in DefaultOptions, 0 or less means 1
+	Rate     float64 // requests per second across all sessions, 0 for no limit
+
+	Limits Thresholds
+	Report string // where the validation report is written, "" to skip
+	Force  bool   // publish even if validation fails
+
+	DryRun bool   // don't write to firestore
+	Out    string // also write the dataset as JSON here, "-" for stdout
+
+	Keep int // snapshots to keep after publishing
+
+	Trigger string // who started the run, kept in its record, ex) "cli", "schedule"
+}
+
+// spring 2026 term
+const DefaultTerm = "202610"
+
+const DefaultCheckpoint = "scrape.checkpoint.ndjson"
+
+// what `scraper scrape` does without flags, and what scheduled runs do
+// BANNER_BASE_URL points the scraper at another banner, like a local stand-in
+func DefaultOptions() Options {
+	return Options{
+		BaseURL:    cmp.Or(os.Getenv("BANNER_BASE_URL"), banner.DefaultBaseURL),
+		Term:       DefaultTerm,
+		Checkpoint: DefaultCheckpoint,
+		Sessions:   4,
+		Rate:       4,
+		Limits:     DefaultThresholds,
+		Keep:       DefaultKeep,
+	}
+}
+
+// what a run produced
+type Result struct {
+	Dataset store.Dataset
+	Report  *Report
+	Version string   // published snapshot, "" on a dry run
+	Failed  []string // subjects skipped with AllowPartial
+}
+
+// fails the run without touching the live data, the checkpoint is kept for a resume
+var ErrValidation = errors.New("validation failed")
+
+// a subject filter only fetches part of the term, publishing that would drop every other subject's rooms
+var ErrPartialDataset = errors.New("a scrape of some subjects can't be published, use a dry run")
+
+// whether a scrape can go on after validation: failures stop it unless it's a dry run
+// (which only reports them) or forced
+func (opts Options) checkReport(r *Report) error {
+	if r.Passed {
+		return nil
+	}
+	switch {
+	case opts.DryRun:
+		slog.Warn("validation failed", "failed", r.Failed(), "report", opts.Report)
+	case opts.Force:
+		slog.Warn("validation failed, publishing anyway because of force", "failed", r.Failed(), "report", opts.Report)
+	default:
+		return fmt.Errorf("%w: %v, see %s", ErrValidation, r.Failed(), opts.Report)
+	}
+	return nil
+}
+
+// the pipeline itself, Run wraps it with the lock and the run record
+func run(ctx context.Context, opts Options) (*Result, error) {
+	cp, err := OpenCheckpoint(opts.Checkpoint, opts.Term, opts.Resume)
+	if err != nil {
+		return nil, fmt.Errorf("opening checkpoint: %w", err)
+	}
+	defer cp.Close()
+	if cp.Len() > 0 {
+		slog.Info("resuming scrape", "started_at", cp.StartedAt, "subjects_done", cp.Len())
+	}
+
+	// independent sessions (own cookie jar and token each) sharing one polite request rate
+	cfg := banner.Config{BaseURL: opts.BaseURL, Term: opts.Term, Limiter: banner.NewLimiter(opts.Rate)}
+
+	// guest handshake to get X-Synchronizer-Token and set the term
+	// all requests will happen after setting the term
+	slog.Info("== 1 == handshaking with banner", "term", opts.Term, "sessions", max(opts.Sessions, 1))
+	var clients []*banner.Client
+	for i := range max(opts.Sessions, 1) {
+		client := banner.New(cfg)
+		if err := client.Handshake(ctx); err != nil {
+			slog.Warn("banner handshake failed", "session", i, "err", err)
+			continue
+		}
+		clients = append(clients, client)
+	}
+	if len(clients) == 0 {
+		return nil, errors.New("every banner handshake failed")
+	}
+
+	slog.Info("== 2 == fetching subject list")
+	list, err := clients[0].Subjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var subjects []string
+	for _, s := range list {
+		if len(opts.Subjects) == 0 || slices.Contains(opts.Subjects, s.Code) {
+			subjects = append(subjects, s.Code)
+		}
+	}
+	for _, s := range opts.Subjects {
+		if !slices.Contains(subjects, s) {
+			slog.Warn("subject not offered this term", "subject", s, "term", opts.Term)
+		}
+	}
+	if len(subjects) == 0 {
+		return nil, errors.New("no subjects to fetch")
+	}
+	slog.Info("found active subjects", "subjects", len(subjects))
+
+	slog.Info("== 3 == fetching classes", "subjects", len(subjects), "already_done", cp.Len())
+	failed, err := Fetch(ctx, clients, subjects, cp)
+	if err != nil {
+		return nil, fmt.Errorf("scrape interrupted, resume to continue: %w", err)
+	}
+	if len(failed) > 0 && (!opts.AllowPartial || len(failed) == len(subjects)) {
+		// saving now would replace good rooms with half a term, wait for a full pass
+		return nil, fmt.Errorf("%d subjects failed, nothing was saved, resume to retry them: %v", len(failed), failed)
+	}
+	if len(failed) > 0 {
+		slog.Warn("some subjects failed and were skipped", "count", len(failed), "subjects", failed)
+	}
+
+	// NOTE: 8000 sections * ~2KB JSON each = ~16MB RAM worst case
+	res := &Result{Dataset: Aggregate(opts.Term, cp.Sections()), Failed: failed}
+	ds := res.Dataset
+
+	slog.Info("== 4 == validating against the live data")
+	// kept for the audit log too, it records every room the publish changes
+	var live []types.Room
+	if firestore.Client != nil {
+		live, err = firestore.GetAllRooms(ctx)
+		if err != nil && !opts.DryRun {
+			return nil, fmt.Errorf("reading the live data to compare against: %w", err)
+		}
+	}
+	res.Report = Validate(ds, SummaryOf(live), cp.Len(), cp.Empty(), opts.Limits)
+	if opts.Report != "" {
+		if err := res.Report.WriteFile(opts.Report); err != nil {
+			slog.Error("writing validation report", "path", opts.Report, "err", err)
+		}
+	}
+	if err := opts.checkReport(res.Report); err != nil {
+		return res, err
+	}
+
+	if opts.Out != "" {
+		if err := WriteDatasetFile(opts.Out, ds, FormatOf(opts.Out)); err != nil {
+			return res, fmt.Errorf("writing dataset: %w", err)
+		}
+	}
+	if opts.DryRun {
+		slog.Info("dry run, nothing was written to firestore", "rooms", len(ds.Rooms), "sections", len(ds.Sections), "instructors", len(ds.Instructors))
+		return res, nil
+	}
+
+	slog.Info("== 5 == saving snapshot", "rooms", len(ds.Rooms), "sections", len(ds.Sections), "instructors", len(ds.Instructors))
+	res.Version, err = Save(ctx, ds)
+	if err != nil {
+		return res, fmt.Errorf("saving dataset, resume to save again without refetching: %w", err)
+	}
+
+	slog.Info("== 6 == publishing snapshot", "snapshot", res.Version)
+	if err := Publish(ctx, res.Version, opts.Keep); err != nil {
+		return res, fmt.Errorf("snapshot %s is saved but not live, roll back to it to publish: %w", res.Version, err)
+	}
+	audit.Record(ctx, audit.Published(audit.ScraperActor(opts.Trigger), res.Version, live, ds.Rooms)...)
+
+	metrics.ScrapeLastSuccess.SetToCurrentTime()
+	if err := cp.Remove(); err != nil {
+		slog.Warn("removing checkpoint", "path", opts.Checkpoint, "err", err)
+	}
+	return res, nil
+}
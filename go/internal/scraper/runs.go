@@ -0,0 +1,164 @@
+package scraper
+
+// every run that writes to firestore takes meta/scrape_lock first and records itself under scrapes/{id},
+// so a nightly run, a daemon and someone scraping by hand never overlap and GET /api/admin/scrapes
+// can show what happened
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+const (
+	// a run that dies without releasing the lock blocks others for at most this long
+	lockTTL = 10 * time.Minute
+	// how often a running scrape pushes its lock's expiry out
+	lockRenew = time.Minute
+	// for each lock and record write, firestore retries an unreachable backend until the context ends
+	bookkeepingTimeout = 30 * time.Second
+)
+
+// returned when another run or import holds the lock, nothing was fetched or written
+var ErrRunning = errors.New("another scrape is running")
+
+// the run was stopped because another one took the lock over, after it expired
+var errLockLost = errors.New("lost the scrape lock")
+
+// scrapes, validates and publishes a term
+// on any error the live data is untouched and the checkpoint is kept so a resume picks up from there
+// dry runs and runs without firestore skip the lock and aren't recorded
+// only dry runs can filter subjects, see ErrPartialDataset
+func Run(ctx context.Context, opts Options) (*Result, error) {
+	if len(opts.Subjects) > 0 && !opts.DryRun {
+		return nil, ErrPartialDataset
+	}
+	if opts.DryRun || firestore.Client == nil {
+		return run(ctx, opts)
+	}
+
+	host, _ := os.Hostname()
+	rec := &types.ScrapeRun{
+		ID:        firestore.NewScrapeRunID(),
+		Trigger:   opts.Trigger,
+		Host:      host,
+		Term:      opts.Term,
+		Status:    types.ScrapeRunning,
+		StartedAt: time.Now(),
+	}
+	var res *Result
+	err := Locked(ctx, rec.ID, func(ctx context.Context) error {
+		saveRun(ctx, rec)
+		var err error
+		res, err = run(ctx, opts)
+		if err != nil && errors.Is(context.Cause(ctx), errLockLost) {
+			err = fmt.Errorf("%w: %w", errLockLost, err)
+		}
+
+		// recorded before the lock is released, so status never shows a finished run as abandoned
+		finished := time.Now()
+		rec.FinishedAt = &finished
+		rec.Status = types.ScrapeSucceeded
+		if err != nil {
+			rec.Status = types.ScrapeFailed
+			rec.Error = err.Error()
+		}
+		if res != nil {
+			rec.Version = res.Version
+			rec.Rooms = len(res.Dataset.Rooms)
+			rec.Sections = len(res.Dataset.Sections)
+			rec.Instructors = len(res.Dataset.Instructors)
+			rec.FailedSubjects = res.Failed
+			if res.Report != nil {
+				rec.FailedChecks = res.Report.Failed()
+			}
+		}
+		saveRun(context.WithoutCancel(ctx), rec)
+		return err
+	})
+	return res, err
+}
+
+// runs fn holding meta/scrape_lock, ErrRunning if another scrape or import holds it
+// id is who holds it (a scrape run's ID, or "import-..."), fn's ctx is cancelled if another run
+// takes the lock over after it expired and the error then wraps errLockLost
+// without firestore there is nothing to lock and fn just runs
+func Locked(ctx context.Context, id string, fn func(ctx context.Context) error) error {
+	if firestore.Client == nil {
+		return fn(ctx)
+	}
+
+	host, _ := os.Hostname()
+	now := time.Now()
+	lockCtx, cancelLock := context.WithTimeout(ctx, bookkeepingTimeout)
+	err := firestore.AcquireScrapeLock(lockCtx, types.ScrapeLock{RunID: id, Host: host, AcquiredAt: now, ExpiresAt: now.Add(lockTTL)})
+	cancelLock()
+	if errors.Is(err, firestore.ErrLocked) {
+		return ErrRunning
+	}
+	if err != nil {
+		return fmt.Errorf("taking the scrape lock: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), bookkeepingTimeout)
+		defer cancel()
+		if err := firestore.ReleaseScrapeLock(ctx, id); err != nil {
+			slog.Warn("releasing the scrape lock, it expires on its own", "err", err)
+		}
+	}()
+
+	runCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	go holdLock(runCtx, cancel, id)
+
+	err = fn(runCtx)
+	if err != nil && errors.Is(context.Cause(runCtx), errLockLost) && !errors.Is(err, errLockLost) {
+		err = fmt.Errorf("%w: %w", errLockLost, err)
+	}
+	return err
+}
+
+// a lock holder ID for a dataset import, imports don't have a run record
+func ImportLockID() string {
+	return fmt.Sprintf("import-%d", time.Now().UnixNano())
+}
+
+// best effort, a missing record doesn't fail the scrape
+func saveRun(ctx context.Context, rec *types.ScrapeRun) {
+	ctx, cancel := context.WithTimeout(ctx, bookkeepingTimeout)
+	defer cancel()
+	if err := firestore.SaveScrapeRun(ctx, rec); err != nil {
+		slog.Warn("recording scrape run", "run", rec.ID, "err", err)
+	}
+}
+
+// renews the lock until ctx is done, cancels the run if someone else took it
+// other errors are retried, the lock only goes to another run after lockTTL without a renewal
+func holdLock(ctx context.Context, cancel context.CancelCauseFunc, runID string) {
+	ticker := time.NewTicker(lockRenew)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			renewCtx, cancelRenew := context.WithTimeout(ctx, bookkeepingTimeout)
+			err := firestore.RenewScrapeLock(renewCtx, runID, now.Add(lockTTL))
+			cancelRenew()
+			if errors.Is(err, firestore.ErrLocked) {
+				slog.Error("another run took the scrape lock, stopping")
+				cancel(errLockLost)
+				return
+			}
+			if err != nil && ctx.Err() == nil {
+				slog.Warn("renewing the scrape lock", "err", err)
+			}
+		}
+	}
+}
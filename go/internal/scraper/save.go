@@ -0,0 +1,23 @@
+package scraper
+
+import (
+	"context"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/store"
+)
+
+// how many snapshots Publish keeps by default
+const DefaultKeep = firestore.KeepSnapshots
+
+// writes the dataset into a new, unpublished snapshot, see firestore.WriteSnapshot
+func Save(ctx context.Context, ds store.Dataset) (string, error) {
+	metrics.ScrapeRooms.Set(float64(len(ds.Rooms)))
+	return firestore.WriteSnapshot(ctx, ds)
+}
+
+// flips the API over to the snapshot and deletes all but the newest keep snapshots
+func Publish(ctx context.Context, version string, keep int) error {
+	return firestore.PublishAndPrune(ctx, version, keep)
+}
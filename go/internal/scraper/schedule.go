@@ -0,0 +1,101 @@
+package scraper
+
+// scheduled scrapes, for the server (SCRAPE_SCHEDULE) and `scraper daemon`
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/cron"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// nightly, after banner's overnight batch jobs and before anyone is looking for a room
+const DefaultSchedule = "CRON_TZ=America/New_York 0 3 * * *"
+
+// this process's schedule, for GetStatus
+var scheduled struct {
+	sync.Mutex
+	expr string
+	next time.Time
+}
+
+// runs a scrape every time the schedule fires until ctx is done
+// a run still going when the next one is due makes it skip that one, so do runs on other
+// instances (the lock), done is called after every run that was attempted and can be nil
+func Schedule(ctx context.Context, sched *cron.Schedule, opts Options, done func(*Result, error)) {
+	for {
+		next := sched.Next(time.Now())
+		if next.IsZero() {
+			slog.Error("scrape schedule never fires", "schedule", sched.String())
+			return
+		}
+		scheduled.Lock()
+		scheduled.expr, scheduled.next = sched.String(), next
+		scheduled.Unlock()
+		slog.Info("next scheduled scrape", "at", next, "term", opts.Term)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		started := time.Now()
+		res, err := Run(ctx, opts)
+		metrics.ScrapeDuration.Set(time.Since(started).Seconds())
+		switch {
+		case errors.Is(err, ErrRunning):
+			slog.Warn("skipping scheduled scrape, another one is running")
+		case err != nil:
+			slog.Error("scheduled scrape failed", "err", err, "duration", time.Since(started).Round(time.Second))
+		default:
+			slog.Info("scheduled scrape published", "snapshot", res.Version, "duration", time.Since(started).Round(time.Second))
+		}
+		if done != nil {
+			done(res, err)
+		}
+	}
+}
+
+// what GET /api/admin/scrapes shows
+type Status struct {
+	Schedule string            `json:"schedule,omitempty"` // empty when this process doesn't schedule scrapes
+	Next     *time.Time        `json:"next,omitempty"`
+	Lock     *types.ScrapeLock `json:"lock"` // held by the running scrape, null when none is running
+	Runs     []types.ScrapeRun `json:"runs"` // newest first
+}
+
+// the schedule, the lock and the newest limit runs
+// runs that say they are running but don't hold the lock died and are reported as abandoned
+func GetStatus(ctx context.Context, limit int) (*Status, error) {
+	st := &Status{}
+	scheduled.Lock()
+	if scheduled.expr != "" {
+		st.Schedule = scheduled.expr
+		next := scheduled.next
+		st.Next = &next
+	}
+	scheduled.Unlock()
+
+	var err error
+	if st.Lock, err = firestore.GetScrapeLock(ctx, time.Now()); err != nil {
+		return nil, err
+	}
+	if st.Runs, err = firestore.ListScrapeRuns(ctx, limit); err != nil {
+		return nil, err
+	}
+	for i, run := range st.Runs {
+		if run.Status == types.ScrapeRunning && (st.Lock == nil || st.Lock.RunID != run.ID) {
+			st.Runs[i].Status = types.ScrapeAbandoned
+		}
+	}
+	return st, nil
+}
@@ -0,0 +1,262 @@
+package scraper
+
+// sanity checks between aggregating a scrape and publishing it
+// a banner outage or an HTML change shows up as a scrape that is much smaller than the last one,
+// so most checks compare against the dataset the API is serving right now
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/store"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// limits a scrape has to stay within to be published
+// drops are fractions of the previous dataset, 0.2 = at most 20% fewer
+type Thresholds struct {
+	MinRooms         int     `json:"min_rooms"`
+	MaxRoomDrop      float64 `json:"max_room_drop"`
+	MaxMeetingDrop   float64 `json:"max_meeting_drop"`
+	MaxBuildingDrop  float64 `json:"max_building_drop"`
+	MinBuildingRooms int     `json:"min_building_rooms"` // smaller buildings are too noisy for MaxBuildingDrop
+	MaxEmptySubjects float64 `json:"max_empty_subjects"` // fraction of subjects with no sections
+	MaxBadMeetings   int     `json:"max_bad_meetings"`
+}
+
+var DefaultThresholds = Thresholds{
+	MinRooms:         50,
+	MaxRoomDrop:      0.2,
+	MaxMeetingDrop:   0.25,
+	MaxBuildingDrop:  0.5,
+	MinBuildingRooms: 5,
+	MaxEmptySubjects: 0.25,
+	MaxBadMeetings:   0,
+}
+
+// room and meeting counts of a dataset, overall and per building
+type Summary struct {
+	Rooms     int            `json:"rooms"`
+	Meetings  int            `json:"meetings"`
+	Buildings map[string]int `json:"buildings"` // rooms per building
+}
+
+func Summarize(rooms []types.Room) Summary {
+	s := Summary{Rooms: len(rooms), Buildings: make(map[string]int)}
+	for _, r := range rooms {
+		s.Meetings += len(r.Schedule)
+		s.Buildings[r.Building]++
+	}
+	return s
+}
+
+// summary of the dataset the API serves now, nil if there is nothing yet
+func PreviousSummary(ctx context.Context) (*Summary, error) {
+	rooms, err := firestore.GetAllRooms(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return SummaryOf(rooms), nil
+}
+
+// Summarize for validating against, nil for no rooms (nothing was published yet)
+func SummaryOf(rooms []types.Room) *Summary {
+	if len(rooms) == 0 {
+		return nil
+	}
+	s := Summarize(rooms)
+	return &s
+}
+
+// one check of the report
+type Check struct {
+	Name     string  `json:"name"`
+	OK       bool    `json:"ok"`
+	Value    float64 `json:"value"`
+	Limit    float64 `json:"limit"`
+	Previous *int    `json:"previous,omitempty"`
+	Detail   string  `json:"detail,omitempty"`
+}
+
+// rooms of a building now and before
+type BuildingDelta struct {
+	Building string  `json:"building"`
+	Rooms    int     `json:"rooms"`
+	Previous int     `json:"previous"`
+	Change   float64 `json:"change"` // -0.5 = half the rooms are gone
+	OK       bool    `json:"ok"`
+}
+
+// a meeting no room can have
+type BadMeeting struct {
+	RoomID    string `json:"room_id"`
+	CRN       string `json:"crn,omitempty"`
+	Day       int    `json:"day"`
+	StartTime int    `json:"start_time"`
+	EndTime   int    `json:"end_time"`
+}
+
+// machine readable result of Validate, written next to the checkpoint
+type Report struct {
+	Term          string          `json:"term"`
+	CheckedAt     time.Time       `json:"checked_at"`
+	Passed        bool            `json:"passed"`
+	Thresholds    Thresholds      `json:"thresholds"`
+	Current       Summary         `json:"current"`
+	Previous      *Summary        `json:"previous,omitempty"` // nil on the first scrape
+	Checks        []Check         `json:"checks"`
+	Buildings     []BuildingDelta `json:"buildings,omitempty"` // only buildings that changed
+	EmptySubjects []string        `json:"empty_subjects,omitempty"`
+	BadMeetings   []BadMeeting    `json:"bad_meetings,omitempty"`
+}
+
+// names of the checks that failed
+func (r *Report) Failed() []string {
+	var names []string
+	for _, c := range r.Checks {
+		if !c.OK {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+// writes the report as indented JSON
+func (r *Report) WriteFile(path string) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+// checks a scrape against the thresholds and the previous dataset (nil if there is none)
+// subjects is how many subjects were fetched, empty the ones that came back without sections
+func Validate(ds store.Dataset, prev *Summary, subjects int, empty []string, t Thresholds) *Report {
+	cur := Summarize(ds.Rooms)
+	r := &Report{
+		Term:          ds.Term,
+		CheckedAt:     time.Now(),
+		Thresholds:    t,
+		Current:       cur,
+		Previous:      prev,
+		EmptySubjects: empty,
+	}
+
+	r.Checks = append(r.Checks, Check{
+		Name:  "min_rooms",
+		OK:    cur.Rooms >= t.MinRooms,
+		Value: float64(cur.Rooms),
+		Limit: float64(t.MinRooms),
+	})
+
+	if prev != nil {
+		r.Checks = append(r.Checks,
+			dropCheck("room_drop", cur.Rooms, prev.Rooms, t.MaxRoomDrop),
+			dropCheck("meeting_drop", cur.Meetings, prev.Meetings, t.MaxMeetingDrop),
+		)
+
+		var failed []string
+		worst := 0.0
+		for b := range union(cur.Buildings, prev.Buildings) {
+			now, before := cur.Buildings[b], prev.Buildings[b]
+			if now == before {
+				continue
+			}
+			d := BuildingDelta{Building: b, Rooms: now, Previous: before, Change: change(now, before), OK: true}
+			if before >= t.MinBuildingRooms {
+				worst = max(worst, -d.Change)
+				if -d.Change > t.MaxBuildingDrop {
+					d.OK = false
+					failed = append(failed, b)
+				}
+			}
+			r.Buildings = append(r.Buildings, d)
+		}
+		sort.Slice(r.Buildings, func(i, j int) bool { return r.Buildings[i].Change < r.Buildings[j].Change })
+		sort.Strings(failed)
+		// value is the largest drop of any building big enough to count
+		c := Check{Name: "building_drop", OK: len(failed) == 0, Value: worst, Limit: t.MaxBuildingDrop}
+		if len(failed) > 0 {
+			c.Detail = fmt.Sprintf("buildings lost more than %.0f%% of their rooms: %v", t.MaxBuildingDrop*100, failed)
+		}
+		r.Checks = append(r.Checks, c)
+	}
+
+	emptyFrac := 0.0
+	if subjects > 0 {
+		emptyFrac = float64(len(empty)) / float64(subjects)
+	}
+	r.Checks = append(r.Checks, Check{
+		Name:   "empty_subjects",
+		OK:     emptyFrac <= t.MaxEmptySubjects,
+		Value:  emptyFrac,
+		Limit:  t.MaxEmptySubjects,
+		Detail: fmt.Sprintf("%d of %d subjects have no sections", len(empty), subjects),
+	})
+
+	r.BadMeetings = badMeetings(ds.Rooms)
+	r.Checks = append(r.Checks, Check{
+		Name:  "bad_meetings",
+		OK:    len(r.BadMeetings) <= t.MaxBadMeetings,
+		Value: float64(len(r.BadMeetings)),
+		Limit: float64(t.MaxBadMeetings),
+	})
+
+	r.Passed = len(r.Failed()) == 0
+	return r
+}
+
+func dropCheck(name string, now, before int, limit float64) Check {
+	c := change(now, before)
+	return Check{
+		Name:     name,
+		OK:       -c <= limit,
+		Value:    -c,
+		Limit:    limit,
+		Previous: &before,
+		Detail:   fmt.Sprintf("%d now, %d before", now, before),
+	}
+}
+
+// relative change from before to now, 0 when before is 0
+func change(now, before int) float64 {
+	if before == 0 {
+		return 0
+	}
+	return float64(now-before) / float64(before)
+}
+
+func union(a, b map[string]int) map[string]bool {
+	keys := make(map[string]bool, len(a))
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	return keys
+}
+
+// meetings outside a day, ending before they start, or on a day that doesn't exist
+func badMeetings(rooms []types.Room) []BadMeeting {
+	var bad []BadMeeting
+	for _, r := range rooms {
+		for _, m := range r.Schedule {
+			if m.Day >= 0 && m.Day <= 6 && m.StartTime >= 0 && m.EndTime <= 24*60 && m.StartTime < m.EndTime {
+				continue
+			}
+			b := BadMeeting{RoomID: r.ID, Day: m.Day, StartTime: m.StartTime, EndTime: m.EndTime}
+			if len(m.Label) > 0 {
+				b.CRN = m.Label[0].ID
+			}
+			bad = append(bad, b)
+		}
+	}
+	return bad
+}
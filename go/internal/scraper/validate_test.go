@@ -0,0 +1,127 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/store"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// n rooms in building with meetings classes each, monday 9:00-10:15
+func rooms(building string, n, meetings int) []types.Room {
+	list := make([]types.Room, n)
+	for i := range list {
+		list[i] = types.Room{ID: fmt.Sprintf("%s_%d", building, 1000+i), Building: building, Number: fmt.Sprint(1000 + i)}
+		for range meetings {
+			list[i].Schedule = append(list[i].Schedule, types.Meeting{Day: 1, StartTime: 9 * 60, EndTime: 10*60 + 15})
+		}
+	}
+	return list
+}
+
+func dataset(parts ...[]types.Room) store.Dataset {
+	return store.Dataset{Term: "202610", Rooms: slices.Concat(parts...)}
+}
+
+func TestValidate(t *testing.T) {
+	// 84 rooms, 168 meetings, TINY is under MinBuildingRooms
+	live := dataset(rooms("HORIZN", 40, 2), rooms("ENGR", 40, 2), rooms("TINY", 4, 2))
+	prev := SummaryOf(live.Rooms)
+
+	broken := rooms("HORIZN", 40, 2)
+	broken[0].Schedule[0].EndTime = broken[0].Schedule[0].StartTime - 30
+
+	tests := []struct {
+		name     string
+		ds       store.Dataset
+		prev     *Summary
+		subjects int
+		empty    []string
+		failed   []string
+	}{
+		{"same as before", live, prev, 10, nil, nil},
+		{"first scrape", live, nil, 10, nil, nil},
+		{"first scrape too small", dataset(rooms("HORIZN", 30, 2)), nil, 10, nil, []string{"min_rooms"}},
+		// 74 of 84 rooms, 12% fewer, ENGR lost a quarter
+		{"small drop", dataset(rooms("HORIZN", 40, 2), rooms("ENGR", 30, 2), rooms("TINY", 4, 2)), prev, 10, nil, nil},
+		// 64 of 84 rooms, 24% fewer, ENGR lost half which is still allowed
+		{"room drop", dataset(rooms("HORIZN", 40, 2), rooms("ENGR", 20, 2), rooms("TINY", 4, 2)), prev, 10, nil, []string{"room_drop"}},
+		{"meeting drop", dataset(rooms("HORIZN", 40, 1), rooms("ENGR", 40, 1), rooms("TINY", 4, 1)), prev, 10, nil, []string{"meeting_drop"}},
+		// same room count overall, but ENGR is gone
+		{"building gone", dataset(rooms("HORIZN", 40, 2), rooms("ARTS", 40, 2), rooms("TINY", 4, 2)), prev, 10, nil, []string{"building_drop"}},
+		{"small building gone", dataset(rooms("HORIZN", 40, 2), rooms("ENGR", 40, 2)), prev, 10, nil, nil},
+		{"some empty subjects", live, prev, 10, []string{"ARAB", "GAME"}, nil},
+		{"too many empty subjects", live, prev, 10, []string{"ARAB", "GAME", "CS"}, []string{"empty_subjects"}},
+		{"bad meeting", dataset(broken, rooms("ENGR", 40, 2), rooms("TINY", 4, 2)), prev, 10, nil, []string{"bad_meetings"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Validate(tt.ds, tt.prev, tt.subjects, tt.empty, DefaultThresholds)
+			if !slices.Equal(r.Failed(), tt.failed) {
+				t.Errorf("failed %v, want %v", r.Failed(), tt.failed)
+			}
+			if r.Passed != (len(tt.failed) == 0) {
+				t.Errorf("passed %v with %v failing", r.Passed, r.Failed())
+			}
+		})
+	}
+}
+
+func TestValidateBuildings(t *testing.T) {
+	prev := SummaryOf(dataset(rooms("HORIZN", 40, 2), rooms("ENGR", 40, 2), rooms("TINY", 4, 2)).Rooms)
+	r := Validate(dataset(rooms("HORIZN", 40, 2), rooms("ENGR", 10, 2), rooms("ARTS", 34, 2)), prev, 10, nil, DefaultThresholds)
+
+	// most rooms lost first, unchanged buildings left out
+	want := []BuildingDelta{
+		{Building: "TINY", Rooms: 0, Previous: 4, Change: -1, OK: true},
+		{Building: "ENGR", Rooms: 10, Previous: 40, Change: -0.75, OK: false},
+		{Building: "ARTS", Rooms: 34, Previous: 0, Change: 0, OK: true},
+	}
+	if !slices.Equal(r.Buildings, want) {
+		t.Errorf("buildings %+v, want %+v", r.Buildings, want)
+	}
+	for _, c := range r.Checks {
+		if c.Name == "building_drop" && (c.OK || c.Value != 0.75) {
+			t.Errorf("building_drop %+v, want a failure at 0.75", c)
+		}
+	}
+}
+
+func TestCheckReport(t *testing.T) {
+	failed := Validate(dataset(rooms("HORIZN", 30, 2)), nil, 10, nil, DefaultThresholds)
+	passed := Validate(dataset(rooms("HORIZN", 60, 2)), nil, 10, nil, DefaultThresholds)
+
+	tests := []struct {
+		name   string
+		opts   Options
+		report *Report
+		stop   bool
+	}{
+		{"passed", Options{}, passed, false},
+		{"failed", Options{}, failed, true},
+		{"failed dry run", Options{DryRun: true}, failed, false},
+		{"failed with force", Options{Force: true}, failed, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.checkReport(tt.report)
+			if tt.stop != errors.Is(err, ErrValidation) {
+				t.Errorf("got %v, want stopped %v", err, tt.stop)
+			}
+		})
+	}
+}
+
+// a subject filter never gets as far as banner or firestore unless it's a dry run
+func TestRunRefusesPartialDataset(t *testing.T) {
+	opts := DefaultOptions()
+	opts.BaseURL = "http://127.0.0.1:0"
+	opts.Subjects = []string{"CS"}
+	if _, err := Run(context.Background(), opts); !errors.Is(err, ErrPartialDataset) {
+		t.Errorf("got %v, want ErrPartialDataset", err)
+	}
+}
@@ -0,0 +1,166 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	db "github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// nicknames students actually type, on top of the code and official name
+var buildingAliases = map[string][]string{
+	"JC":     {"Johnson Center", "JC"},
+	"SUBI":   {"SUB I", "SUB 1", "Student Union"},
+	"ENGR":   {"Engineering", "Nguyen"},
+	"FENWCK": {"Fenwick", "Library"},
+	"HORIZN": {"Horizon"},
+	"EXPL":   {"Exploratory"},
+	"PETRSN": {"Peterson"},
+	"RAC":    {"Rec", "Gym"},
+	"AFC":    {"Aquatics", "Pool"},
+	"MERTEN": {"Merten"},
+}
+
+var current atomic.Pointer[Index]
+
+// the index the API is serving, empty until the first Rebuild finishes
+func Current() *Index {
+	if idx := current.Load(); idx != nil {
+		return idx
+	}
+	return NewIndex(nil)
+}
+
+// reads rooms, sections and instructors and swaps in a fresh index
+func Rebuild(ctx context.Context) error {
+	rooms, err := db.GetAllRooms(ctx)
+	if err != nil {
+		return fmt.Errorf("reading rooms: %w", err)
+	}
+	sections, err := db.GetAllSections(ctx)
+	if err != nil {
+		return fmt.Errorf("reading sections: %w", err)
+	}
+	instructors, err := db.GetAllInstructors(ctx)
+	if err != nil {
+		return fmt.Errorf("reading instructors: %w", err)
+	}
+
+	idx := NewIndex(BuildDocs(types.Buildings, rooms, sections, instructors))
+	current.Store(idx)
+	slog.Info("search: index rebuilt", "docs", idx.Len())
+	return nil
+}
+
+// turns the dataset into search docs
+func BuildDocs(buildings map[string]types.BuildingInfo, rooms []types.Room, sections []types.Section, instructors []types.Instructor) []Doc {
+	var docs []Doc
+
+	for code, b := range buildings {
+		docs = append(docs, Doc{
+			Type:     TypeBuilding,
+			ID:       code,
+			Title:    b.Name,
+			Subtitle: code,
+			keywords: buildingAliases[code],
+			building: code,
+		})
+	}
+
+	for _, r := range rooms {
+		name := r.Building
+		if b, ok := buildings[r.Building]; ok {
+			name = b.Name
+		}
+		docs = append(docs, Doc{
+			Type:     TypeRoom,
+			ID:       r.ID,
+			Title:    r.Building + " " + r.Number,
+			Subtitle: name,
+			keywords: buildingAliases[r.Building],
+			building: r.Building,
+		})
+	}
+
+	// one doc per course, with every CRN as a keyword so "10492" finds it too
+	type course struct {
+		doc  Doc
+		crns []string
+	}
+	courses := make(map[string]*course)
+	for _, s := range sections {
+		c, ok := courses[s.CourseID]
+		if !ok {
+			c = &course{doc: Doc{
+				Type:     TypeCourse,
+				ID:       s.CourseID,
+				Title:    s.Subject + " " + s.CourseNumber,
+				Subtitle: s.Title,
+			}}
+			courses[s.CourseID] = c
+		}
+		c.crns = append(c.crns, s.CRN)
+	}
+	ids := make([]string, 0, len(courses))
+	for id := range courses {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		c := courses[id]
+		c.doc.keywords = c.crns
+		docs = append(docs, c.doc)
+	}
+
+	for _, inst := range instructors {
+		docs = append(docs, Doc{
+			Type:     TypeInstructor,
+			ID:       inst.ID,
+			Title:    inst.Name,
+			Subtitle: inst.Email,
+		})
+	}
+	return docs
+}
+
+// builds the index at startup, then rebuilds it whenever the scraper marks the dataset as updated
+func Run(ctx context.Context, every time.Duration) {
+	var built time.Time
+	ready := false
+	check := func() {
+		ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+		defer cancel()
+
+		updated, err := db.GetDatasetUpdatedAt(ctx)
+		if err != nil {
+			slog.Error("search: rebuilding index", "err", err)
+			return
+		}
+		if ready && !updated.After(built) {
+			return
+		}
+		if err := Rebuild(ctx); err != nil {
+			slog.Error("search: rebuilding index", "err", err)
+			return
+		}
+		built = updated
+		ready = true
+	}
+
+	check()
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package search
+
+// small in-memory inverted index over rooms, buildings, courses and instructors
+// the whole dataset is a few thousand docs, so we just rebuild it from scratch after every scrape
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// result types
+const (
+	TypeBuilding   = "building"
+	TypeRoom       = "room"
+	TypeCourse     = "course"
+	TypeInstructor = "instructor"
+)
+
+// something that can show up in search results
+type Doc struct {
+	Type     string `json:"type"`
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle,omitempty"`
+
+	// extra text that should match but isn't displayed (aliases, CRNs...)
+	keywords []string
+	// building code of building and room docs, for hiding them
+	building string
+}
+
+type Result struct {
+	Doc
+	Score float64 `json:"score"`
+}
+
+type Index struct {
+	docs  []Doc
+	terms map[string][]int // term -> doc positions
+	// sorted keys of terms for prefix lookups
+	sorted []string
+}
+
+// how much a query token is worth depending on how it matched
+const (
+	exactScore  = 3
+	prefixScore = 2
+	fuzzyScore  = 1
+)
+
+// small nudge so buildings and courses beat rooms with the same score
+var typeBoost = map[string]float64{
+	TypeBuilding:   0.3,
+	TypeCourse:     0.2,
+	TypeInstructor: 0.1,
+	TypeRoom:       0,
+}
+
+func NewIndex(docs []Doc) *Index {
+	idx := &Index{docs: docs, terms: make(map[string][]int)}
+	for i, d := range docs {
+		seen := make(map[string]bool)
+		text := append([]string{d.Title, d.Subtitle, d.ID}, d.keywords...)
+		for _, t := range tokenize(strings.Join(text, " "), true) {
+			if seen[t] {
+				continue
+			}
+			seen[t] = true
+			idx.terms[t] = append(idx.terms[t], i)
+		}
+	}
+	for t := range idx.terms {
+		idx.sorted = append(idx.sorted, t)
+	}
+	sort.Strings(idx.sorted)
+	return idx
+}
+
+func (idx *Index) Len() int {
+	return len(idx.docs)
+}
+
+// returns the best matches for q, every query token has to match a doc somehow
+// (exactly, as a prefix, or within a small edit distance) for the doc to be returned
+// typ filters by result type when set, buildings for which hidden returns true are left out with their rooms
+func (idx *Index) Search(q string, typ string, limit int, hidden func(building string) bool) []Result {
+	tokens := tokenize(q, false)
+	if len(tokens) == 0 {
+		return []Result{}
+	}
+
+	var scores map[int]float64
+	for _, tok := range tokens {
+		matched := idx.match(tok)
+		if scores == nil {
+			scores = matched
+			continue
+		}
+		// AND: keep docs that matched every token so far
+		for doc, s := range scores {
+			if m, ok := matched[doc]; ok {
+				scores[doc] = s + m
+			} else {
+				delete(scores, doc)
+			}
+		}
+	}
+
+	results := []Result{}
+	for i, s := range scores {
+		d := idx.docs[i]
+		if typ != "" && d.Type != typ {
+			continue
+		}
+		if d.building != "" && hidden != nil && hidden(d.building) {
+			continue
+		}
+		results = append(results, Result{Doc: d, Score: s + typeBoost[d.Type]})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Title < results[j].Title
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// scores every doc matching a single query token, keeping the best way it matched
+func (idx *Index) match(tok string) map[int]float64 {
+	scores := make(map[int]float64)
+	add := func(term string, score float64) {
+		for _, doc := range idx.terms[term] {
+			if score > scores[doc] {
+				scores[doc] = score
+			}
+		}
+	}
+
+	add(tok, exactScore)
+
+	// prefix: walk the sorted terms starting at tok
+	for i := sort.SearchStrings(idx.sorted, tok); i < len(idx.sorted); i++ {
+		term := idx.sorted[i]
+		if !strings.HasPrefix(term, tok) {
+			break
+		}
+		if term != tok {
+			add(term, prefixScore)
+		}
+	}
+
+	// fuzzy: only for words long enough that a typo is likely, numbers have to match
+	if max := maxEdits(tok); max > 0 {
+		for _, term := range idx.sorted {
+			if abs(len(term)-len(tok)) > max || term[0] != tok[0] {
+				continue
+			}
+			if levenshtein(tok, term) <= max {
+				add(term, fuzzyScore)
+			}
+		}
+	}
+	return scores
+}
+
+func maxEdits(tok string) int {
+	if strings.IndexFunc(tok, unicode.IsDigit) >= 0 {
+		return 0
+	}
+	switch {
+	case len(tok) >= 8:
+		return 2
+	case len(tok) >= 4:
+		return 1
+	}
+	return 0
+}
+
+// lowercases and splits on anything that isn't a letter or digit
+// when indexing, course codes also get a joined token so a "cs310" query finds "CS 310"
+// (queries aren't joined, "CS 310" already matches both halves)
+func tokenize(s string, join bool) []string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := make([]string, 0, len(fields)+1)
+	for i, f := range fields {
+		tokens = append(tokens, f)
+		if join && i+1 < len(fields) && isAlpha(f) && isCourseNumber(fields[i+1]) {
+			tokens = append(tokens, f+fields[i+1])
+		}
+	}
+	return tokens
+}
+
+func isAlpha(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool { return !unicode.IsLetter(r) }) < 0
+}
+
+// "310", "499a"
+func isCourseNumber(s string) bool {
+	return len(s) >= 3 && len(s) <= 4 && unicode.IsDigit(rune(s[0])) && unicode.IsDigit(rune(s[1])) && unicode.IsDigit(rune(s[2]))
+}
+
+// classic two-row edit distance
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,154 @@
+package search
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+func testIndex() *Index {
+	buildings := map[string]types.BuildingInfo{
+		"JC":     {Name: "Johnson Center"},
+		"HORIZN": {Name: "Horizon Hall"},
+		"ENGR":   {Name: "Nguyen Engineering Building"},
+	}
+	rooms := []types.Room{
+		{ID: "HORIZN_2014", Building: "HORIZN", Number: "2014"},
+		{ID: "HORIZN_2016", Building: "HORIZN", Number: "2016"},
+		{ID: "JC_B105", Building: "JC", Number: "B105"},
+		{ID: "ENGR_1103", Building: "ENGR", Number: "1103"},
+	}
+	sections := []types.Section{
+		{CRN: "10492", Subject: "CS", CourseNumber: "310", CourseID: "CS310", Title: "Data Structures"},
+		{CRN: "10493", Subject: "CS", CourseNumber: "310", CourseID: "CS310", Title: "Data Structures"},
+		{CRN: "10510", Subject: "CS", CourseNumber: "330", CourseID: "CS330", Title: "Formal Methods and Models"},
+		{CRN: "20001", Subject: "MATH", CourseNumber: "113", CourseID: "MATH113", Title: "Analytic Geometry and Calculus I"},
+	}
+	instructors := []types.Instructor{
+		{ID: "jdoe", Name: "Jane Doe", Email: "jdoe@gmu.edu"},
+		{ID: "jsmith", Name: "Johnson Smith", Email: "jsmith@gmu.edu"},
+	}
+	return NewIndex(BuildDocs(buildings, rooms, sections, instructors))
+}
+
+func ids(results []Result) []string {
+	list := make([]string, len(results))
+	for i, r := range results {
+		list[i] = r.ID
+	}
+	return list
+}
+
+func TestSearch(t *testing.T) {
+	idx := testIndex()
+	tests := []struct {
+		name  string
+		q     string
+		typ   string
+		limit int
+		want  []string
+	}{
+		// buildings beat their rooms at the same score, rooms come in title order
+		{"building name", "horizon", "", 0, []string{"HORIZN", "HORIZN_2014", "HORIZN_2016"}},
+		{"building code", "horizn", "", 0, []string{"HORIZN", "HORIZN_2014", "HORIZN_2016"}},
+		{"prefix", "hori", "", 0, []string{"HORIZN", "HORIZN_2014", "HORIZN_2016"}},
+		{"alias", "jc", "", 0, []string{"JC", "JC_B105"}},
+		// johnson alone also finds the instructor, below the building
+		{"name shared with an instructor", "johnson", "", 0, []string{"JC", "jsmith", "JC_B105"}},
+		{"every word has to match", "johnson center", "", 0, []string{"JC", "JC_B105"}},
+		{"room number", "horizon 2014", "", 0, []string{"HORIZN_2014"}},
+		// numbers never match fuzzily, 2014 doesn't find 2016
+		{"number", "2014", "", 0, []string{"HORIZN_2014"}},
+		{"course code", "cs310", "", 0, []string{"CS310"}},
+		{"course code with a space", "CS 310", "", 0, []string{"CS310"}},
+		{"subject", "cs", "", 0, []string{"CS310", "CS330"}},
+		{"crn", "10492", "", 0, []string{"CS310"}},
+		{"crn prefix", "1051", "", 0, []string{"CS330"}},
+		{"course title", "data structures", "", 0, []string{"CS310"}},
+		{"one typo", "calculs", "", 0, []string{"MATH113"}},
+		{"two typos in a long word", "strucures", "", 0, []string{"CS310"}},
+		{"short words need to match", "dta", "", 0, []string{}},
+		{"typo in the first letter", "fata", "", 0, []string{}},
+		{"instructor", "jane", "", 0, []string{"jdoe"}},
+		{"instructor email", "jdoe", "", 0, []string{"jdoe"}},
+		{"type filter", "horizon", TypeRoom, 0, []string{"HORIZN_2014", "HORIZN_2016"}},
+		{"limit", "horizon", "", 1, []string{"HORIZN"}},
+		{"punctuation only", "--", "", 0, []string{}},
+		{"nothing", "zzzz", "", 0, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(idx.Search(tt.q, tt.typ, tt.limit, nil)); !slices.Equal(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.q, got, tt.want)
+			}
+		})
+	}
+}
+
+// an exact match beats a prefix, which beats a typo
+func TestSearchRanking(t *testing.T) {
+	idx := NewIndex([]Doc{
+		{Type: TypeCourse, ID: "typo", Title: "Modal"},
+		{Type: TypeCourse, ID: "prefix", Title: "Models"},
+		{Type: TypeCourse, ID: "exact", Title: "Model"},
+	})
+	got := idx.Search("model", "", 0, nil)
+	if !slices.Equal(ids(got), []string{"exact", "prefix", "typo"}) {
+		t.Fatalf("got %v", ids(got))
+	}
+	if got[0].Score != exactScore+typeBoost[TypeCourse] || got[1].Score != prefixScore+typeBoost[TypeCourse] {
+		t.Errorf("scores %v %v", got[0].Score, got[1].Score)
+	}
+}
+
+func TestSearchHidden(t *testing.T) {
+	hidden := func(b string) bool { return b == "HORIZN" }
+	if got := ids(testIndex().Search("hall", "", 0, hidden)); len(got) != 0 {
+		t.Errorf("hidden building and rooms found: %v", got)
+	}
+	// courses aren't in a building, they stay
+	if got := ids(testIndex().Search("cs310", "", 0, hidden)); !slices.Equal(got, []string{"CS310"}) {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		s    string
+		join bool
+		want []string
+	}{
+		{"CS 310", true, []string{"cs", "cs310", "310"}},
+		{"CS 310", false, []string{"cs", "310"}},
+		{"ECE 499A Senior Design", true, []string{"ece", "ece499a", "499a", "senior", "design"}},
+		// too short for a course number
+		{"HORIZN 20", true, []string{"horizn", "20"}},
+		{"jdoe@gmu.edu", true, []string{"jdoe", "gmu", "edu"}},
+		{"Café, Résumé!", false, []string{"café", "résumé"}},
+	}
+	for _, tt := range tests {
+		if got := tokenize(tt.s, tt.join); !slices.Equal(got, tt.want) {
+			t.Errorf("tokenize(%q, %v) = %v, want %v", tt.s, tt.join, got, tt.want)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"horizon", "horizon", 0},
+		{"horizon", "horzon", 1},
+		{"horizon", "hroizon", 2},
+		{"kitten", "sitting", 3},
+	}
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
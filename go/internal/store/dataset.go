@@ -0,0 +1,182 @@
+package store
+
+// the full room/section/instructor dataset, and moving it between backends
+// a dataset file is either one JSON object (Dataset) or NDJSON, one Record per line:
+//
+//	{"type":"meta","data":{"term":"202610","exported_at":"..."}}
+//	{"type":"building","data":{"code":"HORIZN","name":"Horizon Hall",...}}
+//	{"type":"room","data":{...}}
+//
+// NDJSON streams and diffs line by line, which is what seeding and sharing snapshots wants
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// everything one scrape produces
+type Dataset struct {
+	Term        string             `json:"term"`
+	Rooms       []types.Room       `json:"rooms"`
+	Sections    []types.Section    `json:"sections"`
+	Instructors []types.Instructor `json:"instructors"`
+}
+
+// backends the whole dataset can be read out of and loaded into
+type Datasets interface {
+	// the dataset being served
+	ExportDataset(ctx context.Context) (Dataset, error)
+	// replaces the dataset being served
+	ImportDataset(ctx context.Context, ds Dataset) error
+}
+
+// NDJSON record types
+const (
+	RecordMeta       = "meta"
+	RecordBuilding   = "building"
+	RecordRoom       = "room"
+	RecordSection    = "section"
+	RecordInstructor = "instructor"
+)
+
+// one NDJSON line
+type Record struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+type RecordMetaData struct {
+	Term       string    `json:"term"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+type RecordBuildingData struct {
+	Code string `json:"code"`
+	types.BuildingInfo
+}
+
+// writes the dataset as NDJSON: meta, buildings, rooms, sections, instructors
+// buildings are only there for whoever reads the file, they are compiled in (types.Buildings)
+// and skipped on import
+func WriteNDJSON(w io.Writer, ds Dataset, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	write := func(kind string, v any) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return enc.Encode(Record{Type: kind, Data: data})
+	}
+
+	if err := write(RecordMeta, RecordMetaData{Term: ds.Term, ExportedAt: now.UTC()}); err != nil {
+		return err
+	}
+	codes := make([]string, 0, len(types.Buildings))
+	for code := range types.Buildings {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if err := write(RecordBuilding, RecordBuildingData{Code: code, BuildingInfo: types.Buildings[code]}); err != nil {
+			return err
+		}
+	}
+	for _, r := range ds.Rooms {
+		if err := write(RecordRoom, r); err != nil {
+			return err
+		}
+	}
+	for _, s := range ds.Sections {
+		if err := write(RecordSection, s); err != nil {
+			return err
+		}
+	}
+	for _, i := range ds.Instructors {
+		if err := write(RecordInstructor, i); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// reads either format, told apart by the first value: NDJSON starts with a typed record
+func ReadDataset(r io.Reader) (Dataset, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	var first json.RawMessage
+	if err := dec.Decode(&first); err != nil {
+		return Dataset{}, fmt.Errorf("empty or invalid dataset: %w", err)
+	}
+
+	var rec Record
+	if err := json.Unmarshal(first, &rec); err != nil || rec.Type == "" {
+		var ds Dataset
+		if err := json.Unmarshal(first, &ds); err != nil {
+			return Dataset{}, err
+		}
+		return ds, nil
+	}
+
+	ds := Dataset{Rooms: []types.Room{}, Sections: []types.Section{}, Instructors: []types.Instructor{}}
+	for line := 1; ; line++ {
+		if err := addRecord(&ds, rec); err != nil {
+			return Dataset{}, fmt.Errorf("record %d: %w", line, err)
+		}
+		rec = Record{}
+		if err := dec.Decode(&rec); err == io.EOF {
+			return ds, nil
+		} else if err != nil {
+			return Dataset{}, fmt.Errorf("record %d: %w", line+1, err)
+		}
+	}
+}
+
+func addRecord(ds *Dataset, rec Record) error {
+	switch rec.Type {
+	case RecordMeta:
+		var meta RecordMetaData
+		if err := json.Unmarshal(rec.Data, &meta); err != nil {
+			return err
+		}
+		ds.Term = meta.Term
+	case RecordBuilding:
+	case RecordRoom:
+		var room types.Room
+		if err := json.Unmarshal(rec.Data, &room); err != nil {
+			return err
+		}
+		if room.ID == "" {
+			return errors.New("room without an id")
+		}
+		ds.Rooms = append(ds.Rooms, room)
+	case RecordSection:
+		var section types.Section
+		if err := json.Unmarshal(rec.Data, &section); err != nil {
+			return err
+		}
+		if section.CRN == "" {
+			return errors.New("section without a crn")
+		}
+		ds.Sections = append(ds.Sections, section)
+	case RecordInstructor:
+		var inst types.Instructor
+		if err := json.Unmarshal(rec.Data, &inst); err != nil {
+			return err
+		}
+		if inst.ID == "" {
+			return errors.New("instructor without an id")
+		}
+		ds.Instructors = append(ds.Instructors, inst)
+	default:
+		return fmt.Errorf("unknown record type %q", rec.Type)
+	}
+	return nil
+}
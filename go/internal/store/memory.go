@@ -0,0 +1,71 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// in-memory Rooms and Datasets for local dev, dry runs and imports
+type Memory struct {
+	mu          sync.RWMutex
+	term        string
+	rooms       map[string]types.Room
+	sections    []types.Section
+	instructors []types.Instructor
+}
+
+func NewMemory() *Memory {
+	return &Memory{rooms: make(map[string]types.Room)}
+}
+
+func (m *Memory) SaveRoom(ctx context.Context, room types.Room) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rooms[room.ID] = room
+	return nil
+}
+
+func (m *Memory) ListRooms(ctx context.Context, q RoomQuery) (RoomPage, error) {
+	m.mu.RLock()
+	rooms := make([]types.Room, 0, len(m.rooms))
+	for _, r := range m.rooms {
+		if q.Building == "" || r.Building == q.Building {
+			rooms = append(rooms, r)
+		}
+	}
+	m.mu.RUnlock()
+	return ApplyQuery(rooms, q)
+}
+
+func (m *Memory) ExportDataset(ctx context.Context) (Dataset, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ds := Dataset{
+		Term:        m.term,
+		Rooms:       make([]types.Room, 0, len(m.rooms)),
+		Sections:    append([]types.Section{}, m.sections...),
+		Instructors: append([]types.Instructor{}, m.instructors...),
+	}
+	for _, r := range m.rooms {
+		ds.Rooms = append(ds.Rooms, r)
+	}
+	sort.Slice(ds.Rooms, func(i, j int) bool { return ds.Rooms[i].ID < ds.Rooms[j].ID })
+	return ds, nil
+}
+
+func (m *Memory) ImportDataset(ctx context.Context, ds Dataset) error {
+	rooms := make(map[string]types.Room, len(ds.Rooms))
+	for _, r := range ds.Rooms {
+		rooms[r.ID] = r
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.term = ds.Term
+	m.rooms = rooms
+	m.sections = append([]types.Section{}, ds.Sections...)
+	m.instructors = append([]types.Instructor{}, ds.Instructors...)
+	return nil
+}
@@ -0,0 +1,207 @@
+package store
+
+// storage interface for room listings
+// firestore (internal/firestore) and Memory (memory.go) both implement Rooms with the same
+// sorting, cursor and projection semantics, the shared bits live in this file
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/availability"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// sort keys for ListRooms
+const (
+	SortID           = "id"
+	SortNumber       = "number" // room numbers are strings, so this is text order ("100" < "20" < "2014A")
+	SortCapacity     = "capacity"
+	SortFreeDuration = "free_duration" // computed at RoomQuery.At, needs every room's schedule
+)
+
+// fields that can be projected, names match the firestore doc
+var RoomFields = []string{"id", "building", "number", "capacity", "schedule"}
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type RoomQuery struct {
+	Building string
+	Sort     string // one of the Sort* keys, defaults to SortID
+	Desc     bool
+	Limit    int // 0 = everything
+	Cursor   string
+	// firestore field names to read, empty = all
+	// schedule is always read for SortFreeDuration but dropped again if not requested
+	Fields []string
+	At     time.Time // for SortFreeDuration, defaults to now
+}
+
+type RoomPage struct {
+	Rooms      []types.Room
+	NextCursor string // empty on the last page
+}
+
+type Rooms interface {
+	ListRooms(ctx context.Context, q RoomQuery) (RoomPage, error)
+}
+
+// opaque pagination cursor
+// keyset cursors (Value + ID) for stored fields, offset cursors for computed sorts
+type Cursor struct {
+	Sort   string `json:"s"`
+	Desc   bool   `json:"d,omitempty"`
+	Value  any    `json:"v,omitempty"`
+	ID     string `json:"i,omitempty"`
+	Offset int    `json:"o,omitempty"`
+	At     int64  `json:"a,omitempty"` // unix seconds, keeps computed sorts stable across pages
+}
+
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodes a cursor and makes sure it was made for the same sort
+func DecodeCursor(s string, q RoomQuery) (Cursor, error) {
+	var c Cursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	if c.Sort != q.sortKey() || c.Desc != q.Desc {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+func (q RoomQuery) sortKey() string {
+	if q.Sort == "" {
+		return SortID
+	}
+	return q.Sort
+}
+
+// true if the field should be read
+func (q RoomQuery) Wants(field string) bool {
+	if len(q.Fields) == 0 {
+		return true
+	}
+	for _, f := range q.Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// validates sort and fields
+func (q RoomQuery) Validate() error {
+	switch q.sortKey() {
+	case SortID, SortNumber, SortCapacity, SortFreeDuration:
+	default:
+		return errors.New("sort must be one of id, number, capacity, free_duration")
+	}
+	for _, f := range q.Fields {
+		ok := false
+		for _, known := range RoomFields {
+			ok = ok || f == known
+		}
+		if !ok {
+			return errors.New("unknown field " + f + ", expected one of " + strings.Join(RoomFields, ", "))
+		}
+	}
+	return nil
+}
+
+// minutes a room stays free from at, 0 if it's busy, MaxInt if nothing else is scheduled
+func FreeMinutes(room types.Room, at time.Time) int {
+	state := availability.RoomState(room.Schedule, at)
+	if !state.Free {
+		return 0
+	}
+	if state.FreeUntil.IsZero() {
+		return math.MaxInt
+	}
+	return int(state.FreeUntil.Sub(at).Minutes())
+}
+
+// sorts and pages rooms in memory
+// used by Memory for everything and by firestore for computed sorts
+func ApplyQuery(rooms []types.Room, q RoomQuery) (RoomPage, error) {
+	at := q.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	var cur Cursor
+	if q.Cursor != "" {
+		c, err := DecodeCursor(q.Cursor, q)
+		if err != nil {
+			return RoomPage{}, err
+		}
+		cur = c
+		if c.At != 0 {
+			at = time.Unix(c.At, 0)
+		}
+	}
+
+	var free map[string]int
+	if q.sortKey() == SortFreeDuration {
+		free = make(map[string]int, len(rooms))
+		for _, r := range rooms {
+			free[r.ID] = FreeMinutes(r, at)
+		}
+	}
+
+	less := func(a, b types.Room) bool {
+		switch q.sortKey() {
+		case SortNumber:
+			if a.Number != b.Number {
+				return a.Number < b.Number
+			}
+		case SortCapacity:
+			if a.Capacity != b.Capacity {
+				return a.Capacity < b.Capacity
+			}
+		case SortFreeDuration:
+			if free[a.ID] != free[b.ID] {
+				return free[a.ID] < free[b.ID]
+			}
+		}
+		return a.ID < b.ID
+	}
+	sort.Slice(rooms, func(i, j int) bool {
+		if q.Desc {
+			return less(rooms[j], rooms[i])
+		}
+		return less(rooms[i], rooms[j])
+	})
+
+	// every sort ends in a unique ID tiebreak, so the offset of the cursor is stable
+	start := min(cur.Offset, len(rooms))
+	end := len(rooms)
+	if q.Limit > 0 {
+		end = min(start+q.Limit, len(rooms))
+	}
+
+	page := RoomPage{Rooms: rooms[start:end]}
+	if end < len(rooms) {
+		page.NextCursor = Cursor{Sort: q.sortKey(), Desc: q.Desc, Offset: end, At: at.Unix()}.Encode()
+	}
+	if !q.Wants("schedule") {
+		for i := range page.Rooms {
+			page.Rooms[i].Schedule = nil
+		}
+	}
+	return page, nil
+}
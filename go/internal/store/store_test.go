@@ -0,0 +1,165 @@
+package store
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/availability"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+func testRooms() []types.Room {
+	return []types.Room{
+		{ID: "HORIZN_2014", Building: "HORIZN", Number: "2014", Capacity: 40},
+		{ID: "HORIZN_100", Building: "HORIZN", Number: "100", Capacity: 40},
+		{ID: "ENGR_20", Building: "ENGR", Number: "20", Capacity: 12},
+		{ID: "ENGR_1103", Building: "ENGR", Number: "1103", Capacity: 90},
+		{ID: "JC_B", Building: "JC", Number: "B", Capacity: 0},
+	}
+}
+
+func ids(rooms []types.Room) []string {
+	list := make([]string, len(rooms))
+	for i, r := range rooms {
+		list[i] = r.ID
+	}
+	return list
+}
+
+// every page of q, following the cursors
+func allPages(t *testing.T, q RoomQuery) [][]string {
+	t.Helper()
+	var pages [][]string
+	for range 10 {
+		page, err := ApplyQuery(testRooms(), q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, ids(page.Rooms))
+		if page.NextCursor == "" {
+			return pages
+		}
+		q.Cursor = page.NextCursor
+	}
+	t.Fatal("cursor never ran out")
+	return nil
+}
+
+func TestApplyQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		q    RoomQuery
+		want [][]string
+	}{
+		{"default is id", RoomQuery{},
+			[][]string{{"ENGR_1103", "ENGR_20", "HORIZN_100", "HORIZN_2014", "JC_B"}}},
+		{"id pages", RoomQuery{Limit: 2},
+			[][]string{{"ENGR_1103", "ENGR_20"}, {"HORIZN_100", "HORIZN_2014"}, {"JC_B"}}},
+		{"number is text order", RoomQuery{Sort: SortNumber, Limit: 3},
+			[][]string{{"HORIZN_100", "ENGR_1103", "ENGR_20"}, {"HORIZN_2014", "JC_B"}}},
+		{"capacity ties break on id", RoomQuery{Sort: SortCapacity, Limit: 2},
+			[][]string{{"JC_B", "ENGR_20"}, {"HORIZN_100", "HORIZN_2014"}, {"ENGR_1103"}}},
+		{"capacity desc", RoomQuery{Sort: SortCapacity, Desc: true, Limit: 4},
+			[][]string{{"ENGR_1103", "HORIZN_2014", "HORIZN_100", "ENGR_20"}, {"JC_B"}}},
+		{"limit past the end", RoomQuery{Limit: 50},
+			[][]string{{"ENGR_1103", "ENGR_20", "HORIZN_100", "HORIZN_2014", "JC_B"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := allPages(t, tt.q)
+			if !slices.EqualFunc(got, tt.want, slices.Equal) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyQueryFreeDuration(t *testing.T) {
+	// a Tuesday at 10am on campus
+	at := time.Date(2026, 1, 20, 10, 0, 0, 0, availability.Campus)
+	rooms := []types.Room{
+		{ID: "A", Schedule: []types.Meeting{{Day: 2, StartTime: 9 * 60, EndTime: 11 * 60}}},
+		{ID: "B", Schedule: []types.Meeting{{Day: 2, StartTime: 12 * 60, EndTime: 13 * 60}}},
+		{ID: "C"},
+		{ID: "D", Schedule: []types.Meeting{{Day: 2, StartTime: 10*60 + 30, EndTime: 11 * 60}}},
+	}
+	page, err := ApplyQuery(rooms, RoomQuery{Sort: SortFreeDuration, Desc: true, Limit: 2, At: at})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ids(page.Rooms), []string{"C", "B"}; !slices.Equal(got, want) {
+		t.Errorf("first page %v, want %v", got, want)
+	}
+
+	// the cursor keeps the time of the first page, a later clock doesn't reorder the rest
+	page, err = ApplyQuery(rooms, RoomQuery{Sort: SortFreeDuration, Desc: true, Limit: 2, Cursor: page.NextCursor, At: at.Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ids(page.Rooms), []string{"D", "A"}; !slices.Equal(got, want) {
+		t.Errorf("second page %v, want %v", got, want)
+	}
+	if page.NextCursor != "" {
+		t.Errorf("last page has a cursor")
+	}
+}
+
+func TestApplyQueryDropsSchedule(t *testing.T) {
+	rooms := []types.Room{{ID: "A", Schedule: []types.Meeting{{Day: 1, StartTime: 9 * 60, EndTime: 10 * 60}}}}
+	page, err := ApplyQuery(rooms, RoomQuery{Fields: []string{"id"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Rooms[0].Schedule != nil {
+		t.Errorf("schedule kept without being asked for")
+	}
+}
+
+func TestCursor(t *testing.T) {
+	cur := Cursor{Sort: SortNumber, Value: "2014", ID: "HORIZN_2014"}
+	got, err := DecodeCursor(cur.Encode(), RoomQuery{Sort: SortNumber})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Sort != cur.Sort || got.Value != cur.Value || got.ID != cur.ID || got.Desc {
+		t.Errorf("round trip got %+v, want %+v", got, cur)
+	}
+
+	// json numbers come back as float64, firestore.Store converts them back
+	cur = Cursor{Sort: SortCapacity, Desc: true, Value: 40, ID: "HORIZN_100"}
+	got, err = DecodeCursor(cur.Encode(), RoomQuery{Sort: SortCapacity, Desc: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Value != float64(40) {
+		t.Errorf("capacity came back as %#v", got.Value)
+	}
+
+	// the default sort is id
+	if _, err := DecodeCursor(Cursor{Sort: SortID, ID: "A"}.Encode(), RoomQuery{}); err != nil {
+		t.Errorf("id cursor with the default sort: %v", err)
+	}
+
+	bad := []struct {
+		name   string
+		cursor string
+		q      RoomQuery
+	}{
+		{"not base64", "%%%", RoomQuery{}},
+		{"not json", "bm90IGpzb24", RoomQuery{}},
+		{"other sort", Cursor{Sort: SortNumber, Value: "1"}.Encode(), RoomQuery{Sort: SortCapacity}},
+		{"other direction", Cursor{Sort: SortNumber, Value: "1"}.Encode(), RoomQuery{Sort: SortNumber, Desc: true}},
+	}
+	for _, tt := range bad {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeCursor(tt.cursor, tt.q); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("got %v, want ErrInvalidCursor", err)
+			}
+			if _, err := ApplyQuery(testRooms(), RoomQuery{Sort: tt.q.Sort, Desc: tt.q.Desc, Cursor: tt.cursor}); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("ApplyQuery got %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
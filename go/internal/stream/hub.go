@@ -0,0 +1,281 @@
+package stream
+
+// live room state for GET /api/stream
+// availability only changes when a class starts or ends (always on a minute boundary)
+// or when someone reports on a room, so instead of polling per client we re-evaluate the
+// watched buildings once a minute and right after a report, and fan out only the rooms that changed
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/availability"
+	db "github.com/google-dev-groups-gmu/ghost/go/internal/firestore"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/metrics"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+const (
+	// slow clients get dropped instead of blocking everyone else
+	subscriberBuffer = 64
+
+	// upper bound on open streams, each one holds a connection
+	MaxSubscribers = 2000
+)
+
+var ErrTooManySubscribers = errors.New("too many open streams")
+
+type Subscription struct {
+	Building string
+	C        <-chan types.RoomStatus
+
+	ch chan types.RoomStatus
+}
+
+var (
+	mu    sync.Mutex
+	subs  = make(map[string]map[*Subscription]struct{}) // building -> open streams
+	count int
+	last  = make(map[string]types.RoomStatus) // room -> last state sent
+
+	roomsMu   sync.Mutex
+	rooms     = make(map[string][]types.Room) // building -> rooms, dropped when the dataset changes
+	datasetAt time.Time
+
+	// buildings to re-evaluate right away
+	kick = make(chan string, 64)
+)
+
+// opens a stream for building and returns the current state of its rooms
+func Subscribe(ctx context.Context, building string) (*Subscription, []types.RoomStatus, error) {
+	list, err := buildingRooms(ctx, building)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	reports, err := db.GetActiveReports(ctx, now)
+	if err != nil {
+		slog.Warn("stream: reading reports", "err", err)
+	}
+	overrides := readOverrides(ctx, now)
+	list = overrides.Apply(list)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if count >= MaxSubscribers {
+		return nil, nil, ErrTooManySubscribers
+	}
+
+	ch := make(chan types.RoomStatus, subscriberBuffer)
+	sub := &Subscription{Building: building, C: ch, ch: ch}
+	if subs[building] == nil {
+		subs[building] = make(map[*Subscription]struct{})
+	}
+	subs[building][sub] = struct{}{}
+	count++
+
+	snapshot := make([]types.RoomStatus, len(list))
+	for i, room := range list {
+		snapshot[i] = availability.Status(room, now, reports[room.ID], overrides)
+		if _, ok := last[room.ID]; !ok {
+			last[room.ID] = snapshot[i]
+		}
+	}
+	return sub, snapshot, nil
+}
+
+// closes the stream, safe to call twice
+func Unsubscribe(sub *Subscription) {
+	mu.Lock()
+	defer mu.Unlock()
+	remove(sub)
+}
+
+// must hold mu
+func remove(sub *Subscription) {
+	set := subs[sub.Building]
+	if _, ok := set[sub]; !ok {
+		return
+	}
+	delete(set, sub)
+	close(sub.ch)
+	count--
+	if len(set) == 0 {
+		delete(subs, sub.Building)
+		// nobody is watching, the next subscriber starts from its own snapshot
+		for id, status := range last {
+			if status.Building == sub.Building {
+				delete(last, id)
+			}
+		}
+	}
+}
+
+// re-evaluates a building right away, called after a report is saved
+func Touch(building string) {
+	select {
+	case kick <- building:
+	default:
+		// the next minute tick picks it up
+	}
+}
+
+// re-evaluates watched buildings on every minute boundary and whenever Touch is called
+func Run(ctx context.Context) {
+	timer := time.NewTimer(untilNextMinute(time.Now()))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case building := <-kick:
+			now := time.Now()
+			if reports, overrides, ok := readLive(ctx, now); ok {
+				evaluate(ctx, building, now, reports, overrides)
+			}
+		case now := <-timer.C:
+			refreshDataset(ctx)
+			// reports and overrides are read once for every watched building
+			if buildings := watched(); len(buildings) > 0 {
+				if reports, overrides, ok := readLive(ctx, now); ok {
+					for _, building := range buildings {
+						evaluate(ctx, building, now, reports, overrides)
+					}
+				}
+			}
+			timer.Reset(untilNextMinute(time.Now()))
+		}
+	}
+}
+
+// active reports and overrides at now, false if the reports can't be read
+// (sending every room without its reports would look like they all went away)
+func readLive(ctx context.Context, now time.Time) (map[string][]types.Report, *availability.Overrides, bool) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	reports, err := db.GetActiveReports(ctx, now)
+	if err != nil {
+		slog.Warn("stream: reading reports", "err", err)
+		return nil, nil, false
+	}
+	return reports, readOverrides(ctx, now), true
+}
+
+// a second past the boundary so classes ending at :50 have ended
+func untilNextMinute(now time.Time) time.Duration {
+	return now.Truncate(time.Minute).Add(time.Minute + time.Second).Sub(now)
+}
+
+func watched() []string {
+	mu.Lock()
+	defer mu.Unlock()
+	list := make([]string, 0, len(subs))
+	for b := range subs {
+		list = append(list, b)
+	}
+	return list
+}
+
+// sends every room of building whose state changed since the last send
+func evaluate(ctx context.Context, building string, now time.Time, reports map[string][]types.Report, overrides *availability.Overrides) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	list, err := buildingRooms(ctx, building)
+	if err != nil {
+		slog.Error("stream: reading rooms", "building", building, "err", err)
+		return
+	}
+	list = overrides.Apply(list)
+
+	mu.Lock()
+	defer mu.Unlock()
+	set := subs[building]
+	if len(set) == 0 {
+		return
+	}
+	for _, room := range list {
+		status := availability.Status(room, now, reports[room.ID], overrides)
+		prev, ok := last[room.ID]
+		last[room.ID] = status
+		if ok && !changed(prev, status) {
+			continue
+		}
+		for sub := range set {
+			select {
+			case sub.ch <- status:
+			default:
+				slog.Warn("stream: dropping slow subscriber", "building", building)
+				remove(sub)
+			}
+		}
+	}
+}
+
+// confidence decays every minute on its own, so only the reported status and count count as a change
+func changed(a, b types.RoomStatus) bool {
+	if a.Free != b.Free || !sameTime(a.FreeUntil, b.FreeUntil) || !sameTime(a.BusyUntil, b.BusyUntil) {
+		return true
+	}
+	if (a.Reports == nil) != (b.Reports == nil) {
+		return true
+	}
+	return a.Reports != nil && (a.Reports.Status != b.Reports.Status || a.Reports.Count != b.Reports.Count)
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// admin overrides are best effort, rooms are sent with their scraped state without them
+func readOverrides(ctx context.Context, now time.Time) *availability.Overrides {
+	list, err := db.GetActiveOverrides(ctx, now)
+	if err != nil {
+		slog.Warn("stream: reading overrides", "err", err)
+		return nil
+	}
+	return availability.NewOverrides(list, now)
+}
+
+// rooms of a building, cached until the scraper publishes new data
+func buildingRooms(ctx context.Context, building string) ([]types.Room, error) {
+	roomsMu.Lock()
+	list, ok := rooms[building]
+	roomsMu.Unlock()
+	if ok {
+		metrics.CacheHit("stream_rooms")
+		return list, nil
+	}
+	metrics.CacheMiss("stream_rooms")
+
+	list, err := db.GetRoomsByBuilding(ctx, building)
+	if err != nil {
+		return nil, err
+	}
+	roomsMu.Lock()
+	rooms[building] = list
+	roomsMu.Unlock()
+	return list, nil
+}
+
+func refreshDataset(ctx context.Context) {
+	updated, err := db.GetDatasetUpdatedAt(ctx)
+	if err != nil {
+		slog.Error("stream: reading dataset marker", "err", err)
+		return
+	}
+	roomsMu.Lock()
+	defer roomsMu.Unlock()
+	if updated.After(datasetAt) {
+		if !datasetAt.IsZero() {
+			rooms = make(map[string][]types.Room)
+		}
+		datasetAt = updated
+	}
+}
@@ -0,0 +1,264 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google-dev-groups-gmu/ghost/go/internal/availability"
+	"github.com/google-dev-groups-gmu/ghost/go/internal/types"
+)
+
+// a Tuesday on campus
+func at(hour, minute int) time.Time {
+	return time.Date(2026, 1, 20, hour, minute, 0, 0, availability.Campus)
+}
+
+func ptr(t time.Time) *time.Time { return &t }
+
+// empties the hub and caches HORIZN's rooms so nothing goes to firestore:
+// 2014 has a class 10:00-10:50, 2016 is free all day
+func reset(t *testing.T) {
+	t.Helper()
+	empty := func() {
+		mu.Lock()
+		subs = make(map[string]map[*Subscription]struct{})
+		last = make(map[string]types.RoomStatus)
+		count = 0
+		mu.Unlock()
+		roomsMu.Lock()
+		rooms = make(map[string][]types.Room)
+		roomsMu.Unlock()
+	}
+	empty()
+	t.Cleanup(empty)
+	roomsMu.Lock()
+	rooms["HORIZN"] = []types.Room{
+		{ID: "HORIZN_2014", Building: "HORIZN", Number: "2014", Schedule: []types.Meeting{{Day: 2, StartTime: 10 * 60, EndTime: 10*60 + 50}}},
+		{ID: "HORIZN_2016", Building: "HORIZN", Number: "2016"},
+	}
+	rooms["ENGR"] = []types.Room{{ID: "ENGR_1103", Building: "ENGR", Number: "1103"}}
+	roomsMu.Unlock()
+}
+
+// whatever is waiting on the subscription
+func drain(sub *Subscription) []types.RoomStatus {
+	var got []types.RoomStatus
+	for {
+		select {
+		case s, ok := <-sub.C:
+			if !ok {
+				return got
+			}
+			got = append(got, s)
+		default:
+			return got
+		}
+	}
+}
+
+func TestChanged(t *testing.T) {
+	reports := func(status string, count int, confidence float64) *types.ReportSummary {
+		return &types.ReportSummary{Status: status, Count: count, Confidence: confidence}
+	}
+	base := types.RoomStatus{Free: true, FreeUntil: ptr(at(10, 0)), Reports: reports(types.ReportOccupied, 1, 0.5)}
+	tests := []struct {
+		name string
+		b    types.RoomStatus
+		want bool
+	}{
+		{"same", types.RoomStatus{Free: true, FreeUntil: ptr(at(10, 0)), Reports: reports(types.ReportOccupied, 1, 0.5)}, false},
+		{"confidence decayed", types.RoomStatus{Free: true, FreeUntil: ptr(at(10, 0)), Reports: reports(types.ReportOccupied, 1, 0.4)}, false},
+		{"same time in another zone", types.RoomStatus{Free: true, FreeUntil: ptr(at(10, 0).UTC()), Reports: reports(types.ReportOccupied, 1, 0.5)}, false},
+		{"busy", types.RoomStatus{BusyUntil: ptr(at(11, 0)), Reports: reports(types.ReportOccupied, 1, 0.5)}, true},
+		{"free for longer", types.RoomStatus{Free: true, FreeUntil: ptr(at(11, 0)), Reports: reports(types.ReportOccupied, 1, 0.5)}, true},
+		{"free all week", types.RoomStatus{Free: true, Reports: reports(types.ReportOccupied, 1, 0.5)}, true},
+		{"another report", types.RoomStatus{Free: true, FreeUntil: ptr(at(10, 0)), Reports: reports(types.ReportOccupied, 2, 0.6)}, true},
+		{"reports disagree now", types.RoomStatus{Free: true, FreeUntil: ptr(at(10, 0)), Reports: reports(types.ReportAvailable, 2, 0.3)}, true},
+		{"reports expired", types.RoomStatus{Free: true, FreeUntil: ptr(at(10, 0))}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := changed(base, tt.b); got != tt.want {
+				t.Errorf("changed = %v, want %v", got, tt.want)
+			}
+			if got := changed(tt.b, base); got != tt.want {
+				t.Errorf("changed the other way = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUntilNextMinute(t *testing.T) {
+	tests := []struct {
+		now  time.Time
+		want time.Duration
+	}{
+		{at(10, 49), time.Minute + time.Second},
+		{at(10, 49).Add(30 * time.Second), 31 * time.Second},
+		{at(10, 49).Add(59*time.Second + 500*time.Millisecond), 1500 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := untilNextMinute(tt.now); got != tt.want {
+			t.Errorf("untilNextMinute(%s) = %s, want %s", tt.now.Format("15:04:05.000"), got, tt.want)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	reset(t)
+	ctx := context.Background()
+	sub, snapshot, err := Subscribe(ctx, "HORIZN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Unsubscribe(sub)
+	if len(snapshot) != 2 {
+		t.Fatalf("snapshot has %d rooms", len(snapshot))
+	}
+
+	// the snapshot was taken now, so line last up with class time first
+	evaluate(ctx, "HORIZN", at(10, 30), nil, nil)
+	drain(sub)
+
+	// nothing changed a minute later
+	evaluate(ctx, "HORIZN", at(10, 31), nil, nil)
+	if got := drain(sub); len(got) != 0 {
+		t.Errorf("sent %d updates with nothing changed", len(got))
+	}
+
+	// the class ends, only that room is sent
+	evaluate(ctx, "HORIZN", at(10, 51), nil, nil)
+	got := drain(sub)
+	if len(got) != 1 || got[0].RoomID != "HORIZN_2014" || !got[0].Free {
+		t.Fatalf("sent %+v, want HORIZN_2014 free", got)
+	}
+
+	// a report on the free room is a change, and so is an admin closing the other one
+	reports := map[string][]types.Report{"HORIZN_2016": {{RoomID: "HORIZN_2016", Status: types.ReportOccupied, ReportedAt: at(10, 52)}}}
+	closed := availability.NewOverrides([]types.Override{
+		{Kind: types.OverrideClosed, RoomID: "HORIZN_2014", Building: "HORIZN", Start: at(10, 52)},
+	}, at(10, 52))
+	evaluate(ctx, "HORIZN", at(10, 52), reports, closed)
+	got = drain(sub)
+	if len(got) != 2 {
+		t.Fatalf("sent %+v, want both rooms", got)
+	}
+	for _, s := range got {
+		if s.RoomID == "HORIZN_2014" && s.Free {
+			t.Error("closed room sent as free")
+		}
+		if s.RoomID == "HORIZN_2016" && (s.Reports == nil || s.Reports.Status != types.ReportOccupied) {
+			t.Errorf("reported room sent without its report: %+v", s)
+		}
+	}
+
+	// unwatched buildings aren't evaluated
+	evaluate(ctx, "ENGR", at(10, 52), nil, nil)
+	mu.Lock()
+	_, ok := last["ENGR_1103"]
+	mu.Unlock()
+	if ok {
+		t.Error("evaluated a building nobody watches")
+	}
+}
+
+func TestSlowSubscriberDropped(t *testing.T) {
+	reset(t)
+	ctx := context.Background()
+	slow, _, err := Subscribe(ctx, "HORIZN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fast, _, err := Subscribe(ctx, "HORIZN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Unsubscribe(fast)
+	evaluate(ctx, "HORIZN", at(10, 30), nil, nil)
+	drain(slow)
+	drain(fast)
+
+	// slow never reads, its buffer fills up
+	for range subscriberBuffer {
+		slow.ch <- types.RoomStatus{}
+	}
+	evaluate(ctx, "HORIZN", at(10, 51), nil, nil)
+
+	if got := drain(fast); len(got) != 1 {
+		t.Errorf("fast subscriber got %d updates, want 1", len(got))
+	}
+	if got := drain(slow); len(got) != subscriberBuffer {
+		t.Errorf("slow subscriber had %d queued", len(got))
+	}
+	if _, ok := <-slow.C; ok {
+		t.Error("slow subscriber's channel is still open")
+	}
+	mu.Lock()
+	n := count
+	mu.Unlock()
+	if n != 1 {
+		t.Errorf("%d subscribers counted, want 1", n)
+	}
+	// the handler unsubscribes when the stream ends, which is fine after a drop
+	Unsubscribe(slow)
+}
+
+func TestMaxSubscribers(t *testing.T) {
+	reset(t)
+	ctx := context.Background()
+	mu.Lock()
+	count = MaxSubscribers - 1
+	mu.Unlock()
+
+	sub, _, err := Subscribe(ctx, "HORIZN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := Subscribe(ctx, "ENGR"); !errors.Is(err, ErrTooManySubscribers) {
+		t.Errorf("got %v, want ErrTooManySubscribers", err)
+	}
+	Unsubscribe(sub)
+	if _, _, err := Subscribe(ctx, "ENGR"); err != nil {
+		t.Errorf("a slot freed up, got %v", err)
+	}
+}
+
+func TestLastSubscriberLeaves(t *testing.T) {
+	reset(t)
+	ctx := context.Background()
+	a, _, _ := Subscribe(ctx, "HORIZN")
+	b, _, _ := Subscribe(ctx, "HORIZN")
+	engr, _, _ := Subscribe(ctx, "ENGR")
+	defer Unsubscribe(engr)
+
+	remembered := func(id string) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		_, ok := last[id]
+		return ok
+	}
+
+	Unsubscribe(a)
+	if !remembered("HORIZN_2014") {
+		t.Error("forgot HORIZN with a subscriber left")
+	}
+	Unsubscribe(b)
+	Unsubscribe(b)
+	if remembered("HORIZN_2014") || remembered("HORIZN_2016") {
+		t.Error("HORIZN is still remembered after its last subscriber left")
+	}
+	if !remembered("ENGR_1103") {
+		t.Error("forgot ENGR, which is still watched")
+	}
+	if got := watched(); len(got) != 1 || got[0] != "ENGR" {
+		t.Errorf("watching %v, want only ENGR", got)
+	}
+	mu.Lock()
+	n := count
+	mu.Unlock()
+	if n != 1 {
+		t.Errorf("%d subscribers counted after a double unsubscribe, want 1", n)
+	}
+}
@@ -0,0 +1,32 @@
+package types
+
+import "time"
+
+// audit actions
+const (
+	AuditDatasetPublished = "dataset.published" // a scrape, import or rollback changed the data the API serves
+	AuditRoomAdded        = "room.added"
+	AuditRoomRemoved      = "room.removed"
+	AuditRoomChanged      = "room.changed"
+	AuditBuildingAdded    = "building.added"   // the first rooms of a building showed up
+	AuditBuildingRemoved  = "building.removed" // the last rooms of a building went away
+	AuditOverrideCreated  = "override.created"
+	AuditOverrideDeleted  = "override.deleted"
+	AuditReportCreated    = "report.created"
+)
+
+// one change to the data, stored in the append-only "audit" collection
+type AuditEntry struct {
+	ID     string    `json:"id" firestore:"id"`
+	At     time.Time `json:"at" firestore:"at"`
+	Actor  string    `json:"actor" firestore:"actor"` // admin email, "token", "scraper:schedule", "cli:jdoe@host", "device:..."
+	Action string    `json:"action" firestore:"action"`
+
+	RoomID   string `json:"room_id,omitempty" firestore:"room_id"`
+	Building string `json:"building,omitempty" firestore:"building"`
+	Ref      string `json:"ref,omitempty" firestore:"ref"` // snapshot version, override or report ID
+
+	// only the fields that changed, Before is null for something new and After for something gone
+	Before map[string]any `json:"before" firestore:"before"`
+	After  map[string]any `json:"after" firestore:"after"`
+}
@@ -5,6 +5,12 @@ type BannerSubject struct {
 	Description string `json:"description"`
 }
 
+// ex) {"code": "202610", "description": "Spring 2026"}
+type BannerTerm struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+}
+
 type BannerResponse struct {
 	Success    bool            `json:"success"`
 	TotalCount int             `json:"totalCount"`
@@ -20,6 +26,7 @@ type BannerSection struct {
 	CourseNumber   string `json:"courseNumber"`
 	SequenceNumber string `json:"sequenceNumber"`
 	Title          string `json:"courseTitle"`
+	MaxEnrollment  int    `json:"maximumEnrollment"`
 
 	Faculty []struct {
 		DisplayName string `json:"displayName"`
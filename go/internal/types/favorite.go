@@ -0,0 +1,36 @@
+package types
+
+import "time"
+
+// a room a user keeps coming back to
+type Favorite struct {
+	RoomID    string    `json:"room_id" firestore:"room_id"`
+	CreatedAt time.Time `json:"created_at" firestore:"created_at"`
+}
+
+// a named search a user can rerun from the frontend
+type SavedSearch struct {
+	ID          string    `json:"id" firestore:"id"`
+	Name        string    `json:"name" firestore:"name" binding:"required,max=64"`
+	Building    string    `json:"building" firestore:"building"`
+	MinDuration int       `json:"min_duration" firestore:"min_duration" binding:"min=0,max=1440"` // minutes the room has to stay free
+	CreatedAt   time.Time `json:"created_at" firestore:"created_at"`
+}
+
+// current free/busy state of a room
+type RoomStatus struct {
+	RoomID    string         `json:"room_id"`
+	Building  string         `json:"building"`
+	Number    string         `json:"number"`
+	Free      bool           `json:"free"`
+	FreeUntil *time.Time     `json:"free_until,omitempty"` // nil when nothing else is scheduled this week
+	BusyUntil *time.Time     `json:"busy_until,omitempty"`
+	Reports   *ReportSummary `json:"reports,omitempty"`
+}
+
+// free/busy state of a room at query time
+type Availability struct {
+	Free      bool       `json:"free"`
+	FreeUntil *time.Time `json:"free_until,omitempty"` // nil when nothing else is scheduled this week
+	BusyUntil *time.Time `json:"busy_until,omitempty"` // nil for a closure without an end
+}
@@ -0,0 +1,21 @@
+package types
+
+import "time"
+
+// longest a group can hold a room for
+const MaxHoldDuration = 3 * time.Hour
+
+// most holds a user can have that haven't ended, so one account can't sit on a whole floor
+const MaxActiveHolds = 2
+
+// a soft-hold on a free room by a study group
+// ex) "our group is in HORIZN 2014 until 4pm"
+// nothing stops someone else from walking in, it just shows up for other users
+type Hold struct {
+	ID     string    `json:"id" firestore:"id"`
+	RoomID string    `json:"room_id" firestore:"room_id"`
+	UserID string    `json:"-" firestore:"user_id"`
+	Group  string    `json:"group" firestore:"group"` // "CS 310 study group"
+	Start  time.Time `json:"start" firestore:"start"`
+	End    time.Time `json:"end" firestore:"end"` // also the expiry, a TTL policy can clean these up
+}
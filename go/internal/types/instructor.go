@@ -0,0 +1,21 @@
+package types
+
+// an instructor as listed on banner sections, stored in the "instructors" collection
+type Instructor struct {
+	ID    string `json:"id" firestore:"id"` // email username when we have it, ex) "jdoe"
+	Name  string `json:"name" firestore:"name"`
+	Email string `json:"email,omitempty" firestore:"email"`
+}
+
+// one block of an instructor's weekly schedule
+type TeachingSlot struct {
+	CRN       string `json:"crn"`
+	CourseID  string `json:"course_id"`
+	Section   string `json:"section"`
+	Title     string `json:"title"`
+	Days      []int  `json:"days"`
+	StartTime int    `json:"start_time"`
+	EndTime   int    `json:"end_time"`
+	Location  string `json:"location"`
+	RoomID    string `json:"room_id,omitempty"`
+}
@@ -0,0 +1,36 @@
+package types
+
+import "time"
+
+// override kinds
+const (
+	OverrideBlock        = "block"         // the room can't be used from Start to End, ex) renovation
+	OverrideClosed       = "closed"        // the room is closed from Start until End, or until the override is deleted
+	OverrideEvent        = "event"         // a one-off event takes the room from Start to End
+	OverrideHideBuilding = "hide_building" // Building and its rooms are left out of every listing
+)
+
+// a manual correction to the scraped data, stored in the "overrides" collection
+// scrapes only replace rooms, sections and instructors, so overrides survive them and are merged in at query time
+type Override struct {
+	ID       string     `json:"id" firestore:"id"`
+	Kind     string     `json:"kind" firestore:"kind"`
+	RoomID   string     `json:"room_id,omitempty" firestore:"room_id"` // every kind but hide_building
+	Building string     `json:"building" firestore:"building"`         // the room's building, or the one hidden
+	Title    string     `json:"title,omitempty" firestore:"title"`     // shown to users, ex) "Closed for renovation"
+	Start    time.Time  `json:"start" firestore:"start"`
+	End      *time.Time `json:"end,omitempty" firestore:"end"` // nil = until deleted, only closed and hide_building
+
+	CreatedBy string    `json:"created_by" firestore:"created_by"` // admin email, or "token"
+	CreatedAt time.Time `json:"created_at" firestore:"created_at"`
+}
+
+// true from Start until End
+func (o Override) ActiveAt(t time.Time) bool {
+	return !t.Before(o.Start) && !o.EndedBy(t)
+}
+
+// true once End has passed
+func (o Override) EndedBy(t time.Time) bool {
+	return o.End != nil && !o.End.After(t)
+}
@@ -0,0 +1,42 @@
+package types
+
+import "time"
+
+// statuses a user can report for a room
+const (
+	ReportOccupied  = "occupied"
+	ReportLocked    = "locked"
+	ReportAvailable = "available"
+)
+
+// how long a report counts towards a room's status
+// after this it is ignored (and can be cleaned up by a firestore TTL policy on expires_at)
+const ReportTTL = 30 * time.Minute
+
+// a crowd-sourced report about a room's current state
+// schedules don't know about club meetings, tutoring or locked doors, users do
+type Report struct {
+	ID         string    `json:"id" firestore:"id"`
+	RoomID     string    `json:"room_id" firestore:"room_id"` // "HORIZN_2014"
+	Status     string    `json:"status" firestore:"status"`   // "occupied" | "locked" | "available"
+	ReportedAt time.Time `json:"reported_at" firestore:"reported_at"`
+	ExpiresAt  time.Time `json:"expires_at" firestore:"expires_at"`
+	DeviceID   string    `json:"-" firestore:"device_id"` // who sent it, kept for abuse cleanup
+}
+
+// aggregated view of the active reports for a room
+type ReportSummary struct {
+	Status         string    `json:"status"`     // the status most reports agree on
+	Confidence     float64   `json:"confidence"` // 0..1, how much we trust Status
+	Count          int       `json:"count"`      // number of active reports
+	LastReportedAt time.Time `json:"last_reported_at"`
+}
+
+// returns true if s is one of the report statuses above
+func IsValidReportStatus(s string) bool {
+	switch s {
+	case ReportOccupied, ReportLocked, ReportAvailable:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package types
+
+import "time"
+
+// scrape run states
+const (
+	ScrapeRunning   = "running"
+	ScrapeSucceeded = "succeeded"
+	ScrapeFailed    = "failed"
+	ScrapeAbandoned = "abandoned" // still "running" but nobody holds the lock, the process died
+)
+
+// one scrape that wrote to firestore, stored under scrapes/{id}
+type ScrapeRun struct {
+	ID         string     `json:"id" firestore:"id"`
+	Trigger    string     `json:"trigger" firestore:"trigger"` // "cli", "daemon" or "schedule"
+	Host       string     `json:"host" firestore:"host"`
+	Term       string     `json:"term" firestore:"term"`
+	Status     string     `json:"status" firestore:"status"`
+	StartedAt  time.Time  `json:"started_at" firestore:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" firestore:"finished_at"`
+	Error      string     `json:"error,omitempty" firestore:"error"`
+
+	Version        string   `json:"version,omitempty" firestore:"version"` // published snapshot
+	Rooms          int      `json:"rooms" firestore:"rooms"`
+	Sections       int      `json:"sections" firestore:"sections"`
+	Instructors    int      `json:"instructors" firestore:"instructors"`
+	FailedSubjects []string `json:"failed_subjects,omitempty" firestore:"failed_subjects"`
+	FailedChecks   []string `json:"failed_checks,omitempty" firestore:"failed_checks"`
+}
+
+// meta/scrape_lock, held by the running scrape and renewed while it runs
+// a run that dies keeps it until ExpiresAt
+type ScrapeLock struct {
+	// a scrape run's ID, or import-... for a dataset import, which has no run record
+	RunID      string    `json:"run_id" firestore:"run_id"`
+	Host       string    `json:"host" firestore:"host"`
+	AcquiredAt time.Time `json:"acquired_at" firestore:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at" firestore:"expires_at"`
+}
@@ -5,7 +5,10 @@ type MeetingInfo struct {
 	ID        string `json:"id" firestore:"id"`               // CRN as the ID ex) "10492"
 	CourseID  string `json:"course_id" firestore:"course_id"` // "CS110"
 	Section   string `json:"section" firestore:"section"`     // "001"
-	Professor string `json:"professor" firestore:"professor"`
+	Professor string `json:"professor" firestore:"professor"` // first instructor, kept for the frontend
+
+	// everyone teaching this meeting
+	Instructors []Instructor `json:"instructors,omitempty" firestore:"instructors,omitempty"`
 }
 
 // meeting time for a class in a specific room
@@ -22,5 +25,15 @@ type Room struct {
 	ID       string    `firestore:"id"`       // ex) "HORIZN_2014"
 	Building string    `firestore:"building"` // " Horizon Hall"
 	Number   string    `firestore:"number"`   // "2014"
+	Capacity int       `firestore:"capacity"` // largest section scheduled here, banner doesn't publish seats per room
 	Schedule []Meeting `firestore:"schedule"`
+
+	// crowd-sourced status, filled in at query time and never stored on the room doc
+	Reports *ReportSummary `json:"Reports,omitempty" firestore:"-"`
+	// active study group holds, also filled in at query time
+	Holds []Hold `json:"Holds,omitempty" firestore:"-"`
+	// blocks, closures and events that haven't ended, from the overrides collection
+	Overrides []Override `json:"Overrides,omitempty" firestore:"-"`
+	// free or busy right now from the schedule and overrides, Reports is what people on the ground say
+	Availability *Availability `json:"Availability,omitempty" firestore:"-"`
 }
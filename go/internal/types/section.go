@@ -0,0 +1,37 @@
+package types
+
+// a class section as scraped from banner, stored in the "sections" collection
+// rooms only keep a short label per meeting, this keeps everything else
+type Section struct {
+	CRN            string           `json:"crn" firestore:"crn"` // doc ID, ex) "10492"
+	Term           string           `json:"term" firestore:"term"`
+	Subject        string           `json:"subject" firestore:"subject"`             // "CS"
+	CourseNumber   string           `json:"course_number" firestore:"course_number"` // "310"
+	CourseID       string           `json:"course_id" firestore:"course_id"`         // "CS310"
+	SequenceNumber string           `json:"section" firestore:"section"`             // "001"
+	Title          string           `json:"title" firestore:"title"`
+	TitleLower     string           `json:"-" firestore:"title_lower"` // for prefix queries
+	Instructors    []Instructor     `json:"instructors" firestore:"instructors"`
+	InstructorIDs  []string         `json:"-" firestore:"instructor_ids"` // for array-contains queries
+	Meetings       []SectionMeeting `json:"meetings" firestore:"meetings"`
+}
+
+// when and where a section meets
+type SectionMeeting struct {
+	Days      []int  `json:"days" firestore:"days"` // 0 = sunday
+	StartTime int    `json:"start_time" firestore:"start_time"`
+	EndTime   int    `json:"end_time" firestore:"end_time"`
+	Location  string `json:"location" firestore:"location"` // "HORIZN 2014" or "TBA"
+	RoomID    string `json:"room_id,omitempty" firestore:"room_id"`
+
+	Instructors []Instructor `json:"instructors" firestore:"instructors"`
+}
+
+// sections of the same course grouped together for search results
+type Course struct {
+	CourseID     string    `json:"course_id"`
+	Subject      string    `json:"subject"`
+	CourseNumber string    `json:"course_number"`
+	Title        string    `json:"title"`
+	Sections     []Section `json:"sections"`
+}
@@ -0,0 +1,17 @@
+package types
+
+import "time"
+
+// one scrape's worth of rooms, sections and instructors, stored under datasets/{version}
+// the API only reads the snapshot meta/dataset points at
+type Snapshot struct {
+	Version     string     `json:"version" firestore:"version"` // ex) "202610-20260120T150405Z"
+	Term        string     `json:"term" firestore:"term"`
+	CreatedAt   time.Time  `json:"created_at" firestore:"created_at"`
+	Complete    bool       `json:"complete" firestore:"complete"` // every doc written and counted
+	PublishedAt *time.Time `json:"published_at,omitempty" firestore:"published_at"`
+	Rooms       int        `json:"rooms" firestore:"rooms"`
+	Sections    int        `json:"sections" firestore:"sections"`
+	Instructors int        `json:"instructors" firestore:"instructors"`
+	Active      bool       `json:"active" firestore:"-"`
+}
@@ -0,0 +1,21 @@
+package types
+
+import "time"
+
+// a student that signed in with their GMU google account
+type User struct {
+	ID          string    `json:"id" firestore:"id"` // OIDC subject
+	Email       string    `json:"email" firestore:"email"`
+	Name        string    `json:"name" firestore:"name"`
+	AvatarURL   string    `json:"avatar_url" firestore:"avatar_url"`
+	CreatedAt   time.Time `json:"created_at" firestore:"created_at"`
+	LastLoginAt time.Time `json:"last_login_at" firestore:"last_login_at"`
+}
+
+// server-side login session
+// the doc ID is a hash of the cookie value so a leaked database doesn't leak live sessions
+type Session struct {
+	UserID    string    `firestore:"user_id"`
+	CreatedAt time.Time `firestore:"created_at"`
+	ExpiresAt time.Time `firestore:"expires_at"`
+}
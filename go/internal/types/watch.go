@@ -0,0 +1,26 @@
+package types
+
+import "time"
+
+// notification channels a watch can use
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelPush    = "push"
+)
+
+// a user asking to be pinged before a room frees up
+// ex) "ping me 5 minutes before ENGR 1103 frees up"
+type Watch struct {
+	ID          string `json:"id" firestore:"id"`
+	UserID      string `json:"-" firestore:"user_id"`
+	RoomID      string `json:"room_id" firestore:"room_id"`
+	LeadMinutes int    `json:"lead_minutes" firestore:"lead_minutes"` // how early to notify
+	Channel     string `json:"channel" firestore:"channel"`           // "email" | "webhook" | "push"
+	// where to send it: email address, webhook URL or web push subscription JSON
+	Target string `json:"-" firestore:"target"`
+
+	// start of the last free window we notified about, so each window is only sent once
+	NotifiedFor time.Time `json:"notified_for,omitempty" firestore:"notified_for"`
+	CreatedAt   time.Time `json:"created_at" firestore:"created_at"`
+}